@@ -0,0 +1,131 @@
+// local_files runs a pipeline on the local filesystem, without any cloud credentials:
+// every file dropped on the input directory (compressed or not) is decompressed and appended
+// to rotating files on the output directory.
+//
+//	go run ./examples/local_files -input ./input -output ./output
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+	"github.com/otaviohenrique/vecna/pkg/task/file"
+	"github.com/otaviohenrique/vecna/pkg/workers"
+)
+
+type ContentExtractor[I *file.File, O []byte] struct{}
+
+func (c *ContentExtractor[I, O]) Run(_ context.Context, in I, _ map[string]interface{}, _ string) (O, error) {
+	f := (*file.File)(in)
+
+	return O(f.Data), nil
+}
+
+func main() {
+	input := flag.String("input", "input", "directory watched for new files")
+	output := flag.String("output", "output", "directory where files are written")
+	flag.Parse()
+
+	metric := &metrics.TODO{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	if err := os.MkdirAll(*input, 0o755); err != nil {
+		panic(err)
+	}
+
+	watcherTask, err := file.NewFileWatcher(*input, logger, &file.FileWatcherOpts{MinAge: time.Second})
+	if err != nil {
+		panic(err)
+	}
+
+	watcher := workers.NewProducerWorker("Watch Files", watcherTask, 1, logger, metric, 500*time.Millisecond)
+	filesCh := make(chan *workers.WorkerData[*file.File], 10)
+	watcher.AddOutputCh(filesCh)
+
+	extractor := workers.NewBiDirectionalWorker[*file.File, []byte](
+		"Extract Content",
+		&ContentExtractor[*file.File, []byte]{},
+		1,
+		logger,
+		metric,
+	)
+	extractor.AddInputCh(filesCh)
+	rawContentCh := make(chan *workers.WorkerData[[]byte], 10)
+	extractor.AddOutputCh(rawContentCh)
+
+	decompressor := workers.NewBiDirectionalWorker(
+		"Decompress Data",
+		compression.NewDecompressor(compression.AUTO_TYPE, logger, nil),
+		2,
+		logger,
+		metric,
+	)
+	decompressor.AddInputCh(rawContentCh)
+	decompressedCh := make(chan *workers.WorkerData[[]byte], 10)
+	decompressor.AddOutputCh(decompressedCh)
+
+	writerTask, err := file.NewFileWriter(*output, logger, &file.FileWriterOpts{
+		Prefix:    "collected-",
+		Extension: ".log",
+		MaxSize:   1 << 20,
+		MaxAge:    10 * time.Second,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	writer := workers.NewConsumerWorker("Write Files", writerTask, 1, logger, metric)
+	writer.AddInputCh(decompressedCh)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// workers run on their own context, so the interrupt doesn't cancel the files still in flight
+	runCtx := context.Background()
+
+	watcher.Start(runCtx)
+	extractor.Start(runCtx)
+	decompressor.Start(runCtx)
+	writer.Start(runCtx)
+
+	logger.Info("watching files, interrupt to stop", "input", *input, "output", *output)
+
+	<-ctx.Done()
+
+	// workers are stopped in pipeline order, each one after its input was drained, so files in flight reach the
+	// writer before its last file is renamed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watcher.Stop(shutdownCtx)
+	drain(shutdownCtx, filesCh)
+	extractor.Stop(shutdownCtx)
+	drain(shutdownCtx, rawContentCh)
+	decompressor.Stop(shutdownCtx)
+	drain(shutdownCtx, decompressedCh)
+	writer.Stop(shutdownCtx)
+
+	if err := writerTask.Close(); err != nil {
+		panic(err)
+	}
+}
+
+// drain waits the next worker to take every message buffered on ch
+func drain[T any](ctx context.Context, ch chan *workers.WorkerData[T]) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for len(ch) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
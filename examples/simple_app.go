@@ -8,10 +8,9 @@ import (
 	"os"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	awsS3 "github.com/aws/aws-sdk-go/service/s3"
-	awsSqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/otaviohenrique/vecna/pkg/metrics"
 	"github.com/otaviohenrique/vecna/pkg/task/compression"
 	"github.com/otaviohenrique/vecna/pkg/task/s3"
@@ -50,21 +49,25 @@ func main() {
 	metric := &metrics.TODO{}
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String("us-east-1"),
-	})
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
 
 	if err != nil {
 		panic(err)
 	}
 
-	sqsClient := awsSqs.New(sess)
+	sqsClient := awsSqs.NewFromConfig(cfg)
+
+	consumerTask, err := sqs.NewSQSConsumer(sqsClient, logger, &sqs.SQSConsumerOpts{
+		QueueName: "any-queue",
+	})
+
+	if err != nil {
+		panic(err)
+	}
 
 	sqsConsumer := workers.NewProducerWorker(
 		"Event Created",
-		sqs.NewSQSConsumer(sqsClient, logger, &sqs.SQSConsumerOpts{
-			QueueName: "any-queue",
-		}),
+		consumerTask,
 		10,
 		logger,
 		metric,
@@ -97,7 +100,7 @@ func main() {
 	pathExtractor.AddInputCh(msgsCh)
 	pathExtractor.AddOutputCh(pathCh)
 
-	s3Client := awsS3.New(sess)
+	s3Client := awsS3.NewFromConfig(cfg)
 
 	s3Downloader := workers.NewBiDirectionalWorker(
 		"Download Data",
@@ -105,6 +108,7 @@ func main() {
 			s3Client,
 			"bucket",
 			logger,
+			nil,
 		),
 		5,
 		logger,
@@ -129,6 +133,7 @@ func main() {
 		compression.NewDecompressor(
 			"gzip",
 			logger,
+			nil,
 		),
 		5,
 		logger,
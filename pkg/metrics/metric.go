@@ -23,6 +23,13 @@ type Metric interface {
 	TaskExecutionTime(workerName string, start time.Time, end time.Time)
 }
 
+// PayloadRejecter is optionally implemented by a Metric, tasks and workers check it with a type assertion
+// so existing Metric implementations keep working without it
+type PayloadRejecter interface {
+	// PayloadRejected will be called everytime that a task rejects a payload (ex. decompression bomb) with the reason
+	PayloadRejected(workerName string, reason string)
+}
+
 // TODO metrics class. Mean to be used if you don't want metrics or don't implemented it yet
 type TODO struct{}
 
@@ -40,6 +47,8 @@ func (m *TODO) TaskRun(workerName string) {}
 
 func (m *TODO) TaskExecutionTime(workerName string, start time.Time, end time.Time) {}
 
+func (m *TODO) PayloadRejected(workerName string, reason string) {}
+
 // MockMetric append metrics on maps. Don't use it on production environmnets.
 type MockMetric struct {
 	EnqueuedMessagesCalled map[string]int
@@ -48,6 +57,7 @@ type MockMetric struct {
 	TaskErrorCalled        map[string]int
 	TaskSuccessCalled      map[string]int
 	TaskRunCalled          map[string]int
+	PayloadRejectedCalled  map[string]int
 	taskExecutionTime      map[string]float64
 	Lock                   sync.RWMutex
 }
@@ -61,6 +71,7 @@ func NewMockMetrics() *MockMetric {
 	m.TaskErrorCalled = map[string]int{}
 	m.TaskSuccessCalled = map[string]int{}
 	m.TaskRunCalled = map[string]int{}
+	m.PayloadRejectedCalled = map[string]int{}
 	m.taskExecutionTime = map[string]float64{}
 	m.Lock = sync.RWMutex{}
 
@@ -109,3 +120,9 @@ func (m *MockMetric) TaskRun(workerName string) {
 	m.TaskRunCalled[workerName] += 1
 	m.Lock.Unlock()
 }
+
+func (m *MockMetric) PayloadRejected(workerName string, reason string) {
+	m.Lock.Lock()
+	m.PayloadRejectedCalled[workerName] += 1
+	m.Lock.Unlock()
+}
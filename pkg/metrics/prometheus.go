@@ -49,6 +49,12 @@ var taskRuntime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 	Buckets:   []float64{1, 5, 10, 15, 20, 35, 50, 100, 200, 350, 500, 750, 1000, 2000},
 }, []string{"worker_name"})
 
+var payloadRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "vecna",
+	Name:      "task_payload_rejected",
+	Help:      "payload rejected by task",
+}, []string{"worker_name", "reason"})
+
 type PromMetrics struct {
 	EnqueuedMsgs prometheus.GaugeVec
 	ConsumedMsg  prometheus.CounterVec
@@ -57,6 +63,7 @@ type PromMetrics struct {
 	TaskSucc     prometheus.CounterVec
 	TaskR        prometheus.CounterVec
 	TaskRT       prometheus.HistogramVec
+	PayloadRej   prometheus.CounterVec
 }
 
 func NewPromMetrics() *PromMetrics {
@@ -69,6 +76,7 @@ func NewPromMetrics() *PromMetrics {
 	metrics.TaskSucc = *taskSuccess
 	metrics.TaskR = *taskRun
 	metrics.TaskRT = *taskRuntime
+	metrics.PayloadRej = *payloadRejected
 
 	return metrics
 }
@@ -102,3 +110,7 @@ func (m *PromMetrics) TaskExecutionTime(workerName string, start time.Time, end
 
 	m.TaskRT.WithLabelValues(workerName).Observe(float64(elapsed.Milliseconds()))
 }
+
+func (m *PromMetrics) PayloadRejected(workerName string, reason string) {
+	m.PayloadRej.WithLabelValues(workerName, reason).Inc()
+}
@@ -0,0 +1,58 @@
+package amqp
+
+import (
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrChannelClosed is returned when the AMQP channel was closed (ex. connection lost), open a new one
+	ErrChannelClosed = errors.New("amqp channel closed")
+	// ErrNacked is returned when the broker refused a published message (publisher confirms)
+	ErrNacked = errors.New("amqp message nacked by broker")
+)
+
+// AMQPMessage is a delivery received by AMQPConsumer, also appended on metadata under worker name
+type AMQPMessage struct {
+	Body          []byte
+	Exchange      string
+	RoutingKey    string
+	Headers       amqp.Table
+	ContentType   string
+	MessageID     string
+	CorrelationID string
+	ReplyTo       string
+	Timestamp     time.Time
+	// Redelivered is true when the message was requeued before (ex. a failed attempt or a consumer crash)
+	Redelivered bool
+
+	delivery amqp.Delivery
+}
+
+func newAMQPMessage(d amqp.Delivery) *AMQPMessage {
+	return &AMQPMessage{
+		Body:          d.Body,
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		Headers:       d.Headers,
+		ContentType:   d.ContentType,
+		MessageID:     d.MessageId,
+		CorrelationID: d.CorrelationId,
+		ReplyTo:       d.ReplyTo,
+		Timestamp:     d.Timestamp,
+		Redelivered:   d.Redelivered,
+		delivery:      d,
+	}
+}
+
+// Ack acknowledges the message, removing it from the queue
+func (m *AMQPMessage) Ack() error {
+	return m.delivery.Ack(false)
+}
+
+// Nack refuses the message, requeueing it or dead-lettering it (when the queue has a dead letter exchange)
+func (m *AMQPMessage) Nack(requeue bool) error {
+	return m.delivery.Nack(false, requeue)
+}
@@ -0,0 +1,66 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type AMQPAckOpts struct {
+	// MessageMetaKey is the name of the worker running AMQPConsumer, where its *AMQPMessage is kept on metadata
+	MessageMetaKey string
+	// Requeue failed messages, otherwise they are dead-lettered (or dropped when the queue has no dead letter exchange)
+	Requeue bool
+	// DeadLetterRedelivered dead-letters failed messages which were already redelivered instead of requeueing them again,
+	// avoiding poison messages looping forever
+	DeadLetterRedelivered bool
+}
+
+// AMQPAck wraps a task settling the delivery an AMQPConsumer kept on metadata (the consumer runs with manual acks).
+// Success (or task.ErrNoData, dropping the message on purpose) acks the delivery tag. Failure nacks it, where the
+// broker either requeues it or routes it to the queue dead letter exchange, as chosen by Requeue and
+// DeadLetterRedelivered.
+type AMQPAck[I any, O any] struct {
+	task   task.Task[I, O]
+	logger *slog.Logger
+	opts   *AMQPAckOpts
+}
+
+func NewAMQPAck[I any, O any](t task.Task[I, O], logger *slog.Logger, opts *AMQPAckOpts) *AMQPAck[I, O] {
+	a := new(AMQPAck[I, O])
+
+	a.task = t
+	a.logger = logger
+	a.opts = opts
+
+	return a
+}
+
+func (a *AMQPAck[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	resp, err := a.task.Run(ctx, input, meta, name)
+
+	msg, ok := meta[a.opts.MessageMetaKey].(*AMQPMessage)
+	if !ok {
+		return resp, err
+	}
+
+	if err != nil && !errors.Is(err, task.ErrNoData) {
+		requeue := a.opts.Requeue && !(a.opts.DeadLetterRedelivered && msg.Redelivered)
+
+		if nackErr := msg.Nack(requeue); nackErr != nil {
+			a.logger.Error("error nacking amqp message", "error", nackErr, "routing_key", msg.RoutingKey)
+		}
+
+		return resp, err
+	}
+
+	if ackErr := msg.Ack(); ackErr != nil {
+		a.logger.Error("error acking amqp message", "error", ackErr, "routing_key", msg.RoutingKey)
+
+		return resp, ackErr
+	}
+
+	return resp, err
+}
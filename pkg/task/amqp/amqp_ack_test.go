@@ -0,0 +1,76 @@
+package amqp_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnaamqp "github.com/otaviohenrique/vecna/pkg/task/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type mockTask[I []byte, O string] struct {
+	err error
+}
+
+func (m *mockTask[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	return O(input), m.err
+}
+
+func TestAMQPAck_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		opts        vecnaamqp.AMQPAckOpts
+		redelivered bool
+		wantAcked   []uint64
+		wantRequeue []bool
+	}{
+		{"It acks when the task succeeds", nil, vecnaamqp.AMQPAckOpts{}, false, []uint64{1}, nil},
+		{"It nacks without requeue when the task fails", errors.New("boom"), vecnaamqp.AMQPAckOpts{}, false, nil, []bool{false}},
+		{"It acks when the task returns ErrNoData", task.ErrNoData, vecnaamqp.AMQPAckOpts{Requeue: true}, false, []uint64{1}, nil},
+		{"It requeues when the task fails", errors.New("boom"), vecnaamqp.AMQPAckOpts{Requeue: true}, false, nil, []bool{true}},
+		{"It dead-letters redelivered messages", errors.New("boom"), vecnaamqp.AMQPAckOpts{Requeue: true, DeadLetterRedelivered: true}, true, nil, []bool{false}},
+		{"It requeues first deliveries", errors.New("boom"), vecnaamqp.AMQPAckOpts{Requeue: true, DeadLetterRedelivered: true}, false, nil, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acknowledger := &mockAcknowledger{}
+			ch := &mockConsumeChannel{deliveries: make(chan amqp.Delivery, 1)}
+			ch.deliveries <- amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: []byte("order"), Redelivered: tt.redelivered}
+
+			c, _ := vecnaamqp.NewAMQPConsumer(ch, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaamqp.AMQPConsumerOpts{Queue: "orders", PollTimeout: time.Second})
+			meta := map[string]interface{}{}
+
+			msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "amqp")
+			if err != nil {
+				t.Fatalf("AMQPConsumer.Run() error = %v", err)
+			}
+
+			tt.opts.MessageMetaKey = "amqp"
+			ack := vecnaamqp.NewAMQPAck[[]byte, string](&mockTask[[]byte, string]{err: tt.err}, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+
+			got, err := ack.Run(context.TODO(), msg.Body, meta, "process")
+			if !errors.Is(err, tt.err) || got != "order" {
+				t.Errorf("AMQPAck.Run() = %s, %v, want order, %v", got, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(acknowledger.acked, tt.wantAcked) || !reflect.DeepEqual(acknowledger.requeue, tt.wantRequeue) {
+				t.Errorf("AMQPAck.Run() acked %v requeue %v, want acked %v requeue %v", acknowledger.acked, acknowledger.requeue, tt.wantAcked, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestAMQPAck_RunWithoutMessage(t *testing.T) {
+	ack := vecnaamqp.NewAMQPAck[[]byte, string](&mockTask[[]byte, string]{}, slog.Default(), &vecnaamqp.AMQPAckOpts{MessageMetaKey: "amqp"})
+
+	if got, err := ack.Run(context.TODO(), []byte("data"), map[string]interface{}{}, "process"); err != nil || got != "data" {
+		t.Errorf("AMQPAck.Run() = %s, %v, want data", got, err)
+	}
+}
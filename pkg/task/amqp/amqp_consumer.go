@@ -0,0 +1,121 @@
+package amqp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	DefaultPrefetch = 1
+)
+
+type AMQPConsumerOpts struct {
+	// Queue consumed
+	Queue string
+	// ConsumerTag identifies the consumer on the channel, a unique one is generated when empty
+	ConsumerTag string
+	// Prefetch is how many unacknowledged messages the broker sends to this consumer. Match it to the number of workers
+	// (goroutines) of the pool: lower leaves workers idle, higher keeps messages waiting on the client where other
+	// consumers can't take them. Defaults to DefaultPrefetch
+	Prefetch int
+	// Exclusive makes this the only consumer of the queue
+	Exclusive bool
+	// Args of the consume call (ex. "x-priority")
+	Args amqp.Table
+	// PollTimeout is how long Run waits for a message before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+}
+
+// AMQPConsumer is a source task (to be used with ProducerWorker) which consumes a queue with manual acknowledgement.
+// Every Run() returns one message, also appended on metadata under worker name, to be acknowledged with AMQPAck
+// (or AMQPMessage Ack/Nack). Unacknowledged messages are requeued by the broker when the channel closes.
+// When there is no message to emit it returns task.ErrNoData.
+type AMQPConsumer[I task.Nullable, O *AMQPMessage] struct {
+	ch         ConsumeChannel
+	tag        string
+	deliveries <-chan amqp.Delivery
+	logger     *slog.Logger
+	opts       *AMQPConsumerOpts
+}
+
+// NewAMQPConsumer sets the channel prefetch and starts consuming the queue
+func NewAMQPConsumer[I task.Nullable, O *AMQPMessage](ch ConsumeChannel, logger *slog.Logger, opts *AMQPConsumerOpts) (*AMQPConsumer[I, O], error) {
+	if opts.Queue == "" {
+		return nil, errors.New("amqp consumer needs a queue")
+	}
+
+	c := new(AMQPConsumer[I, O])
+
+	c.ch = ch
+	c.logger = logger
+	c.opts = opts
+
+	if c.opts.Prefetch == 0 {
+		c.opts.Prefetch = DefaultPrefetch
+	}
+
+	if c.opts.PollTimeout == 0 {
+		c.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	// the tag is needed to cancel the consumer on Close, so it's never left for the library to generate
+	c.tag = opts.ConsumerTag
+	if c.tag == "" {
+		c.tag = newConsumerTag()
+	}
+
+	if err := ch.Qos(c.opts.Prefetch, 0, false); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := ch.ConsumeWithContext(context.Background(), opts.Queue, c.tag, false, opts.Exclusive, false, false, opts.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	c.deliveries = deliveries
+
+	return c, nil
+}
+
+func (c *AMQPConsumer[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	timer := time.NewTimer(c.opts.PollTimeout)
+	defer timer.Stop()
+
+	select {
+	case d, ok := <-c.deliveries:
+		if !ok {
+			c.logger.Error("amqp deliveries closed", "queue", c.opts.Queue)
+
+			return nil, ErrChannelClosed
+		}
+
+		msg := newAMQPMessage(d)
+		meta[name] = msg
+
+		return msg, nil
+	case <-timer.C:
+		return nil, task.ErrNoData
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops consuming, deliveries not returned by Run yet are requeued when the channel closes
+func (c *AMQPConsumer[I, O]) Close() error {
+	return c.ch.Cancel(c.tag, false)
+}
+
+func newConsumerTag() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return "vecna-" + hex.EncodeToString(b)
+}
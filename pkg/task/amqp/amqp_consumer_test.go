@@ -0,0 +1,157 @@
+package amqp_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnaamqp "github.com/otaviohenrique/vecna/pkg/task/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// mockAcknowledger records the outcome of every delivery tag
+type mockAcknowledger struct {
+	mu      sync.Mutex
+	acked   []uint64
+	nacked  []uint64
+	requeue []bool
+}
+
+func (a *mockAcknowledger) Ack(tag uint64, _ bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.acked = append(a.acked, tag)
+
+	return nil
+}
+
+func (a *mockAcknowledger) Nack(tag uint64, _ bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nacked = append(a.nacked, tag)
+	a.requeue = append(a.requeue, requeue)
+
+	return nil
+}
+
+func (a *mockAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+type mockConsumeChannel struct {
+	deliveries chan amqp.Delivery
+	prefetch   int
+	queue      string
+	tag        string
+	autoAck    bool
+	cancelled  string
+	qosErr     error
+}
+
+func (m *mockConsumeChannel) Qos(prefetchCount, _ int, _ bool) error {
+	m.prefetch = prefetchCount
+
+	return m.qosErr
+}
+
+func (m *mockConsumeChannel) ConsumeWithContext(_ context.Context, queue, consumer string, autoAck, _, _, _ bool, _ amqp.Table) (<-chan amqp.Delivery, error) {
+	m.queue = queue
+	m.tag = consumer
+	m.autoAck = autoAck
+
+	return m.deliveries, nil
+}
+
+func (m *mockConsumeChannel) Cancel(consumer string, _ bool) error {
+	m.cancelled = consumer
+
+	return nil
+}
+
+func TestAMQPConsumer_Run(t *testing.T) {
+	acknowledger := &mockAcknowledger{}
+	ch := &mockConsumeChannel{deliveries: make(chan amqp.Delivery, 2)}
+
+	c, err := vecnaamqp.NewAMQPConsumer(ch, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaamqp.AMQPConsumerOpts{
+		Queue:       "orders",
+		ConsumerTag: "vecna",
+		Prefetch:    5,
+		PollTimeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewAMQPConsumer() error = %v", err)
+	}
+
+	if ch.prefetch != 5 || ch.queue != "orders" || ch.autoAck {
+		t.Errorf("NewAMQPConsumer() prefetch %d queue %s autoAck %v, want 5 orders false", ch.prefetch, ch.queue, ch.autoAck)
+	}
+
+	ch.deliveries <- amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: []byte("order"), RoutingKey: "orders.created", Redelivered: true, Headers: amqp.Table{"source": "test"}}
+
+	meta := map[string]interface{}{}
+
+	msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "amqp")
+	if err != nil {
+		t.Fatalf("AMQPConsumer.Run() error = %v", err)
+	}
+
+	if string(msg.Body) != "order" || msg.RoutingKey != "orders.created" || !msg.Redelivered || msg.Headers["source"] != "test" || meta["amqp"] != msg {
+		t.Errorf("AMQPConsumer.Run() = %+v, want the delivery", msg)
+	}
+
+	if err := msg.Ack(); err != nil || len(acknowledger.acked) != 1 || acknowledger.acked[0] != 1 {
+		t.Errorf("AMQPMessage.Ack() = %v, acked %v, want tag 1", err, acknowledger.acked)
+	}
+
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "amqp"); !errors.Is(err, task.ErrNoData) {
+		t.Errorf("AMQPConsumer.Run() error = %v, want ErrNoData", err)
+	}
+
+	c.Close()
+	if ch.cancelled != "vecna" {
+		t.Errorf("AMQPConsumer.Close() cancelled %q, want vecna", ch.cancelled)
+	}
+
+	close(ch.deliveries)
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "amqp"); !errors.Is(err, vecnaamqp.ErrChannelClosed) {
+		t.Errorf("AMQPConsumer.Run() after close error = %v, want ErrChannelClosed", err)
+	}
+}
+
+func TestAMQPConsumer_CloseGeneratedTag(t *testing.T) {
+	ch := &mockConsumeChannel{deliveries: make(chan amqp.Delivery)}
+
+	c, err := vecnaamqp.NewAMQPConsumer(ch, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaamqp.AMQPConsumerOpts{Queue: "orders"})
+	if err != nil {
+		t.Fatalf("NewAMQPConsumer() error = %v", err)
+	}
+
+	if ch.tag == "" {
+		t.Fatalf("NewAMQPConsumer() consumed with an empty tag, want a generated one")
+	}
+
+	c.Close()
+	if ch.cancelled != ch.tag {
+		t.Errorf("AMQPConsumer.Close() cancelled %q, want the generated tag %q", ch.cancelled, ch.tag)
+	}
+}
+
+func TestNewAMQPConsumer_Errors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	if _, err := vecnaamqp.NewAMQPConsumer(&mockConsumeChannel{}, logger, &vecnaamqp.AMQPConsumerOpts{}); err == nil {
+		t.Errorf("NewAMQPConsumer() without queue error = nil, want error")
+	}
+
+	qosErr := errors.New("qos")
+	if _, err := vecnaamqp.NewAMQPConsumer(&mockConsumeChannel{qosErr: qosErr}, logger, &vecnaamqp.AMQPConsumerOpts{Queue: "orders"}); !errors.Is(err, qosErr) {
+		t.Errorf("NewAMQPConsumer() error = %v, want qos error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package amqp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type AMQPPublisherOpts struct {
+	// Exchange where messages are published, the default exchange routes to the queue named as the routing key
+	Exchange string
+	// RoutingKey of every message
+	RoutingKey string
+	// RoutingKeyMetaKey when given, a string on metadata under it overrides RoutingKey
+	RoutingKeyMetaKey string
+	// HeaderMetaKeys are copied from metadata (string or []byte values) to message headers
+	HeaderMetaKeys []string
+	// ContentType of messages (ex. "application/json")
+	ContentType string
+	// Persistent messages survive broker restarts on durable queues
+	Persistent bool
+	// Mandatory messages which can't be routed to any queue are returned by the broker instead of dropped
+	Mandatory bool
+	// Confirm puts the channel in confirm mode, Run returns only after the broker confirmed the message
+	Confirm bool
+}
+
+// AMQPPublisher is a task which publishes the input as message body.
+// With Confirm, concurrent Run calls share the channel and wait each its own confirmation.
+type AMQPPublisher[I []byte, O task.Nullable] struct {
+	ch     PublishChannel
+	logger *slog.Logger
+	opts   *AMQPPublisherOpts
+
+	// mu keeps delivery tags in the same order of publishings
+	mu      sync.Mutex
+	pending map[uint64]chan bool
+	closed  bool
+}
+
+// NewAMQPPublisher creates an AMQPPublisher, putting the channel in confirm mode when Confirm is given
+func NewAMQPPublisher[I []byte, O task.Nullable](ch PublishChannel, logger *slog.Logger, opts *AMQPPublisherOpts) (*AMQPPublisher[I, O], error) {
+	p := new(AMQPPublisher[I, O])
+
+	p.ch = ch
+	p.logger = logger
+	p.opts = opts
+	p.pending = map[uint64]chan bool{}
+
+	if opts.Confirm {
+		if err := ch.Confirm(false); err != nil {
+			return nil, err
+		}
+
+		go p.dispatchConfirms(ch.NotifyPublish(make(chan amqp.Confirmation, 100)))
+	}
+
+	return p, nil
+}
+
+func (p *AMQPPublisher[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	key := p.opts.RoutingKey
+	if k, ok := metadata.String(meta[p.opts.RoutingKeyMetaKey]); ok && p.opts.RoutingKeyMetaKey != "" {
+		key = k
+	}
+
+	msg := amqp.Publishing{Body: input, ContentType: p.opts.ContentType, Timestamp: time.Now()}
+
+	if p.opts.Persistent {
+		msg.DeliveryMode = amqp.Persistent
+	}
+
+	for _, k := range p.opts.HeaderMetaKeys {
+		if value, ok := metadata.String(meta[k]); ok {
+			if msg.Headers == nil {
+				msg.Headers = amqp.Table{}
+			}
+
+			msg.Headers[k] = value
+		}
+	}
+
+	confirm, err := p.publish(ctx, key, msg)
+	if err != nil {
+		p.logger.Error("error publishing amqp message", "error", err, "exchange", p.opts.Exchange, "routing_key", key)
+
+		return O(task.Nullable{}), err
+	}
+
+	if confirm == nil {
+		return O(task.Nullable{}), nil
+	}
+
+	select {
+	case acked, ok := <-confirm:
+		if !ok {
+			return O(task.Nullable{}), ErrChannelClosed
+		}
+
+		if !acked {
+			p.logger.Error("amqp message nacked", "exchange", p.opts.Exchange, "routing_key", key)
+
+			return O(task.Nullable{}), ErrNacked
+		}
+	case <-ctx.Done():
+		return O(task.Nullable{}), ctx.Err()
+	}
+
+	return O(task.Nullable{}), nil
+}
+
+// publish sends the message, returning where its confirmation will arrive (nil without Confirm)
+func (p *AMQPPublisher[I, O]) publish(ctx context.Context, key string, msg amqp.Publishing) (chan bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.opts.Confirm {
+		return nil, p.ch.PublishWithContext(ctx, p.opts.Exchange, key, p.opts.Mandatory, false, msg)
+	}
+
+	if p.closed {
+		return nil, ErrChannelClosed
+	}
+
+	tag := p.ch.GetNextPublishSeqNo()
+
+	if err := p.ch.PublishWithContext(ctx, p.opts.Exchange, key, p.opts.Mandatory, false, msg); err != nil {
+		return nil, err
+	}
+
+	confirm := make(chan bool, 1)
+	p.pending[tag] = confirm
+
+	return confirm, nil
+}
+
+// dispatchConfirms delivers every confirmation to the Run waiting it, until the channel closes
+func (p *AMQPPublisher[I, O]) dispatchConfirms(confirms chan amqp.Confirmation) {
+	for c := range confirms {
+		p.mu.Lock()
+		confirm, ok := p.pending[c.DeliveryTag]
+		delete(p.pending, c.DeliveryTag)
+		p.mu.Unlock()
+
+		if ok {
+			confirm <- c.Ack
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	for tag, confirm := range p.pending {
+		close(confirm)
+		delete(p.pending, tag)
+	}
+}
@@ -0,0 +1,168 @@
+package amqp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	vecnaamqp "github.com/otaviohenrique/vecna/pkg/task/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type published struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+// mockPublishChannel confirms publishings asynchronously, nacking the routing keys in nack
+type mockPublishChannel struct {
+	mu        sync.Mutex
+	confirm   bool
+	seq       uint64
+	confirms  chan amqp.Confirmation
+	published []published
+	nack      map[string]bool
+	err       error
+	closed    bool
+}
+
+func (m *mockPublishChannel) Confirm(_ bool) error {
+	m.confirm = true
+	m.seq = 1
+
+	return nil
+}
+
+func (m *mockPublishChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	m.confirms = confirm
+
+	return confirm
+}
+
+func (m *mockPublishChannel) GetNextPublishSeqNo() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.seq
+}
+
+func (m *mockPublishChannel) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	m.published = append(m.published, published{exchange, key, msg})
+
+	if m.confirm && !m.closed {
+		tag := m.seq
+		m.seq++
+
+		go func() { m.confirms <- amqp.Confirmation{DeliveryTag: tag, Ack: !m.nack[key]} }()
+	}
+
+	return nil
+}
+
+func TestAMQPPublisher_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       vecnaamqp.AMQPPublisherOpts
+		meta       map[string]interface{}
+		channel    *mockPublishChannel
+		wantErr    error
+		wantKey    string
+		wantHeader interface{}
+	}{
+		{"It publishes with the routing key", vecnaamqp.AMQPPublisherOpts{Exchange: "events", RoutingKey: "orders"}, map[string]interface{}{}, &mockPublishChannel{}, nil, "orders", nil},
+		{"It takes routing key and headers from metadata", vecnaamqp.AMQPPublisherOpts{Exchange: "events", RoutingKey: "orders", RoutingKeyMetaKey: "key", HeaderMetaKeys: []string{"trace_id"}},
+			map[string]interface{}{"key": "orders.eu", "trace_id": []byte("abc")}, &mockPublishChannel{}, nil, "orders.eu", "abc"},
+		{"It waits the confirmation", vecnaamqp.AMQPPublisherOpts{Exchange: "events", RoutingKey: "orders", Confirm: true}, map[string]interface{}{}, &mockPublishChannel{}, nil, "orders", nil},
+		{"It returns nacks", vecnaamqp.AMQPPublisherOpts{Exchange: "events", RoutingKey: "orders", Confirm: true}, map[string]interface{}{},
+			&mockPublishChannel{nack: map[string]bool{"orders": true}}, vecnaamqp.ErrNacked, "orders", nil},
+		{"It returns publish errors", vecnaamqp.AMQPPublisherOpts{Exchange: "events", RoutingKey: "orders", Confirm: true}, map[string]interface{}{},
+			&mockPublishChannel{err: amqp.ErrClosed}, amqp.ErrClosed, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := vecnaamqp.NewAMQPPublisher(tt.channel, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+			if err != nil {
+				t.Fatalf("NewAMQPPublisher() error = %v", err)
+			}
+
+			if _, err := p.Run(context.TODO(), []byte("payload"), tt.meta, "publisher"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AMQPPublisher.Run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantKey == "" {
+				return
+			}
+
+			got := tt.channel.published[0]
+			if got.exchange != "events" || got.key != tt.wantKey || string(got.msg.Body) != "payload" || got.msg.Headers["trace_id"] != tt.wantHeader {
+				t.Errorf("AMQPPublisher.Run() published %+v, want payload on %s with trace_id %v", got, tt.wantKey, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestAMQPPublisher_RunConcurrentConfirms(t *testing.T) {
+	channel := &mockPublishChannel{nack: map[string]bool{"key-3": true, "key-7": true}}
+
+	p, err := vecnaamqp.NewAMQPPublisher(channel, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaamqp.AMQPPublisherOpts{RoutingKeyMetaKey: "key", Confirm: true})
+	if err != nil {
+		t.Fatalf("NewAMQPPublisher() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, errs[i] = p.Run(context.TODO(), []byte("payload"), map[string]interface{}{"key": fmt.Sprintf("key-%d", i)}, "publisher")
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if wantNack := channel.nack[fmt.Sprintf("key-%d", i)]; errors.Is(err, vecnaamqp.ErrNacked) != wantNack || (!wantNack && err != nil) {
+			t.Errorf("AMQPPublisher.Run() key-%d error = %v, want nacked %v", i, err, wantNack)
+		}
+	}
+}
+
+func TestAMQPPublisher_RunChannelClosed(t *testing.T) {
+	channel := &mockPublishChannel{}
+
+	p, _ := vecnaamqp.NewAMQPPublisher(channel, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaamqp.AMQPPublisherOpts{RoutingKey: "orders", Confirm: true})
+
+	channel.mu.Lock()
+	channel.closed = true
+	close(channel.confirms)
+	channel.mu.Unlock()
+
+	// the closed channel is noticed asynchronously, so the first runs may wait confirmations which never come
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		if _, err := p.Run(ctx, []byte("payload"), map[string]interface{}{}, "publisher"); errors.Is(err, vecnaamqp.ErrChannelClosed) {
+			return
+		}
+	}
+
+	t.Errorf("AMQPPublisher.Run() never returned ErrChannelClosed")
+}
@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+)
+
+const (
+	// RejectedMaxEntries is the PayloadRejected reason when an archive has more than ExtractorOpts.MaxEntries files
+	RejectedMaxEntries = "max_entries"
+	// RejectedMaxEntrySize is the PayloadRejected reason when a file is bigger than ExtractorOpts.MaxEntrySize
+	RejectedMaxEntrySize = "max_entry_size"
+	// RejectedMaxTotalSize is the PayloadRejected reason when all files together are bigger than ExtractorOpts.MaxTotalSize
+	RejectedMaxTotalSize = "max_total_size"
+
+	// DefaultMode is used by builders when ArchiveEntry.Mode is zero
+	DefaultMode fs.FileMode = 0644
+)
+
+var (
+	ErrTooManyEntries   = errors.New("archive has too many entries")
+	ErrEntryTooLarge    = errors.New("archive entry too large")
+	ErrArchiveTooLarge  = errors.New("archive extracted size too large")
+	ErrInvalidEntryName = errors.New("invalid archive entry name")
+)
+
+// ArchiveEntry is one regular file of an archive. Use EventBreakerWorker to emit one event per file.
+type ArchiveEntry struct {
+	// Name is the path of the file inside the archive (ex. "reports/2024.csv").
+	// It comes from untrusted data, sanitize it before using as a local path.
+	Name string
+	Mode fs.FileMode
+	Data []byte
+}
+
+// ExtractorOpts limits what an extractor accepts, protecting against archive bombs. Zero values mean unlimited.
+type ExtractorOpts struct {
+	// MaxEntries is the maximum number of files on the archive
+	MaxEntries int
+	// MaxEntrySize is the maximum size in bytes of each extracted file
+	MaxEntrySize int64
+	// MaxTotalSize is the maximum size in bytes of all extracted files together
+	MaxTotalSize int64
+	// Metric receives PayloadRejected when a limit is exceeded and it implements metrics.PayloadRejecter. Optional
+	Metric metrics.Metric
+}
+
+// extraction keeps the limits state of one archive being extracted
+type extraction struct {
+	opts    *ExtractorOpts
+	logger  *slog.Logger
+	name    string
+	entries []ArchiveEntry
+	total   int64
+}
+
+// add reads one file from r, checking every limit
+func (e *extraction) add(name string, mode fs.FileMode, r io.Reader) error {
+	if e.opts.MaxEntries > 0 && len(e.entries) >= e.opts.MaxEntries {
+		return e.reject(RejectedMaxEntries, fmt.Errorf("%w: more than %d", ErrTooManyEntries, e.opts.MaxEntries))
+	}
+
+	// reads one byte past the smallest limit, enough to know it was exceeded without inflating the rest
+	limit := int64(-1)
+
+	if e.opts.MaxEntrySize > 0 {
+		limit = e.opts.MaxEntrySize + 1
+	}
+
+	if e.opts.MaxTotalSize > 0 && (limit < 0 || e.opts.MaxTotalSize-e.total+1 < limit) {
+		limit = e.opts.MaxTotalSize - e.total + 1
+	}
+
+	if limit >= 0 {
+		r = io.LimitReader(r, limit)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if e.opts.MaxEntrySize > 0 && int64(len(data)) > e.opts.MaxEntrySize {
+		return e.reject(RejectedMaxEntrySize, fmt.Errorf("%w: %s has more than %d bytes", ErrEntryTooLarge, name, e.opts.MaxEntrySize))
+	}
+
+	e.total += int64(len(data))
+
+	if e.opts.MaxTotalSize > 0 && e.total > e.opts.MaxTotalSize {
+		return e.reject(RejectedMaxTotalSize, fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, e.opts.MaxTotalSize))
+	}
+
+	e.entries = append(e.entries, ArchiveEntry{Name: name, Mode: mode, Data: data})
+
+	return nil
+}
+
+func (e *extraction) reject(reason string, err error) error {
+	e.logger.Warn("archive rejected", "reason", reason, "error", err)
+
+	if rejecter, ok := e.opts.Metric.(metrics.PayloadRejecter); ok {
+		rejecter.PayloadRejected(e.name, reason)
+	}
+
+	return err
+}
+
+// entryMode returns the mode to be written for entry
+func entryMode(entry ArchiveEntry) fs.FileMode {
+	if entry.Mode == 0 {
+		return DefaultMode
+	}
+
+	return entry.Mode
+}
+
+// validName checks an entry name before writing it into an archive
+func validName(name string) bool {
+	return name != "" && fs.ValidPath(name)
+}
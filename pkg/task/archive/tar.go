@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// TarExtractor is a generic task which unpacks a tar archive into its regular files.
+// Directories, links and other special entries are skipped. For .tar.gz use a Decompressor before it.
+type TarExtractor[I []byte, O []ArchiveEntry] struct {
+	logger *slog.Logger
+	opts   *ExtractorOpts
+}
+
+// NewTarExtractor creates a TarExtractor. opts is optional (nil)
+func NewTarExtractor[I []byte, O []ArchiveEntry](logger *slog.Logger, opts *ExtractorOpts) *TarExtractor[I, O] {
+	t := new(TarExtractor[I, O])
+
+	t.logger = logger
+	t.opts = opts
+
+	if t.opts == nil {
+		t.opts = &ExtractorOpts{}
+	}
+
+	return t
+}
+
+// Run returns every regular file of the archive, in archive order
+func (t *TarExtractor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	e := &extraction{opts: t.opts, logger: t.logger, name: name}
+	tr := tar.NewReader(bytes.NewReader(input))
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.logger.Error("error reading tar archive", "error", err)
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := e.add(hdr.Name, hdr.FileInfo().Mode().Perm(), tr); err != nil {
+			return nil, err
+		}
+	}
+
+	t.logger.Debug("tar archive extracted", "entries", len(e.entries), "size", e.total)
+
+	return e.entries, nil
+}
+
+// TarBuilder is a generic task which packs files into a tar archive. For .tar.gz use a Compressor after it.
+type TarBuilder[I []ArchiveEntry, O []byte] struct {
+	logger *slog.Logger
+}
+
+func NewTarBuilder[I []ArchiveEntry, O []byte](logger *slog.Logger) *TarBuilder[I, O] {
+	t := new(TarBuilder[I, O])
+
+	t.logger = logger
+
+	return t
+}
+
+// Run returns a tar archive containing every entry. Entry names must be relative slash separated paths.
+func (t *TarBuilder[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+	modTime := time.Now()
+
+	for _, entry := range input {
+		if !validName(entry.Name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEntryName, entry.Name)
+		}
+
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     entry.Name,
+			Mode:     int64(entryMode(entry).Perm()),
+			Size:     int64(len(entry.Data)),
+			ModTime:  modTime,
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.logger.Error("error writing tar header", "error", err, "entry", entry.Name)
+			return nil, err
+		}
+
+		if _, err := tw.Write(entry.Data); err != nil {
+			t.logger.Error("error writing tar entry", "error", err, "entry", entry.Name)
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/task/archive"
+)
+
+var entries = []archive.ArchiveEntry{
+	{Name: "a.txt", Mode: 0600, Data: []byte("file-a")},
+	{Name: "dir/b.json", Mode: 0644, Data: []byte(`{"b":true}`)},
+	{Name: "dir/empty", Mode: 0755, Data: []byte{}},
+}
+
+// tarWithSpecialEntries builds a tar with a directory and a symlink besides one regular file
+func tarWithSpecialEntries() []byte {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "dir/", Mode: 0755})
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "dir/link", Linkname: "../etc/passwd"})
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "dir/file", Mode: 0640, Size: 4})
+	tw.Write([]byte("data"))
+	tw.Close()
+
+	return buf.Bytes()
+}
+
+func TestTarBuilderExtractor_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	archived, err := archive.NewTarBuilder(logger).Run(context.TODO(), entries, map[string]interface{}{}, "builder")
+	if err != nil {
+		t.Fatalf("TarBuilder.Run() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		input      []byte
+		opts       *archive.ExtractorOpts
+		want       []archive.ArchiveEntry
+		wantErr    error
+		wantReject bool
+	}{
+		{"It extracts every entry", archived, nil, entries, nil, false},
+		{"It skips directories and links", tarWithSpecialEntries(), nil,
+			[]archive.ArchiveEntry{{Name: "dir/file", Mode: 0640, Data: []byte("data")}}, nil, false},
+		{"It rejects too many entries", archived, &archive.ExtractorOpts{MaxEntries: 2}, nil, archive.ErrTooManyEntries, true},
+		{"It rejects big entries", archived, &archive.ExtractorOpts{MaxEntrySize: 8}, nil, archive.ErrEntryTooLarge, true},
+		{"It rejects big archives", archived, &archive.ExtractorOpts{MaxTotalSize: 15}, nil, archive.ErrArchiveTooLarge, true},
+		{"It rejects one entry bigger than the total size", archived, &archive.ExtractorOpts{MaxTotalSize: 4}, nil, archive.ErrArchiveTooLarge, true},
+		{"It accepts archives within limits", archived, &archive.ExtractorOpts{MaxEntries: 3, MaxEntrySize: 10, MaxTotalSize: 16}, entries, nil, false},
+		{"It returns error on invalid archive", []byte(strings.Repeat("not-a-tar", 100)), nil, nil, tar.ErrHeader, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := metrics.NewMockMetrics()
+			if tt.opts != nil {
+				tt.opts.Metric = metric
+			}
+
+			got, err := archive.NewTarExtractor(logger, tt.opts).Run(context.TODO(), tt.input, map[string]interface{}{}, "extractor")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TarExtractor.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TarExtractor.Run() = %+v, want %+v", got, tt.want)
+			}
+
+			if rejected := metric.PayloadRejectedCalled["extractor"] == 1; rejected != tt.wantReject {
+				t.Errorf("PayloadRejected called = %v, want %v", metric.PayloadRejectedCalled["extractor"], tt.wantReject)
+			}
+		})
+	}
+}
+
+func TestTarBuilder_RunInvalidName(t *testing.T) {
+	for _, name := range []string{"", "/etc/passwd", "../escape", "dir/"} {
+		_, err := archive.NewTarBuilder(slog.Default()).Run(context.TODO(), []archive.ArchiveEntry{{Name: name}}, map[string]interface{}{}, "")
+		if !errors.Is(err, archive.ErrInvalidEntryName) {
+			t.Errorf("TarBuilder.Run(%q) error = %v, want ErrInvalidEntryName", name, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ZipExtractor is a generic task which unpacks a zip archive into its regular files.
+// Directories, links and other special entries are skipped.
+type ZipExtractor[I []byte, O []ArchiveEntry] struct {
+	logger *slog.Logger
+	opts   *ExtractorOpts
+}
+
+// NewZipExtractor creates a ZipExtractor. opts is optional (nil)
+func NewZipExtractor[I []byte, O []ArchiveEntry](logger *slog.Logger, opts *ExtractorOpts) *ZipExtractor[I, O] {
+	z := new(ZipExtractor[I, O])
+
+	z.logger = logger
+	z.opts = opts
+
+	if z.opts == nil {
+		z.opts = &ExtractorOpts{}
+	}
+
+	return z
+}
+
+// Run returns every regular file of the archive, in archive order.
+// Limits are checked against the data actually decompressed, not the sizes declared on the archive.
+func (z *ZipExtractor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	zr, err := zip.NewReader(bytes.NewReader(input), int64(len(input)))
+	if err != nil {
+		z.logger.Error("error reading zip archive", "error", err)
+		return nil, err
+	}
+
+	e := &extraction{opts: z.opts, logger: z.logger, name: name}
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		if err := z.extract(e, f); err != nil {
+			return nil, err
+		}
+	}
+
+	z.logger.Debug("zip archive extracted", "entries", len(e.entries), "size", e.total)
+
+	return e.entries, nil
+}
+
+func (z *ZipExtractor[I, O]) extract(e *extraction, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		z.logger.Error("error opening zip entry", "error", err, "entry", f.Name)
+		return err
+	}
+	defer rc.Close()
+
+	return e.add(f.Name, f.Mode().Perm(), rc)
+}
+
+// ZipBuilder is a generic task which packs files into a zip archive, compressed with deflate.
+type ZipBuilder[I []ArchiveEntry, O []byte] struct {
+	logger *slog.Logger
+}
+
+func NewZipBuilder[I []ArchiveEntry, O []byte](logger *slog.Logger) *ZipBuilder[I, O] {
+	z := new(ZipBuilder[I, O])
+
+	z.logger = logger
+
+	return z
+}
+
+// Run returns a zip archive containing every entry. Entry names must be relative slash separated paths.
+func (z *ZipBuilder[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	modTime := time.Now()
+
+	for _, entry := range input {
+		if !validName(entry.Name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidEntryName, entry.Name)
+		}
+
+		hdr := &zip.FileHeader{
+			Name:     entry.Name,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		}
+		hdr.SetMode(entryMode(entry))
+
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			z.logger.Error("error writing zip header", "error", err, "entry", entry.Name)
+			return nil, err
+		}
+
+		if _, err := w.Write(entry.Data); err != nil {
+			z.logger.Error("error writing zip entry", "error", err, "entry", entry.Name)
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/task/archive"
+)
+
+// zipWithDirectory builds a zip with a directory besides one regular file
+func zipWithDirectory() []byte {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	zw.Create("dir/")
+	w, _ := zw.Create("dir/file")
+	w.Write([]byte("data"))
+	zw.Close()
+
+	return buf.Bytes()
+}
+
+func TestZipBuilderExtractor_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	archived, err := archive.NewZipBuilder(logger).Run(context.TODO(), entries, map[string]interface{}{}, "builder")
+	if err != nil {
+		t.Fatalf("ZipBuilder.Run() error = %v", err)
+	}
+
+	bomb, _ := archive.NewZipBuilder(logger).Run(context.TODO(),
+		[]archive.ArchiveEntry{{Name: "zeros", Data: bytes.Repeat([]byte{0}, 1<<20)}}, map[string]interface{}{}, "builder")
+
+	tests := []struct {
+		name       string
+		input      []byte
+		opts       *archive.ExtractorOpts
+		want       []archive.ArchiveEntry
+		wantErr    error
+		wantReject bool
+	}{
+		{"It extracts every entry", archived, nil, entries, nil, false},
+		{"It skips directories", zipWithDirectory(), nil,
+			[]archive.ArchiveEntry{{Name: "dir/file", Mode: 0666, Data: []byte("data")}}, nil, false},
+		{"It rejects too many entries", archived, &archive.ExtractorOpts{MaxEntries: 2}, nil, archive.ErrTooManyEntries, true},
+		{"It rejects big entries", archived, &archive.ExtractorOpts{MaxEntrySize: 8}, nil, archive.ErrEntryTooLarge, true},
+		{"It rejects zip bombs", bomb, &archive.ExtractorOpts{MaxEntrySize: 1 << 16}, nil, archive.ErrEntryTooLarge, true},
+		{"It rejects big archives", archived, &archive.ExtractorOpts{MaxTotalSize: 15}, nil, archive.ErrArchiveTooLarge, true},
+		{"It rejects zip bombs by total size", bomb, &archive.ExtractorOpts{MaxTotalSize: 1 << 16}, nil, archive.ErrArchiveTooLarge, true},
+		{"It returns error on invalid archive", []byte(strings.Repeat("not-a-zip", 100)), nil, nil, zip.ErrFormat, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := metrics.NewMockMetrics()
+			if tt.opts != nil {
+				tt.opts.Metric = metric
+			}
+
+			got, err := archive.NewZipExtractor(logger, tt.opts).Run(context.TODO(), tt.input, map[string]interface{}{}, "extractor")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ZipExtractor.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ZipExtractor.Run() = %+v, want %+v", got, tt.want)
+			}
+
+			if rejected := metric.PayloadRejectedCalled["extractor"] == 1; rejected != tt.wantReject {
+				t.Errorf("PayloadRejected called = %v, want %v", metric.PayloadRejectedCalled["extractor"], tt.wantReject)
+			}
+		})
+	}
+}
+
+func TestZipExtractor_RunBombTotalSize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	// 64MB of zeros compress to a few KB
+	bomb, err := archive.NewZipBuilder(logger).Run(context.TODO(),
+		[]archive.ArchiveEntry{{Name: "zeros", Data: make([]byte, 64<<20)}}, map[string]interface{}{}, "builder")
+	if err != nil {
+		t.Fatalf("ZipBuilder.Run() error = %v", err)
+	}
+
+	extractor := archive.NewZipExtractor(logger, &archive.ExtractorOpts{MaxTotalSize: 1 << 16})
+
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+
+	_, err = extractor.Run(context.TODO(), bomb, map[string]interface{}{}, "extractor")
+
+	runtime.ReadMemStats(&after)
+
+	if !errors.Is(err, archive.ErrArchiveTooLarge) {
+		t.Fatalf("ZipExtractor.Run() error = %v, wantErr %v", err, archive.ErrArchiveTooLarge)
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("ZipExtractor.Run() allocated %d bytes, the entry was inflated past MaxTotalSize", allocated)
+	}
+}
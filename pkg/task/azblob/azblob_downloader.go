@@ -0,0 +1,189 @@
+package azblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+var (
+	// ErrNotModified is returned when a conditional download matches (blob not modified).
+	// It wraps task.ErrNoData, so workers skip the message without reporting a task error
+	ErrNotModified = fmt.Errorf("blob not modified: %w", task.ErrNoData)
+)
+
+// AzureBlobDownloader is a generic task capable of download a blob from Azure Blob Storage based on a given path and container name
+// it will receive the container name on the constructor function NewAzureBlobDownloader and the path is the input of Run()
+type AzureBlobDownloader[I string, O *AzureBlobDownloaderOutput] struct {
+	// Azure Blob client to be used
+	client DownloadStreamAPI
+	// Container name where all blobs will be downloaded
+	containerName string
+	logger        *slog.Logger
+	opts          *AzureBlobDownloaderOpts
+}
+
+type AzureBlobDownloaderOpts struct {
+	// Offset where the download starts
+	Offset int64
+	// Count of bytes downloaded from Offset. Defaults to the rest of the blob
+	Count int64
+	// IfNoneMatch downloads the blob only if its ETag differs, ErrNotModified is returned otherwise
+	IfNoneMatch string
+	// IfNoneMatchMetaKey when given, a string on metadata under it overrides IfNoneMatch (ex. the ETag of a cached copy)
+	IfNoneMatchMetaKey string
+	// IfModifiedSince downloads the blob only if modified after it, ErrNotModified is returned otherwise
+	IfModifiedSince time.Time
+	// IfModifiedSinceMetaKey when given, a time.Time on metadata under it overrides IfModifiedSince
+	IfModifiedSinceMetaKey string
+	// DetectCompression fills CompressionType from ContentEncoding/ContentType, also appending it on metadata under worker name
+	// to be read by a Decompressor with compression.METADATA_TYPE
+	DetectCompression bool
+}
+
+type AzureBlobDownloaderOutput struct {
+	Data            []byte
+	ContentType     string
+	ContentEncoding string
+	ContentLength   int64
+	ContentRange    string
+	ETag            string
+	LastModified    time.Time
+	// Metadata is the user defined metadata of the blob (x-ms-meta-*)
+	Metadata map[string]string
+	// CompressionType detected when DetectCompression is enabled ("" when not compressed or unknown)
+	CompressionType string
+}
+
+// NewAzureBlobDownloader creates an AzureBlobDownloader. opts is optional (nil)
+func NewAzureBlobDownloader[I string, O *AzureBlobDownloaderOutput](client DownloadStreamAPI, containerName string, logger *slog.Logger, opts *AzureBlobDownloaderOpts) *AzureBlobDownloader[I, O] {
+	d := new(AzureBlobDownloader[I, O])
+
+	d.client = client
+	d.containerName = containerName
+	d.logger = logger
+	d.opts = opts
+
+	if d.opts == nil {
+		d.opts = &AzureBlobDownloaderOpts{}
+	}
+
+	return d
+}
+
+// The return from Run() will be an AzureBlobDownloaderOutput (containing blob as []data and its properties)
+// When DetectCompression is enabled, the detected compression type is added to metadata under worker name.
+func (d *AzureBlobDownloader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	result, err := d.client.DownloadStream(ctx, d.containerName, string(input), d.downloadOptions(meta))
+	if err != nil {
+		if isNotModified(err) {
+			d.logger.Debug("blob not modified", "path", input)
+			return nil, fmt.Errorf("%w: %s", ErrNotModified, input)
+		}
+
+		d.logger.Error("error downloading blob", "error", err, "path", input)
+		return nil, err
+	}
+
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		d.logger.Error("error reading downloaded blob", "error", err, "path", input)
+
+		return nil, err
+	}
+
+	d.logger.Debug("blob downloaded successfully", "path", input)
+
+	out := &AzureBlobDownloaderOutput{
+		Data:            body,
+		ContentType:     deref(result.ContentType),
+		ContentEncoding: deref(result.ContentEncoding),
+		ContentRange:    deref(result.ContentRange),
+		Metadata:        make(map[string]string, len(result.Metadata)),
+	}
+
+	if result.ContentLength != nil {
+		out.ContentLength = *result.ContentLength
+	}
+
+	if result.ETag != nil {
+		out.ETag = string(*result.ETag)
+	}
+
+	if result.LastModified != nil {
+		out.LastModified = *result.LastModified
+	}
+
+	for k, v := range result.Metadata {
+		out.Metadata[k] = deref(v)
+	}
+
+	if d.opts.DetectCompression {
+		out.CompressionType = compression.DetectHeaders(out.ContentEncoding, out.ContentType)
+
+		if out.CompressionType != "" {
+			meta[name] = out.CompressionType
+		}
+	}
+
+	return out, nil
+}
+
+func (d *AzureBlobDownloader[I, O]) downloadOptions(meta map[string]interface{}) *azblob.DownloadStreamOptions {
+	options := &azblob.DownloadStreamOptions{Range: blob.HTTPRange{Offset: d.opts.Offset, Count: d.opts.Count}}
+
+	ifNoneMatch := d.opts.IfNoneMatch
+	if v, ok := meta[d.opts.IfNoneMatchMetaKey].(string); ok && d.opts.IfNoneMatchMetaKey != "" {
+		ifNoneMatch = v
+	}
+
+	ifModifiedSince := d.opts.IfModifiedSince
+	if v, ok := meta[d.opts.IfModifiedSinceMetaKey].(time.Time); ok && d.opts.IfModifiedSinceMetaKey != "" {
+		ifModifiedSince = v
+	}
+
+	if ifNoneMatch == "" && ifModifiedSince.IsZero() {
+		return options
+	}
+
+	conditions := &blob.ModifiedAccessConditions{}
+
+	if ifNoneMatch != "" {
+		etag := azcore.ETag(ifNoneMatch)
+		conditions.IfNoneMatch = &etag
+	}
+
+	if !ifModifiedSince.IsZero() {
+		conditions.IfModifiedSince = &ifModifiedSince
+	}
+
+	options.AccessConditions = &blob.AccessConditions{ModifiedAccessConditions: conditions}
+
+	return options
+}
+
+func isNotModified(err error) bool {
+	var respErr *azcore.ResponseError
+
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotModified
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
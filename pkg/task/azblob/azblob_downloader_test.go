@@ -0,0 +1,176 @@
+package azblob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	vecnaazblob "github.com/otaviohenrique/vecna/pkg/task/azblob"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+type mockDownloader struct {
+	resp       blob.DownloadResponse
+	err        error
+	calledWith []*azblob.DownloadStreamOptions
+}
+
+func (m *mockDownloader) DownloadStream(_ context.Context, _ string, _ string, o *azblob.DownloadStreamOptions) (azblob.DownloadStreamResponse, error) {
+	m.calledWith = append(m.calledWith, o)
+
+	if m.err != nil {
+		return azblob.DownloadStreamResponse{}, m.err
+	}
+
+	resp := m.resp
+	resp.Body = io.NopCloser(strings.NewReader("any-data"))
+
+	return azblob.DownloadStreamResponse{DownloadResponse: resp}, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestAzureBlobDownloader_Run(t *testing.T) {
+	lastModified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		client  *mockDownloader
+		opts    *vecnaazblob.AzureBlobDownloaderOpts
+		want    *vecnaazblob.AzureBlobDownloaderOutput
+		wantErr error
+	}{
+		{
+			"It downloads blobs and their properties",
+			&mockDownloader{resp: blob.DownloadResponse{
+				ContentType:   ptr("application/json"),
+				ContentLength: ptr(int64(8)),
+				ETag:          ptr(azcore.ETag("etag")),
+				LastModified:  &lastModified,
+				Metadata:      map[string]*string{"source": ptr("vecna")},
+			}},
+			nil,
+			&vecnaazblob.AzureBlobDownloaderOutput{Data: []byte("any-data"), ContentType: "application/json", ContentLength: 8, ETag: "etag", LastModified: lastModified, Metadata: map[string]string{"source": "vecna"}},
+			nil,
+		},
+		{
+			"It detects compression",
+			&mockDownloader{resp: blob.DownloadResponse{ContentEncoding: ptr("gzip")}},
+			&vecnaazblob.AzureBlobDownloaderOpts{DetectCompression: true},
+			&vecnaazblob.AzureBlobDownloaderOutput{Data: []byte("any-data"), ContentEncoding: "gzip", Metadata: map[string]string{}, CompressionType: compression.GZIP_TYPE},
+			nil,
+		},
+		{
+			"It returns ErrNotModified on conditional downloads",
+			&mockDownloader{err: &azcore.ResponseError{StatusCode: http.StatusNotModified}},
+			&vecnaazblob.AzureBlobDownloaderOpts{IfNoneMatch: "etag"},
+			nil,
+			vecnaazblob.ErrNotModified,
+		},
+		{
+			"It returns client errors",
+			&mockDownloader{err: errors.New("error-on-download")},
+			nil,
+			nil,
+			errors.New("error-on-download"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := vecnaazblob.NewAzureBlobDownloader(tt.client, "any-container", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			meta := map[string]interface{}{}
+
+			got, err := d.Run(context.TODO(), "path/to/blob", meta, "download")
+			if (err != nil) != (tt.wantErr != nil) || (errors.Is(tt.wantErr, vecnaazblob.ErrNotModified) && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("AzureBlobDownloader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if string(got.Data) != string(tt.want.Data) || got.ContentType != tt.want.ContentType || got.ContentEncoding != tt.want.ContentEncoding ||
+				got.ContentLength != tt.want.ContentLength || got.ETag != tt.want.ETag || !got.LastModified.Equal(tt.want.LastModified) ||
+				len(got.Metadata) != len(tt.want.Metadata) || got.Metadata["source"] != tt.want.Metadata["source"] || got.CompressionType != tt.want.CompressionType {
+				t.Errorf("AzureBlobDownloader.Run() = %+v, want %+v", got, tt.want)
+			}
+
+			if tt.want.CompressionType != "" && meta["download"] != tt.want.CompressionType {
+				t.Errorf("AzureBlobDownloader.Run() meta = %v, want compression %s", meta, tt.want.CompressionType)
+			}
+		})
+	}
+}
+
+func TestAzureBlobDownloader_RunOptions(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		opts                *vecnaazblob.AzureBlobDownloaderOpts
+		meta                map[string]interface{}
+		wantRange           blob.HTTPRange
+		wantIfNoneMatch     string
+		wantIfModifiedSince time.Time
+	}{
+		{"It downloads whole blobs without conditions", nil, map[string]interface{}{}, blob.HTTPRange{}, "", time.Time{}},
+		{"It downloads ranges", &vecnaazblob.AzureBlobDownloaderOpts{Offset: 10, Count: 5}, map[string]interface{}{}, blob.HTTPRange{Offset: 10, Count: 5}, "", time.Time{}},
+		{"It sets conditions from opts", &vecnaazblob.AzureBlobDownloaderOpts{IfNoneMatch: "etag", IfModifiedSince: since}, map[string]interface{}{}, blob.HTTPRange{}, "etag", since},
+		{
+			"It overrides conditions from metadata",
+			&vecnaazblob.AzureBlobDownloaderOpts{IfNoneMatch: "etag", IfNoneMatchMetaKey: "etag", IfModifiedSinceMetaKey: "since"},
+			map[string]interface{}{"etag": "other-etag", "since": since},
+			blob.HTTPRange{}, "other-etag", since,
+		},
+		{
+			"It ignores metadata without meta keys",
+			&vecnaazblob.AzureBlobDownloaderOpts{IfNoneMatch: "etag"},
+			map[string]interface{}{"etag": "other-etag", "since": since},
+			blob.HTTPRange{}, "etag", time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockDownloader{}
+
+			if _, err := vecnaazblob.NewAzureBlobDownloader(client, "any-container", slog.Default(), tt.opts).Run(context.TODO(), "path/to/blob", tt.meta, "download"); err != nil {
+				t.Fatalf("AzureBlobDownloader.Run() error = %v", err)
+			}
+
+			got := client.calledWith[0]
+			if got.Range != tt.wantRange {
+				t.Errorf("AzureBlobDownloader.Run() range = %+v, want %+v", got.Range, tt.wantRange)
+			}
+
+			var ifNoneMatch string
+			var ifModifiedSince time.Time
+
+			if got.AccessConditions != nil {
+				conditions := got.AccessConditions.ModifiedAccessConditions
+
+				if conditions.IfNoneMatch != nil {
+					ifNoneMatch = string(*conditions.IfNoneMatch)
+				}
+
+				if conditions.IfModifiedSince != nil {
+					ifModifiedSince = *conditions.IfModifiedSince
+				}
+			}
+
+			if ifNoneMatch != tt.wantIfNoneMatch || !ifModifiedSince.Equal(tt.wantIfModifiedSince) {
+				t.Errorf("AzureBlobDownloader.Run() conditions = %s %v, want %s %v", ifNoneMatch, ifModifiedSince, tt.wantIfNoneMatch, tt.wantIfModifiedSince)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package azblob
+
+import (
+	"context"
+	"log/slog"
+	"text/template"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/upload"
+)
+
+var (
+	ErrMissingKey = upload.ErrMissingKey
+	ErrEmptyKey   = upload.ErrEmptyKey
+)
+
+// AzureBlobUploader is a task which will upload a given data on the given path (blob name) of one container
+type AzureBlobUploader[I *AzureBlobUploaderInput, O task.Nullable] struct {
+	// Azure Blob client to be used
+	client UploadBufferAPI
+	// Container name where all blobs will be stored (unless overridden by input)
+	containerName string
+	logger        *slog.Logger
+	opts          *AzureBlobUploaderOpts
+	keyTemplate   *upload.KeyTemplate
+}
+
+// AzureBlobUploaderOpts are the defaults applied to every uploaded blob. Fields set on AzureBlobUploaderInput override them.
+type AzureBlobUploaderOpts struct {
+	// KeyTemplate renders the blob name from WorkerData metadata when input Path is empty.
+	// Ex. template.Must(template.New("key").Parse("dt={{.date}}/{{.uuid}}.json.gz"))
+	// Missing metadata keys return an error.
+	KeyTemplate *template.Template
+	// ContentType of uploaded blobs (ex. application/json)
+	ContentType string
+	// ContentEncoding of uploaded blobs (ex. gzip)
+	ContentEncoding string
+	// Metadata is user defined metadata (x-ms-meta-*)
+	Metadata map[string]string
+	// Tags applied to the uploaded blobs
+	Tags map[string]string
+	// AccessTier of uploaded blobs (ex. blob.AccessTierCool)
+	AccessTier blob.AccessTier
+	// IfNotExists only uploads blobs which don't exist yet, a precondition error is returned otherwise
+	IfNotExists bool
+	// BlockSize of uploads, blobs bigger than it are uploaded in blocks. Defaults to the client default
+	BlockSize int64
+	// Concurrency of block uploads. Defaults to the client default
+	Concurrency uint16
+}
+
+// AzureBlobUploaderInput is a envelope containing all the information needed to upload blob to Azure
+// Should be returned by adaptFn
+type AzureBlobUploaderInput struct {
+	// Path (blob name) to upload the blob. When empty it is rendered from KeyTemplate
+	Path string
+	// Bytes to be uploaded
+	Content []byte
+	// Container overrides the container given on constructor
+	Container string
+	// ContentType overrides AzureBlobUploaderOpts.ContentType
+	ContentType string
+	// ContentEncoding overrides AzureBlobUploaderOpts.ContentEncoding
+	ContentEncoding string
+	// Metadata is merged with AzureBlobUploaderOpts.Metadata (input wins)
+	Metadata map[string]string
+	// Tags is merged with AzureBlobUploaderOpts.Tags (input wins)
+	Tags map[string]string
+	// AccessTier overrides AzureBlobUploaderOpts.AccessTier
+	AccessTier blob.AccessTier
+}
+
+// NewAzureBlobUploader creates an AzureBlobUploader. opts is optional (nil)
+func NewAzureBlobUploader[I *AzureBlobUploaderInput, O task.Nullable](client UploadBufferAPI, containerName string, logger *slog.Logger, opts *AzureBlobUploaderOpts) *AzureBlobUploader[I, O] {
+	u := new(AzureBlobUploader[I, O])
+
+	u.client = client
+	u.containerName = containerName
+	u.logger = logger
+	u.opts = opts
+
+	if u.opts == nil {
+		u.opts = &AzureBlobUploaderOpts{}
+	}
+
+	u.keyTemplate = upload.NewKeyTemplate(u.opts.KeyTemplate)
+
+	return u
+}
+
+// Run() will be called by worker and should return a pointer to TaskData.
+// It doesn't merge nothing on metadata given and only return errors if any
+func (u *AzureBlobUploader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	err := u.uploadBlob(ctx, input, meta)
+
+	return O(task.Nullable{}), err
+}
+
+func (u *AzureBlobUploader[I, O]) uploadBlob(ctx context.Context, input *AzureBlobUploaderInput, meta map[string]interface{}) error {
+	key, err := u.keyTemplate.Key(input.Path, meta)
+	if err != nil {
+		u.logger.Error("error building blob upload", "error", err, "path", input.Path)
+		return err
+	}
+
+	_, err = u.client.UploadBuffer(ctx, upload.Override(input.Container, u.containerName), key, input.Content, u.uploadOptions(input))
+	if err != nil {
+		u.logger.Error("error uploading blob", "error", err, "path", key)
+		return err
+	}
+
+	u.logger.Debug("blob uploaded successfully", "path", key)
+
+	return nil
+}
+
+func (u *AzureBlobUploader[I, O]) uploadOptions(input *AzureBlobUploaderInput) *azblob.UploadBufferOptions {
+	options := &azblob.UploadBufferOptions{
+		BlockSize:   u.opts.BlockSize,
+		Concurrency: u.opts.Concurrency,
+		Tags:        upload.Merge(u.opts.Tags, input.Tags),
+	}
+
+	headers := blob.HTTPHeaders{}
+
+	if v := upload.Override(input.ContentType, u.opts.ContentType); v != "" {
+		headers.BlobContentType = &v
+	}
+
+	if v := upload.Override(input.ContentEncoding, u.opts.ContentEncoding); v != "" {
+		headers.BlobContentEncoding = &v
+	}
+
+	options.HTTPHeaders = &headers
+
+	if metadata := upload.Merge(u.opts.Metadata, input.Metadata); len(metadata) > 0 {
+		options.Metadata = make(map[string]*string, len(metadata))
+
+		for k, v := range metadata {
+			options.Metadata[k] = &v
+		}
+	}
+
+	if tier := blob.AccessTier(upload.Override(string(input.AccessTier), string(u.opts.AccessTier))); tier != "" {
+		options.AccessTier = &tier
+	}
+
+	if u.opts.IfNotExists {
+		etag := azcore.ETagAny
+		options.AccessConditions = &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &etag}}
+	}
+
+	return options
+}
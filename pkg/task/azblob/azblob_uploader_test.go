@@ -0,0 +1,147 @@
+package azblob_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	vecnaazblob "github.com/otaviohenrique/vecna/pkg/task/azblob"
+)
+
+type upload struct {
+	container string
+	blob      string
+	content   string
+	options   *azblob.UploadBufferOptions
+}
+
+type mockUploader struct {
+	err        error
+	calledWith []upload
+}
+
+func (m *mockUploader) UploadBuffer(_ context.Context, containerName string, blobName string, buffer []byte, o *azblob.UploadBufferOptions) (azblob.UploadBufferResponse, error) {
+	if m.err != nil {
+		return azblob.UploadBufferResponse{}, m.err
+	}
+
+	m.calledWith = append(m.calledWith, upload{containerName, blobName, string(buffer), o})
+
+	return azblob.UploadBufferResponse{}, nil
+}
+
+func TestAzureBlobUploader_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            *vecnaazblob.AzureBlobUploaderOpts
+		input           *vecnaazblob.AzureBlobUploaderInput
+		meta            map[string]interface{}
+		wantContainer   string
+		wantBlob        string
+		wantContentType string
+		wantMetadata    map[string]string
+		wantTier        blob.AccessTier
+	}{
+		{
+			"It uploads blobs",
+			nil,
+			&vecnaazblob.AzureBlobUploaderInput{Path: "path/to/blob", Content: []byte("any-data")},
+			map[string]interface{}{},
+			"any-container", "path/to/blob", "", nil, "",
+		},
+		{
+			"It applies defaults and input overrides",
+			&vecnaazblob.AzureBlobUploaderOpts{ContentType: "text/plain", Metadata: map[string]string{"team": "data", "source": "vecna"}, AccessTier: blob.AccessTierCool},
+			&vecnaazblob.AzureBlobUploaderInput{Path: "path/to/blob", Content: []byte("any-data"), Container: "other-container", ContentType: "application/json", Metadata: map[string]string{"source": "orders"}},
+			map[string]interface{}{},
+			"other-container", "path/to/blob", "application/json", map[string]string{"team": "data", "source": "orders"}, blob.AccessTierCool,
+		},
+		{
+			"It renders the path from metadata",
+			&vecnaazblob.AzureBlobUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("dt={{.date}}/{{.id}}.json"))},
+			&vecnaazblob.AzureBlobUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{"date": "2024-01-01", "id": "1"},
+			"any-container", "dt=2024-01-01/1.json", "", nil, "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockUploader{}
+
+			u := vecnaazblob.NewAzureBlobUploader(client, "any-container", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			if _, err := u.Run(context.TODO(), tt.input, tt.meta, "upload"); err != nil {
+				t.Fatalf("AzureBlobUploader.Run() error = %v", err)
+			}
+
+			got := client.calledWith[0]
+			if got.container != tt.wantContainer || got.blob != tt.wantBlob || got.content != "any-data" {
+				t.Errorf("AzureBlobUploader.Run() uploaded %s/%s %s, want %s/%s any-data", got.container, got.blob, got.content, tt.wantContainer, tt.wantBlob)
+			}
+
+			var contentType string
+			if got.options.HTTPHeaders.BlobContentType != nil {
+				contentType = *got.options.HTTPHeaders.BlobContentType
+			}
+
+			if contentType != tt.wantContentType {
+				t.Errorf("AzureBlobUploader.Run() content type = %s, want %s", contentType, tt.wantContentType)
+			}
+
+			if len(got.options.Metadata) != len(tt.wantMetadata) {
+				t.Errorf("AzureBlobUploader.Run() metadata = %v, want %v", got.options.Metadata, tt.wantMetadata)
+			}
+
+			for k, v := range tt.wantMetadata {
+				if got.options.Metadata[k] == nil || *got.options.Metadata[k] != v {
+					t.Errorf("AzureBlobUploader.Run() metadata %s = %v, want %s", k, got.options.Metadata[k], v)
+				}
+			}
+
+			if (got.options.AccessTier == nil && tt.wantTier != "") || (got.options.AccessTier != nil && *got.options.AccessTier != tt.wantTier) {
+				t.Errorf("AzureBlobUploader.Run() access tier = %v, want %s", got.options.AccessTier, tt.wantTier)
+			}
+		})
+	}
+}
+
+func TestAzureBlobUploader_RunIfNotExists(t *testing.T) {
+	client := &mockUploader{}
+
+	u := vecnaazblob.NewAzureBlobUploader(client, "any-container", slog.Default(), &vecnaazblob.AzureBlobUploaderOpts{IfNotExists: true})
+	if _, err := u.Run(context.TODO(), &vecnaazblob.AzureBlobUploaderInput{Path: "path/to/blob"}, map[string]interface{}{}, "upload"); err != nil {
+		t.Fatalf("AzureBlobUploader.Run() error = %v", err)
+	}
+
+	conditions := client.calledWith[0].options.AccessConditions
+	if conditions == nil || *conditions.ModifiedAccessConditions.IfNoneMatch != azcore.ETagAny {
+		t.Errorf("AzureBlobUploader.Run() access conditions = %+v, want If-None-Match *", conditions)
+	}
+}
+
+func TestAzureBlobUploader_RunErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *mockUploader
+		input   *vecnaazblob.AzureBlobUploaderInput
+		wantErr error
+	}{
+		{"It returns error without path and template", &mockUploader{}, &vecnaazblob.AzureBlobUploaderInput{}, vecnaazblob.ErrMissingKey},
+		{"It returns client errors", &mockUploader{err: errors.New("error-on-upload")}, &vecnaazblob.AzureBlobUploaderInput{Path: "path/to/blob"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := vecnaazblob.NewAzureBlobUploader(tt.client, "any-container", slog.Default(), nil)
+
+			_, err := u.Run(context.TODO(), tt.input, map[string]interface{}{}, "upload")
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("AzureBlobUploader.Run() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package azblob
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+// DownloadStreamAPI is the subset of the Azure Blob client used by AzureBlobDownloader. Satisfied by *azblob.Client
+type DownloadStreamAPI interface {
+	DownloadStream(ctx context.Context, containerName string, blobName string, o *azblob.DownloadStreamOptions) (azblob.DownloadStreamResponse, error)
+}
+
+// UploadBufferAPI is the subset of the Azure Blob client used by AzureBlobUploader. Satisfied by *azblob.Client
+type UploadBufferAPI interface {
+	UploadBuffer(ctx context.Context, containerName string, blobName string, buffer []byte, o *azblob.UploadBufferOptions) (azblob.UploadBufferResponse, error)
+}
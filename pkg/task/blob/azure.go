@@ -0,0 +1,223 @@
+package blob
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	azureblob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// AzureBlobAPI is the subset of the Azure Blob client used by AzureBlobStore. Satisfied by *azblob.Client
+type AzureBlobAPI interface {
+	DownloadStream(ctx context.Context, containerName string, blobName string, o *azblob.DownloadStreamOptions) (azblob.DownloadStreamResponse, error)
+	UploadBuffer(ctx context.Context, containerName string, blobName string, buffer []byte, o *azblob.UploadBufferOptions) (azblob.UploadBufferResponse, error)
+	NewListBlobsFlatPager(containerName string, o *azblob.ListBlobsFlatOptions) *runtime.Pager[azblob.ListBlobsFlatResponse]
+	DeleteBlob(ctx context.Context, containerName string, blobName string, o *azblob.DeleteBlobOptions) (azblob.DeleteBlobResponse, error)
+}
+
+// AzureBlobStore is a Store keeping blobs on an Azure Blob Storage container
+type AzureBlobStore struct {
+	client        AzureBlobAPI
+	containerName string
+}
+
+func NewAzureBlobStore(client AzureBlobAPI, containerName string) *AzureBlobStore {
+	s := new(AzureBlobStore)
+
+	s.client = client
+	s.containerName = containerName
+
+	return s
+}
+
+func (s *AzureBlobStore) Get(ctx context.Context, key string) ([]byte, *Attrs, error) {
+	resp, err := s.client.DownloadStream(ctx, s.containerName, key, nil)
+	if err != nil {
+		return nil, nil, azureNotFound(err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, azureDownloadAttrs(key, resp), nil
+}
+
+func (s *AzureBlobStore) Put(ctx context.Context, key string, data []byte, opts *PutOptions) error {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	upload := &azblob.UploadBufferOptions{HTTPHeaders: &azureblob.HTTPHeaders{}}
+
+	if opts.ContentType != "" {
+		upload.HTTPHeaders.BlobContentType = to.Ptr(opts.ContentType)
+	}
+
+	if opts.ContentEncoding != "" {
+		upload.HTTPHeaders.BlobContentEncoding = to.Ptr(opts.ContentEncoding)
+	}
+
+	if len(opts.Metadata) > 0 {
+		upload.Metadata = make(map[string]*string, len(opts.Metadata))
+
+		for k, v := range opts.Metadata {
+			upload.Metadata[k] = to.Ptr(v)
+		}
+	}
+
+	_, err := s.client.UploadBuffer(ctx, s.containerName, key, data, upload)
+
+	return err
+}
+
+// List pages with the container NextMarker. StartAfter is sent as StartFrom (inclusive) and also filtered here,
+// as not every account supports it
+func (s *AzureBlobStore) List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	list := &azblob.ListBlobsFlatOptions{
+		Include:    container.ListBlobsInclude{Metadata: true},
+		MaxResults: to.Ptr(int32(maxKeys)),
+		Prefix:     to.Ptr(prefix),
+	}
+
+	if opts.Token != "" {
+		list.Marker = to.Ptr(opts.Token)
+	} else if opts.StartAfter != "" {
+		// StartFrom is inclusive, the smallest key after StartAfter is StartAfter + "\x00"
+		list.StartFrom = to.Ptr(opts.StartAfter + "\x00")
+	}
+
+	pager := s.client.NewListBlobsFlatPager(s.containerName, list)
+	page := &ListPage{}
+
+	// pages filtered out by StartAfter are skipped, until a blob is found or the listing ends
+	for len(page.Blobs) == 0 && pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		page.NextToken = ""
+		if resp.NextMarker != nil {
+			page.NextToken = *resp.NextMarker
+		}
+
+		if resp.Segment == nil {
+			continue
+		}
+
+		for _, item := range resp.Segment.BlobItems {
+			if item.Name == nil || *item.Name <= opts.StartAfter {
+				continue
+			}
+
+			page.Blobs = append(page.Blobs, azureItemAttrs(item))
+		}
+	}
+
+	return page, nil
+}
+
+func (s *AzureBlobStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.DeleteBlob(ctx, s.containerName, key, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// Stat starts a download to read the blob properties, closing it without reading the content
+func (s *AzureBlobStore) Stat(ctx context.Context, key string) (*Attrs, error) {
+	resp, err := s.client.DownloadStream(ctx, s.containerName, key, nil)
+	if err != nil {
+		return nil, azureNotFound(err)
+	}
+
+	resp.Body.Close()
+
+	return azureDownloadAttrs(key, resp), nil
+}
+
+func azureDownloadAttrs(key string, resp azblob.DownloadStreamResponse) *Attrs {
+	attrs := &Attrs{
+		Key:             key,
+		Size:            azureValue(resp.ContentLength),
+		ContentType:     azureValue(resp.ContentType),
+		ContentEncoding: azureValue(resp.ContentEncoding),
+		LastModified:    azureValue(resp.LastModified),
+		Metadata:        azureMetadata(resp.Metadata),
+	}
+
+	if resp.ETag != nil {
+		attrs.ETag = string(*resp.ETag)
+	}
+
+	return attrs
+}
+
+func azureItemAttrs(item *container.BlobItem) *Attrs {
+	attrs := &Attrs{Key: *item.Name, Metadata: azureMetadata(item.Metadata)}
+
+	if p := item.Properties; p != nil {
+		attrs.Size = azureValue(p.ContentLength)
+		attrs.ContentType = azureValue(p.ContentType)
+		attrs.ContentEncoding = azureValue(p.ContentEncoding)
+		attrs.LastModified = azureValue(p.LastModified)
+
+		if p.ETag != nil {
+			attrs.ETag = string(*p.ETag)
+		}
+	}
+
+	return attrs
+}
+
+func azureMetadata(metadata map[string]*string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(metadata))
+
+	for k, v := range metadata {
+		out[k] = azureValue(v)
+	}
+
+	return out
+}
+
+// azureValue dereferences optional fields of Azure responses
+func azureValue[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+
+	return *p
+}
+
+// azureNotFound converts BlobNotFound errors to ErrNotFound
+func azureNotFound(err error) error {
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,154 @@
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	azureblob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+type azureBlob struct {
+	data        []byte
+	contentType *string
+	modified    time.Time
+}
+
+// AzureMock is a container kept in memory, markers are the index of the next blob.
+// It ignores StartFrom, like accounts which don't support it
+type AzureMock struct {
+	mu    sync.Mutex
+	blobs map[string]azureBlob
+}
+
+func (m *AzureMock) DownloadStream(_ context.Context, _ string, blobName string, _ *azblob.DownloadStreamOptions) (azblob.DownloadStreamResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.blobs[blobName]
+	if !ok {
+		return azblob.DownloadStreamResponse{}, &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: string(bloberror.BlobNotFound)}
+	}
+
+	return azblob.DownloadStreamResponse{DownloadResponse: azureblob.DownloadResponse{
+		Body:          io.NopCloser(bytes.NewReader(b.data)),
+		ContentLength: ptr(int64(len(b.data))),
+		ContentType:   b.contentType,
+		LastModified:  &b.modified,
+	}}, nil
+}
+
+func (m *AzureMock) UploadBuffer(_ context.Context, _ string, blobName string, buffer []byte, o *azblob.UploadBufferOptions) (azblob.UploadBufferResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blobs[blobName] = azureBlob{data: buffer, contentType: o.HTTPHeaders.BlobContentType, modified: time.Now()}
+
+	return azblob.UploadBufferResponse{}, nil
+}
+
+func (m *AzureMock) NewListBlobsFlatPager(_ string, o *azblob.ListBlobsFlatOptions) *runtime.Pager[azblob.ListBlobsFlatResponse] {
+	return runtime.NewPager(runtime.PagingHandler[azblob.ListBlobsFlatResponse]{
+		More: func(page azblob.ListBlobsFlatResponse) bool {
+			return page.NextMarker != nil
+		},
+		Fetcher: func(_ context.Context, page *azblob.ListBlobsFlatResponse) (azblob.ListBlobsFlatResponse, error) {
+			marker := o.Marker
+			if page != nil {
+				marker = page.NextMarker
+			}
+
+			return m.list(*o.Prefix, marker, int(*o.MaxResults)), nil
+		},
+	})
+}
+
+func (m *AzureMock) list(prefix string, marker *string, maxResults int) azblob.ListBlobsFlatResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for name := range m.blobs {
+		if len(name) >= len(prefix) && name[:len(prefix)] == prefix {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	start := 0
+	if marker != nil {
+		start, _ = strconv.Atoi(*marker)
+	}
+
+	end := min(start+maxResults, len(names))
+	resp := azblob.ListBlobsFlatResponse{}
+	resp.Segment = &container.BlobFlatListSegment{}
+
+	for _, name := range names[start:end] {
+		b := m.blobs[name]
+		resp.Segment.BlobItems = append(resp.Segment.BlobItems, &container.BlobItem{
+			Name:       ptr(name),
+			Properties: &container.BlobProperties{ContentLength: ptr(int64(len(b.data))), ContentType: b.contentType, LastModified: &b.modified},
+		})
+	}
+
+	if end < len(names) {
+		resp.NextMarker = ptr(strconv.Itoa(end))
+	}
+
+	return resp
+}
+
+func (m *AzureMock) DeleteBlob(_ context.Context, _ string, blobName string, _ *azblob.DeleteBlobOptions) (azblob.DeleteBlobResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.blobs[blobName]; !ok {
+		return azblob.DeleteBlobResponse{}, &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: string(bloberror.BlobNotFound)}
+	}
+
+	delete(m.blobs, blobName)
+
+	return azblob.DeleteBlobResponse{}, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestAzureBlobStore(t *testing.T) {
+	testStore(t, func(t *testing.T) blob.Store {
+		return blob.NewAzureBlobStore(&AzureMock{blobs: map[string]azureBlob{}}, "any-container")
+	})
+}
+
+func TestAzureBlobStore_ListSkipsPagesBeforeStartAfter(t *testing.T) {
+	client := &AzureMock{blobs: map[string]azureBlob{}}
+	s := blob.NewAzureBlobStore(client, "any-container")
+
+	for _, key := range []string{"logs/0", "logs/1", "logs/2", "logs/3", "logs/4"} {
+		s.Put(context.TODO(), key, []byte("any-data"), nil)
+	}
+
+	page, err := s.List(context.TODO(), "logs/", &blob.ListOptions{StartAfter: "logs/2", MaxKeys: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(page.Blobs) != 1 || page.Blobs[0].Key != "logs/3" || page.NextToken == "" {
+		t.Errorf("List() = %+v, want logs/3 and a next token", page)
+	}
+}
@@ -0,0 +1,111 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxKeys = 1000
+)
+
+var (
+	// ErrNotFound is returned by Get and Stat when there is no blob under the key
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned when a key can't be stored by the backend (ex. escaping the directory of a FileSystemStore)
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Attrs are the attributes of a stored blob
+type Attrs struct {
+	// Key of the blob, "/" separated
+	Key             string
+	Size            int64
+	ContentType     string
+	ContentEncoding string
+	ETag            string
+	LastModified    time.Time
+	// Metadata is the user defined metadata of the blob (not kept by FileSystemStore)
+	Metadata map[string]string
+}
+
+// PutOptions are the attributes given to a stored blob
+type PutOptions struct {
+	ContentType     string
+	ContentEncoding string
+	Metadata        map[string]string
+}
+
+// ListOptions pages through a listing
+type ListOptions struct {
+	// StartAfter lists only keys after it
+	StartAfter string
+	// Token continues a listing, given by the previous page NextToken
+	Token string
+	// MaxKeys per page. Defaults to DefaultMaxKeys
+	MaxKeys int
+}
+
+// ListPage is a page of a listing, blobs sorted by key
+type ListPage struct {
+	Blobs []*Attrs
+	// NextToken continues the listing, "" when there are no more blobs
+	NextToken string
+}
+
+// Lister lists blobs, the part of a Store used by BlobLister
+type Lister interface {
+	// List returns a page of blobs whose key starts with prefix
+	List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error)
+}
+
+// Store is a blob storage (a bucket or a directory) where blobs are kept under "/" separated keys.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the blob content and attributes, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, *Attrs, error)
+	// Put stores data under key, replacing any blob stored there
+	Put(ctx context.Context, key string, data []byte, opts *PutOptions) error
+	// List returns a page of blobs whose key starts with prefix
+	List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error)
+	// Delete removes the blob under key, it doesn't fail when there is none
+	Delete(ctx context.Context, key string) error
+	// Stat returns the blob attributes, or ErrNotFound
+	Stat(ctx context.Context, key string) (*Attrs, error)
+}
+
+// paginate pages through every blob under a prefix, for stores which can't list only a page
+func paginate(blobs []*Attrs, prefix string, opts *ListOptions) *ListPage {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	after := max(opts.StartAfter, opts.Token)
+
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+
+	page := &ListPage{}
+
+	for _, b := range blobs {
+		if !strings.HasPrefix(b.Key, prefix) || b.Key <= after {
+			continue
+		}
+
+		if len(page.Blobs) == maxKeys {
+			page.NextToken = page.Blobs[len(page.Blobs)-1].Key
+			break
+		}
+
+		page.Blobs = append(page.Blobs, b)
+	}
+
+	return page
+}
@@ -0,0 +1,73 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+type BlobDownloaderOpts struct {
+	// DetectCompression fills CompressionType from ContentEncoding/ContentType, also appending it on metadata under worker name
+	// to be read by a Decompressor with compression.METADATA_TYPE
+	DetectCompression bool
+}
+
+type BlobDownloaderOutput struct {
+	Data []byte
+	Attrs
+	// CompressionType detected when DetectCompression is enabled ("" when not compressed or unknown)
+	CompressionType string
+}
+
+// BlobDownloader is a task which downloads the blob stored under the input key of a Store
+type BlobDownloader[I string, O *BlobDownloaderOutput] struct {
+	store  Store
+	logger *slog.Logger
+	opts   *BlobDownloaderOpts
+}
+
+// NewBlobDownloader creates a BlobDownloader. opts is optional (nil)
+func NewBlobDownloader[I string, O *BlobDownloaderOutput](store Store, logger *slog.Logger, opts *BlobDownloaderOpts) *BlobDownloader[I, O] {
+	d := new(BlobDownloader[I, O])
+
+	d.store = store
+	d.logger = logger
+	d.opts = opts
+
+	if d.opts == nil {
+		d.opts = &BlobDownloaderOpts{}
+	}
+
+	return d
+}
+
+// The return from Run() will be a BlobDownloaderOutput (containing blob as []data and its attributes)
+// When DetectCompression is enabled, the detected compression type is added to metadata under worker name.
+func (d *BlobDownloader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	data, attrs, err := d.store.Get(ctx, string(input))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			d.logger.Warn("blob not found", "path", input)
+		} else {
+			d.logger.Error("error downloading blob", "error", err, "path", input)
+		}
+
+		return nil, err
+	}
+
+	d.logger.Debug("blob downloaded successfully", "path", input)
+
+	out := &BlobDownloaderOutput{Data: data, Attrs: *attrs}
+
+	if d.opts.DetectCompression {
+		out.CompressionType = compression.DetectHeaders(out.ContentEncoding, out.ContentType)
+
+		if out.CompressionType != "" {
+			meta[name] = out.CompressionType
+		}
+	}
+
+	return out, nil
+}
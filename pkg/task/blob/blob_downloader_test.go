@@ -0,0 +1,55 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+func TestBlobDownloader_Run(t *testing.T) {
+	tests := []struct {
+		name                string
+		opts                *blob.BlobDownloaderOpts
+		key                 string
+		wantData            string
+		wantCompressionType string
+		wantErr             error
+	}{
+		{"It downloads blobs", nil, "path/to/blob.json", "any-data", "", nil},
+		{"It detects compression", &blob.BlobDownloaderOpts{DetectCompression: true}, "path/to/blob.json.gz", "compressed", compression.GZIP_TYPE, nil},
+		{"It doesn't detect compression by default", nil, "path/to/blob.json.gz", "compressed", "", nil},
+		{"It returns ErrNotFound for missing blobs", nil, "path/to/missing", "", "", blob.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := blob.NewMemoryStore()
+			store.Put(context.TODO(), "path/to/blob.json", []byte("any-data"), &blob.PutOptions{ContentType: "application/json"})
+			store.Put(context.TODO(), "path/to/blob.json.gz", []byte("compressed"), &blob.PutOptions{ContentType: "application/json", ContentEncoding: "gzip"})
+
+			d := blob.NewBlobDownloader(store, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			meta := map[string]interface{}{}
+
+			got, err := d.Run(context.TODO(), tt.key, meta, "download")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BlobDownloader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if string(got.Data) != tt.wantData || got.Key != tt.key || got.ContentType != "application/json" || got.CompressionType != tt.wantCompressionType {
+				t.Errorf("BlobDownloader.Run() = %+v, want data %s compression %s", got, tt.wantData, tt.wantCompressionType)
+			}
+
+			if got, ok := meta["download"]; ok != (tt.wantCompressionType != "") || (ok && got != tt.wantCompressionType) {
+				t.Errorf("BlobDownloader.Run() meta = %v, want compression %s", meta, tt.wantCompressionType)
+			}
+		})
+	}
+}
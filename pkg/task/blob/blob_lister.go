@@ -0,0 +1,159 @@
+package blob
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type BlobListerOpts struct {
+	// Prefix to be listed
+	Prefix string
+	// StartAfter is a checkpoint, only keys after it will be listed. Use Checkpoint() to get the last emitted key
+	StartAfter string
+	// Suffix filters keys ending with it (e.g. ".json.gz")
+	Suffix string
+	// KeyPattern filters keys matching it
+	KeyPattern *regexp.Regexp
+	// ModifiedSince filters blobs modified at or after it
+	ModifiedSince time.Time
+	// ModifiedBefore filters blobs modified before it
+	ModifiedBefore time.Time
+	// MaxKeys per List call (page size). Defaults to DefaultMaxKeys
+	MaxKeys int
+	// Poll keeps listing after the prefix is exhausted, looking for keys after the last emitted one.
+	// Works best when keys grow lexicographically (e.g. date based keys)
+	Poll bool
+	// PollInterval is the minimum interval between listings once the prefix is exhausted
+	PollInterval time.Duration
+}
+
+// BlobLister is a source task (to be used with ProducerWorker) which pages through all blobs of a Lister (usually a
+// Store) under a prefix.
+// Every Run() returns one key, ready to be given to BlobDownloader, and appends its *Attrs to metadata.
+// When there is no blob to emit it returns task.ErrNoData.
+type BlobLister[I task.Nullable, O string] struct {
+	lister Lister
+	logger *slog.Logger
+	opts   *BlobListerOpts
+
+	mu          sync.Mutex
+	buffer      []*Attrs
+	token       string
+	lastKey     string
+	exhausted   bool
+	exhaustedAt time.Time
+}
+
+// NewBlobLister creates a BlobLister. opts is optional (nil)
+func NewBlobLister[I task.Nullable, O string](lister Lister, logger *slog.Logger, opts *BlobListerOpts) *BlobLister[I, O] {
+	l := new(BlobLister[I, O])
+
+	l.lister = lister
+	l.logger = logger
+	l.opts = opts
+
+	if l.opts == nil {
+		l.opts = &BlobListerOpts{}
+	}
+
+	l.lastKey = l.opts.StartAfter
+
+	return l
+}
+
+// Checkpoint returns the last emitted key. Give it as StartAfter to resume the listing later.
+func (l *BlobLister[I, O]) Checkpoint() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lastKey
+}
+
+// Run() returns the next listed key, fetching a new page when needed
+func (l *BlobLister[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.buffer) == 0 {
+		if l.exhausted && (!l.opts.Poll || time.Since(l.exhaustedAt) < l.opts.PollInterval) {
+			return "", task.ErrNoData
+		}
+
+		if err := l.nextPage(ctx); err != nil {
+			l.logger.Error("error listing blobs", "error", err, "prefix", l.opts.Prefix)
+			return "", err
+		}
+
+		if len(l.buffer) == 0 && l.exhausted {
+			return "", task.ErrNoData
+		}
+	}
+
+	attrs := l.buffer[0]
+	l.buffer = l.buffer[1:]
+	l.lastKey = attrs.Key
+
+	meta[name] = attrs
+
+	return O(l.lastKey), nil
+}
+
+// nextPage fetches the next page into buffer, applying filters
+func (l *BlobLister[I, O]) nextPage(ctx context.Context) error {
+	opts := &ListOptions{Token: l.token, MaxKeys: l.opts.MaxKeys}
+
+	if l.token == "" {
+		opts.StartAfter = l.lastKey
+	}
+
+	page, err := l.lister.List(ctx, l.opts.Prefix, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, attrs := range page.Blobs {
+		if l.match(attrs) {
+			l.buffer = append(l.buffer, attrs)
+		} else if len(l.buffer) == 0 {
+			// filtered keys still advance the checkpoint when nothing is pending
+			l.lastKey = attrs.Key
+		}
+	}
+
+	l.token = page.NextToken
+	l.exhausted = page.NextToken == ""
+
+	if l.exhausted {
+		l.exhaustedAt = time.Now()
+	}
+
+	l.logger.Debug("blobs listed", "prefix", l.opts.Prefix, "count", len(page.Blobs))
+
+	return nil
+}
+
+func (l *BlobLister[I, O]) match(attrs *Attrs) bool {
+	if l.opts.Suffix != "" && !strings.HasSuffix(attrs.Key, l.opts.Suffix) {
+		return false
+	}
+
+	if l.opts.KeyPattern != nil && !l.opts.KeyPattern.MatchString(attrs.Key) {
+		return false
+	}
+
+	if !l.opts.ModifiedSince.IsZero() && attrs.LastModified.Before(l.opts.ModifiedSince) {
+		return false
+	}
+
+	if !l.opts.ModifiedBefore.IsZero() && !attrs.LastModified.Before(l.opts.ModifiedBefore) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,97 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+// listAll runs the lister until it returns ErrNoData
+func listAll(t *testing.T, l *blob.BlobLister[task.Nullable, string]) []string {
+	t.Helper()
+
+	var keys []string
+
+	for i := 0; i < 100; i++ {
+		meta := map[string]interface{}{}
+
+		key, err := l.Run(context.TODO(), task.Nullable{}, meta, "lister")
+		if errors.Is(err, task.ErrNoData) {
+			return keys
+		}
+
+		if err != nil {
+			t.Fatalf("BlobLister.Run() error = %v", err)
+		}
+
+		if attrs, ok := meta["lister"].(*blob.Attrs); !ok || attrs.Key != key {
+			t.Errorf("BlobLister.Run() meta = %v, want attrs of %s", meta["lister"], key)
+		}
+
+		keys = append(keys, key)
+	}
+
+	t.Fatalf("BlobLister.Run() didn't stop, keys %v", keys)
+
+	return nil
+}
+
+func TestBlobLister_Run(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *blob.BlobListerOpts
+		want []string
+	}{
+		{"It lists every blob", nil, []string{"logs/0.json", "logs/1.json.gz", "logs/2.json", "logs/3.json.gz", "other/0.json"}},
+		{"It lists a prefix page by page", &blob.BlobListerOpts{Prefix: "logs/", MaxKeys: 1}, []string{"logs/0.json", "logs/1.json.gz", "logs/2.json", "logs/3.json.gz"}},
+		{"It lists after a checkpoint", &blob.BlobListerOpts{Prefix: "logs/", StartAfter: "logs/1.json.gz"}, []string{"logs/2.json", "logs/3.json.gz"}},
+		{"It filters by suffix", &blob.BlobListerOpts{Suffix: ".gz", MaxKeys: 2}, []string{"logs/1.json.gz", "logs/3.json.gz"}},
+		{"It filters by pattern", &blob.BlobListerOpts{KeyPattern: regexp.MustCompile(`/[02]\.`)}, []string{"logs/0.json", "logs/2.json", "other/0.json"}},
+		{"It filters by modification time", &blob.BlobListerOpts{ModifiedBefore: time.Now().Add(-time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := blob.NewMemoryStore()
+
+			for _, key := range []string{"logs/3.json.gz", "logs/0.json", "other/0.json", "logs/2.json", "logs/1.json.gz"} {
+				store.Put(context.TODO(), key, []byte("any-data"), nil)
+			}
+
+			l := blob.NewBlobLister(store, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			if got := listAll(t, l); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BlobLister.Run() keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlobLister_RunPoll(t *testing.T) {
+	store := newFileSystemStore(t)
+	store.Put(context.TODO(), "logs/0.json", []byte("any-data"), nil)
+
+	l := blob.NewBlobLister(store, slog.Default(), &blob.BlobListerOpts{Prefix: "logs/", Poll: true})
+
+	if got := listAll(t, l); fmt.Sprint(got) != "[logs/0.json]" {
+		t.Fatalf("BlobLister.Run() keys = %v", got)
+	}
+
+	store.Put(context.TODO(), "logs/1.json", []byte("any-data"), nil)
+
+	if got := listAll(t, l); fmt.Sprint(got) != "[logs/1.json]" {
+		t.Errorf("BlobLister.Run() keys after poll = %v, want [logs/1.json]", got)
+	}
+
+	if l.Checkpoint() != "logs/1.json" {
+		t.Errorf("BlobLister.Checkpoint() = %s, want logs/1.json", l.Checkpoint())
+	}
+}
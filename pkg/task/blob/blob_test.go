@@ -0,0 +1,154 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+// testStore checks the behavior every Store must have
+func testStore(t *testing.T, newStore func(t *testing.T) blob.Store) {
+	t.Run("It gets stored blobs", func(t *testing.T) {
+		s := newStore(t)
+
+		if err := s.Put(context.TODO(), "dir/blob.json", []byte("any-data"), &blob.PutOptions{ContentType: "application/json"}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		data, attrs, err := s.Get(context.TODO(), "dir/blob.json")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if string(data) != "any-data" || attrs.Key != "dir/blob.json" || attrs.Size != 8 || attrs.ContentType != "application/json" || attrs.LastModified.IsZero() {
+			t.Errorf("Get() = %s, %+v", data, attrs)
+		}
+	})
+
+	t.Run("It replaces stored blobs", func(t *testing.T) {
+		s := newStore(t)
+
+		s.Put(context.TODO(), "blob", []byte("old-data"), nil)
+		s.Put(context.TODO(), "blob", []byte("new-data"), nil)
+
+		if data, _, err := s.Get(context.TODO(), "blob"); err != nil || string(data) != "new-data" {
+			t.Errorf("Get() = %s, %v, want new-data", data, err)
+		}
+	})
+
+	t.Run("It stats stored blobs", func(t *testing.T) {
+		s := newStore(t)
+
+		s.Put(context.TODO(), "blob", []byte("any-data"), nil)
+
+		if attrs, err := s.Stat(context.TODO(), "blob"); err != nil || attrs.Key != "blob" || attrs.Size != 8 {
+			t.Errorf("Stat() = %+v, %v", attrs, err)
+		}
+	})
+
+	t.Run("It returns ErrNotFound for missing blobs", func(t *testing.T) {
+		s := newStore(t)
+
+		if _, _, err := s.Get(context.TODO(), "missing"); !errors.Is(err, blob.ErrNotFound) {
+			t.Errorf("Get() error = %v, want %v", err, blob.ErrNotFound)
+		}
+
+		if _, err := s.Stat(context.TODO(), "missing"); !errors.Is(err, blob.ErrNotFound) {
+			t.Errorf("Stat() error = %v, want %v", err, blob.ErrNotFound)
+		}
+	})
+
+	t.Run("It deletes blobs", func(t *testing.T) {
+		s := newStore(t)
+
+		s.Put(context.TODO(), "blob", []byte("any-data"), nil)
+
+		if err := s.Delete(context.TODO(), "blob"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		if _, err := s.Stat(context.TODO(), "blob"); !errors.Is(err, blob.ErrNotFound) {
+			t.Errorf("Stat() after Delete() error = %v, want %v", err, blob.ErrNotFound)
+		}
+
+		if err := s.Delete(context.TODO(), "blob"); err != nil {
+			t.Errorf("Delete() of missing blob error = %v", err)
+		}
+	})
+
+	t.Run("It lists blobs under a prefix page by page", func(t *testing.T) {
+		s := newStore(t)
+
+		for i := 4; i >= 0; i-- {
+			s.Put(context.TODO(), fmt.Sprintf("logs/%d.json", i), []byte("any-data"), nil)
+		}
+
+		s.Put(context.TODO(), "other/0.json", []byte("any-data"), nil)
+
+		var keys []string
+
+		opts := &blob.ListOptions{MaxKeys: 2}
+
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatalf("List() didn't stop paging, keys %v", keys)
+			}
+
+			page, err := s.List(context.TODO(), "logs/", opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			for _, attrs := range page.Blobs {
+				keys = append(keys, attrs.Key)
+			}
+
+			if page.NextToken == "" {
+				break
+			}
+
+			opts.Token = page.NextToken
+		}
+
+		if fmt.Sprint(keys) != "[logs/0.json logs/1.json logs/2.json logs/3.json logs/4.json]" {
+			t.Errorf("List() keys = %v", keys)
+		}
+	})
+
+	t.Run("It lists blobs after a key", func(t *testing.T) {
+		s := newStore(t)
+
+		for i := 0; i < 3; i++ {
+			s.Put(context.TODO(), fmt.Sprintf("logs/%d.json", i), []byte("any-data"), nil)
+		}
+
+		page, err := s.List(context.TODO(), "logs/", &blob.ListOptions{StartAfter: "logs/0.json"})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		if len(page.Blobs) != 2 || page.Blobs[0].Key != "logs/1.json" || page.NextToken != "" {
+			t.Errorf("List() = %+v", page)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) blob.Store { return blob.NewMemoryStore() })
+}
+
+func TestMemoryStore_KeepsMetadata(t *testing.T) {
+	s := blob.NewMemoryStore()
+
+	metadata := map[string]string{"source": "vecna"}
+	s.Put(context.TODO(), "blob", []byte("any-data"), &blob.PutOptions{Metadata: metadata})
+
+	metadata["source"] = "changed"
+
+	if attrs, _ := s.Stat(context.TODO(), "blob"); attrs.Metadata["source"] != "vecna" || attrs.ETag == "" {
+		t.Errorf("Stat() = %+v, want metadata source vecna and an etag", attrs)
+	}
+}
@@ -0,0 +1,98 @@
+package blob
+
+import (
+	"context"
+	"log/slog"
+	"text/template"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/upload"
+)
+
+var (
+	ErrMissingKey = upload.ErrMissingKey
+	ErrEmptyKey   = upload.ErrEmptyKey
+)
+
+// BlobUploaderOpts are the defaults applied to every uploaded blob. Fields set on BlobUploaderInput override them.
+type BlobUploaderOpts struct {
+	// KeyTemplate renders the key from WorkerData metadata when input Path is empty.
+	// Ex. template.Must(template.New("key").Parse("dt={{.date}}/{{.uuid}}.json.gz"))
+	// Missing metadata keys return an error.
+	KeyTemplate *template.Template
+	// ContentType of uploaded blobs (ex. application/json)
+	ContentType string
+	// ContentEncoding of uploaded blobs (ex. gzip)
+	ContentEncoding string
+	// Metadata is user defined metadata
+	Metadata map[string]string
+}
+
+// BlobUploaderInput is a envelope containing all the information needed to upload a blob
+type BlobUploaderInput struct {
+	// Path (key) to upload the blob. When empty it is rendered from KeyTemplate
+	Path string
+	// Bytes to be uploaded
+	Content []byte
+	// ContentType overrides BlobUploaderOpts.ContentType
+	ContentType string
+	// ContentEncoding overrides BlobUploaderOpts.ContentEncoding
+	ContentEncoding string
+	// Metadata is merged with BlobUploaderOpts.Metadata (input wins)
+	Metadata map[string]string
+}
+
+// BlobUploader is a task which stores the input content on a Store
+type BlobUploader[I *BlobUploaderInput, O task.Nullable] struct {
+	store       Store
+	logger      *slog.Logger
+	opts        *BlobUploaderOpts
+	keyTemplate *upload.KeyTemplate
+}
+
+// NewBlobUploader creates a BlobUploader. opts is optional (nil)
+func NewBlobUploader[I *BlobUploaderInput, O task.Nullable](store Store, logger *slog.Logger, opts *BlobUploaderOpts) *BlobUploader[I, O] {
+	u := new(BlobUploader[I, O])
+
+	u.store = store
+	u.logger = logger
+	u.opts = opts
+
+	if u.opts == nil {
+		u.opts = &BlobUploaderOpts{}
+	}
+
+	u.keyTemplate = upload.NewKeyTemplate(u.opts.KeyTemplate)
+
+	return u
+}
+
+// Run() stores the blob, it doesn't merge nothing on metadata given and only return errors if any
+func (u *BlobUploader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	err := u.upload(ctx, input, meta)
+
+	return O(task.Nullable{}), err
+}
+
+func (u *BlobUploader[I, O]) upload(ctx context.Context, input *BlobUploaderInput, meta map[string]interface{}) error {
+	key, err := u.keyTemplate.Key(input.Path, meta)
+	if err != nil {
+		u.logger.Error("error building blob upload", "error", err, "path", input.Path)
+		return err
+	}
+
+	opts := &PutOptions{
+		ContentType:     upload.Override(input.ContentType, u.opts.ContentType),
+		ContentEncoding: upload.Override(input.ContentEncoding, u.opts.ContentEncoding),
+		Metadata:        upload.Merge(u.opts.Metadata, input.Metadata),
+	}
+
+	if err := u.store.Put(ctx, key, input.Content, opts); err != nil {
+		u.logger.Error("error uploading blob", "error", err, "path", key)
+		return err
+	}
+
+	u.logger.Debug("blob uploaded successfully", "path", key)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+func TestBlobUploader_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            *blob.BlobUploaderOpts
+		input           *blob.BlobUploaderInput
+		meta            map[string]interface{}
+		wantKey         string
+		wantContentType string
+		wantMetadata    map[string]string
+		wantErr         error
+	}{
+		{
+			"It uploads blobs",
+			nil,
+			&blob.BlobUploaderInput{Path: "path/to/blob", Content: []byte("any-data")},
+			map[string]interface{}{},
+			"path/to/blob", "", nil, nil,
+		},
+		{
+			"It applies defaults and input overrides",
+			&blob.BlobUploaderOpts{ContentType: "text/plain", Metadata: map[string]string{"team": "data", "source": "vecna"}},
+			&blob.BlobUploaderInput{Path: "path/to/blob", Content: []byte("any-data"), ContentType: "application/json", Metadata: map[string]string{"source": "orders"}},
+			map[string]interface{}{},
+			"path/to/blob", "application/json", map[string]string{"team": "data", "source": "orders"}, nil,
+		},
+		{
+			"It renders the key from metadata",
+			&blob.BlobUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("dt={{.date}}/{{.id}}.json"))},
+			&blob.BlobUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{"date": "2024-01-01", "id": "1"},
+			"dt=2024-01-01/1.json", "", nil, nil,
+		},
+		{
+			"It returns error when the key renders empty",
+			&blob.BlobUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("{{.id}}"))},
+			&blob.BlobUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{"id": ""},
+			"", "", nil, blob.ErrEmptyKey,
+		},
+		{
+			"It returns error without path and template",
+			nil,
+			&blob.BlobUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{},
+			"", "", nil, blob.ErrMissingKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := blob.NewMemoryStore()
+
+			u := blob.NewBlobUploader(store, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			_, err := u.Run(context.TODO(), tt.input, tt.meta, "upload")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BlobUploader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			data, attrs, err := store.Get(context.TODO(), tt.wantKey)
+			if err != nil {
+				t.Fatalf("Get(%s) error = %v", tt.wantKey, err)
+			}
+
+			if string(data) != "any-data" || attrs.ContentType != tt.wantContentType || len(attrs.Metadata) != len(tt.wantMetadata) {
+				t.Errorf("BlobUploader.Run() stored %s %+v, want any-data %s %v", data, attrs, tt.wantContentType, tt.wantMetadata)
+			}
+
+			for k, v := range tt.wantMetadata {
+				if attrs.Metadata[k] != v {
+					t.Errorf("BlobUploader.Run() metadata %s = %s, want %s", k, attrs.Metadata[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestBlobUploader_RunFileSystem(t *testing.T) {
+	store := newFileSystemStore(t)
+
+	u := blob.NewBlobUploader(store, slog.Default(), &blob.BlobUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("{{.id}}/../../escape"))})
+
+	if _, err := u.Run(context.TODO(), &blob.BlobUploaderInput{Content: []byte("any-data")}, map[string]interface{}{"id": "1"}, "upload"); !errors.Is(err, blob.ErrInvalidKey) {
+		t.Errorf("BlobUploader.Run() error = %v, want %v", err, blob.ErrInvalidKey)
+	}
+}
@@ -0,0 +1,194 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// tmpPrefix of files being written by Put, hidden from List
+const tmpPrefix = ".blob-tmp-"
+
+// FileSystemStore is a Store keeping blobs as files under a directory, keys are paths relative to it.
+// Blobs are written to a temporary file renamed on completion, so readers never see partial blobs.
+// Only content is kept: ContentType is guessed from the key extension and Metadata is dropped.
+type FileSystemStore struct {
+	dir string
+}
+
+// NewFileSystemStore creates a FileSystemStore on dir, creating it when it doesn't exist
+func NewFileSystemStore(dir string) (*FileSystemStore, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := new(FileSystemStore)
+
+	s.dir = dir
+
+	return s, nil
+}
+
+func (s *FileSystemStore) Get(_ context.Context, key string) ([]byte, *Attrs, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+
+	if info.IsDir() {
+		return nil, nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+
+	return data, fileAttrs(key, info), nil
+}
+
+func (s *FileSystemStore) Put(_ context.Context, key string, data []byte, _ *PutOptions) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *FileSystemStore) List(_ context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	var blobs []*Attrs
+
+	err := filepath.WalkDir(s.dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || strings.HasPrefix(d.Name(), tmpPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, name)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			// removed while listing
+			return nil
+		}
+
+		blobs = append(blobs, fileAttrs(key, info))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(blobs, prefix, opts), nil
+}
+
+func (s *FileSystemStore) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileSystemStore) Stat(_ context.Context, key string) (*Attrs, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	return fileAttrs(key, info), nil
+}
+
+// path returns the file of a key, rejecting keys outside the directory
+func (s *FileSystemStore) path(key string) (string, error) {
+	rel := filepath.FromSlash(key)
+	if key == "" || !filepath.IsLocal(rel) || strings.HasPrefix(path.Base(key), tmpPrefix) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.dir, rel), nil
+}
+
+func fileAttrs(key string, info fs.FileInfo) *Attrs {
+	return &Attrs{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		LastModified: info.ModTime(),
+	}
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
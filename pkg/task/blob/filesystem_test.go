@@ -0,0 +1,61 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+func newFileSystemStore(t *testing.T) blob.Store {
+	t.Helper()
+
+	s, err := blob.NewFileSystemStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileSystemStore() error = %v", err)
+	}
+
+	return s
+}
+
+func TestFileSystemStore(t *testing.T) {
+	testStore(t, newFileSystemStore)
+}
+
+func TestFileSystemStore_InvalidKeys(t *testing.T) {
+	s := newFileSystemStore(t)
+
+	for _, key := range []string{"", "../escape", "/absolute", "dir/../../escape", ".blob-tmp-1"} {
+		if err := s.Put(context.TODO(), key, []byte("any-data"), nil); !errors.Is(err, blob.ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want %v", key, err, blob.ErrInvalidKey)
+		}
+	}
+}
+
+func TestFileSystemStore_WritesFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	s, _ := blob.NewFileSystemStore(dir)
+	if err := s.Put(context.TODO(), "dir/blob.json", []byte("any-data"), nil); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "dir", "blob.json")); err != nil || string(data) != "any-data" {
+		t.Errorf("Put() wrote %s, %v, want any-data", data, err)
+	}
+
+	// a write in progress isn't listed
+	os.WriteFile(filepath.Join(dir, "dir", ".blob-tmp-123"), []byte("partial"), 0o644)
+
+	page, err := s.List(context.TODO(), "", nil)
+	if err != nil || len(page.Blobs) != 1 || page.Blobs[0].Key != "dir/blob.json" {
+		t.Errorf("List() = %+v, %v, want only dir/blob.json", page, err)
+	}
+
+	if _, err := s.Stat(context.TODO(), "dir"); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("Stat() of directory error = %v, want %v", err, blob.ErrNotFound)
+	}
+}
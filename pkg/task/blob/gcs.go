@@ -0,0 +1,136 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSStore is a Store keeping blobs on a Google Cloud Storage bucket
+type GCSStore struct {
+	bucket *storage.BucketHandle
+}
+
+func NewGCSStore(client *storage.Client, bucketName string) *GCSStore {
+	s := new(GCSStore)
+
+	s.bucket = client.Bucket(bucketName)
+
+	return s
+}
+
+func (s *GCSStore) Get(ctx context.Context, key string) ([]byte, *Attrs, error) {
+	// blobs are returned as stored, without GCS decompressive transcoding
+	reader, err := s.bucket.Object(key).ReadCompressed(true).NewReader(ctx)
+	if err != nil {
+		return nil, nil, gcsNotFound(err)
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, &Attrs{
+		Key:             key,
+		Size:            reader.Attrs.Size,
+		ContentType:     reader.Attrs.ContentType,
+		ContentEncoding: reader.Attrs.ContentEncoding,
+		LastModified:    reader.Attrs.LastModified,
+	}, nil
+}
+
+func (s *GCSStore) Put(ctx context.Context, key string, data []byte, opts *PutOptions) error {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	// the upload is aborted when ctx is canceled before Close
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = opts.ContentType
+	w.ContentEncoding = opts.ContentEncoding
+	w.Metadata = opts.Metadata
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+func (s *GCSStore) List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	query := &storage.Query{Prefix: prefix}
+	if opts.StartAfter != "" {
+		// StartOffset is inclusive, the smallest key after StartAfter is StartAfter + "\x00"
+		query.StartOffset = opts.StartAfter + "\x00"
+	}
+
+	var objs []*storage.ObjectAttrs
+
+	token, err := iterator.NewPager(s.bucket.Objects(ctx, query), maxKeys, opts.Token).NextPage(&objs)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ListPage{Blobs: make([]*Attrs, 0, len(objs)), NextToken: token}
+
+	for _, obj := range objs {
+		page.Blobs = append(page.Blobs, gcsAttrs(obj))
+	}
+
+	return page, nil
+}
+
+func (s *GCSStore) Delete(ctx context.Context, key string) error {
+	if err := s.bucket.Object(key).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *GCSStore) Stat(ctx context.Context, key string) (*Attrs, error) {
+	obj, err := s.bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		return nil, gcsNotFound(err)
+	}
+
+	return gcsAttrs(obj), nil
+}
+
+func gcsAttrs(obj *storage.ObjectAttrs) *Attrs {
+	return &Attrs{
+		Key:             obj.Name,
+		Size:            obj.Size,
+		ContentType:     obj.ContentType,
+		ContentEncoding: obj.ContentEncoding,
+		ETag:            obj.Etag,
+		LastModified:    obj.Updated,
+		Metadata:        obj.Metadata,
+	}
+}
+
+func gcsNotFound(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,22 @@
+package blob_test
+
+import (
+	"testing"
+
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+func TestGCSStore(t *testing.T) {
+	testStore(t, func(t *testing.T) blob.Store {
+		srv, err := fakestorage.NewServerWithOptions(fakestorage.Options{NoListener: true})
+		if err != nil {
+			t.Fatalf("fakestorage.NewServerWithOptions() error = %v", err)
+		}
+		t.Cleanup(srv.Stop)
+
+		srv.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "any-bucket"})
+
+		return blob.NewGCSStore(srv.Client(), "any-bucket")
+	})
+}
@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping blobs in memory, useful on tests
+type MemoryStore struct {
+	mu    sync.RWMutex
+	blobs map[string]*memoryBlob
+}
+
+type memoryBlob struct {
+	data  []byte
+	attrs Attrs
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := new(MemoryStore)
+
+	s.blobs = map[string]*memoryBlob{}
+
+	return s
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, *Attrs, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	return slices.Clone(b.data), b.copyAttrs(), nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, data []byte, opts *PutOptions) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	sum := md5.Sum(data)
+
+	b := &memoryBlob{
+		data: slices.Clone(data),
+		attrs: Attrs{
+			Key:             key,
+			Size:            int64(len(data)),
+			ContentType:     opts.ContentType,
+			ContentEncoding: opts.ContentEncoding,
+			ETag:            hex.EncodeToString(sum[:]),
+			LastModified:    time.Now(),
+			Metadata:        maps.Clone(opts.Metadata),
+		},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = b
+
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blobs := make([]*Attrs, 0, len(s.blobs))
+	for _, b := range s.blobs {
+		blobs = append(blobs, b.copyAttrs())
+	}
+
+	return paginate(blobs, prefix, opts), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+
+	return nil
+}
+
+func (s *MemoryStore) Stat(_ context.Context, key string) (*Attrs, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return b.copyAttrs(), nil
+}
+
+func (b *memoryBlob) copyAttrs() *Attrs {
+	attrs := b.attrs
+	attrs.Metadata = maps.Clone(b.attrs.Metadata)
+
+	return &attrs
+}
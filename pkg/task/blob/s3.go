@@ -0,0 +1,187 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3ListAPI is the subset of the S3 client used by S3BucketLister. Satisfied by *s3.Client
+type S3ListAPI interface {
+	ListObjectsV2(context.Context, *s3.ListObjectsV2Input, ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3API is the subset of the S3 client used by S3Store. Satisfied by *s3.Client
+type S3API interface {
+	S3ListAPI
+	GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(context.Context, *s3.DeleteObjectInput, ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	HeadObject(context.Context, *s3.HeadObjectInput, ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// S3Store is a Store keeping blobs on a S3 bucket
+type S3Store struct {
+	client     S3API
+	bucketName string
+	lister     *S3BucketLister
+}
+
+func NewS3Store(client S3API, bucketName string) *S3Store {
+	s := new(S3Store)
+
+	s.client = client
+	s.bucketName = bucketName
+	s.lister = NewS3BucketLister(client, bucketName)
+
+	return s
+}
+
+// S3BucketLister is a Lister of a S3 bucket, needing only a client which lists (ex. for a BlobLister)
+type S3BucketLister struct {
+	client     S3ListAPI
+	bucketName string
+}
+
+func NewS3BucketLister(client S3ListAPI, bucketName string) *S3BucketLister {
+	l := new(S3BucketLister)
+
+	l.client = client
+	l.bucketName = bucketName
+
+	return l
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, *Attrs, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucketName), Key: aws.String(key)})
+	if err != nil {
+		return nil, nil, s3NotFound(err)
+	}
+
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, &Attrs{
+		Key:             key,
+		Size:            aws.ToInt64(out.ContentLength),
+		ContentType:     aws.ToString(out.ContentType),
+		ContentEncoding: aws.ToString(out.ContentEncoding),
+		ETag:            aws.ToString(out.ETag),
+		LastModified:    aws.ToTime(out.LastModified),
+		Metadata:        out.Metadata,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, opts *PutOptions) error {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(key),
+		Body:     bytes.NewReader(data),
+		Metadata: opts.Metadata,
+	}
+
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+
+	if opts.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(opts.ContentEncoding)
+	}
+
+	_, err := s.client.PutObject(ctx, input)
+
+	return err
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	return s.lister.List(ctx, prefix, opts)
+}
+
+func (l *S3BucketLister) List(ctx context.Context, prefix string, opts *ListOptions) (*ListPage, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(l.bucketName), Prefix: aws.String(prefix), MaxKeys: aws.Int32(int32(maxKeys))}
+
+	if opts.Token != "" {
+		input.ContinuationToken = aws.String(opts.Token)
+	} else if opts.StartAfter != "" {
+		input.StartAfter = aws.String(opts.StartAfter)
+	}
+
+	out, err := l.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ListPage{Blobs: make([]*Attrs, 0, len(out.Contents))}
+
+	for _, obj := range out.Contents {
+		page.Blobs = append(page.Blobs, &Attrs{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			ETag:         aws.ToString(obj.ETag),
+			LastModified: aws.ToTime(obj.LastModified),
+		})
+	}
+
+	if aws.ToBool(out.IsTruncated) {
+		page.NextToken = aws.ToString(out.NextContinuationToken)
+	}
+
+	return page, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucketName), Key: aws.String(key)})
+
+	return err
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (*Attrs, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucketName), Key: aws.String(key)})
+	if err != nil {
+		return nil, s3NotFound(err)
+	}
+
+	return &Attrs{
+		Key:             key,
+		Size:            aws.ToInt64(out.ContentLength),
+		ContentType:     aws.ToString(out.ContentType),
+		ContentEncoding: aws.ToString(out.ContentEncoding),
+		ETag:            aws.ToString(out.ETag),
+		LastModified:    aws.ToTime(out.LastModified),
+		Metadata:        out.Metadata,
+	}, nil
+}
+
+// s3NotFound converts missing key errors (GetObject returns NoSuchKey, HeadObject NotFound) to ErrNotFound
+func s3NotFound(err error) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+type s3Object struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// S3Mock is a bucket kept in memory, continuation tokens are the index of the next key
+type S3Mock struct {
+	mu      sync.Mutex
+	objects map[string]s3Object
+}
+
+func (m *S3Mock) GetObject(_ context.Context, input *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	obj, ok := m.objects[*input.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(obj.data)),
+		ContentLength: aws.Int64(int64(len(obj.data))),
+		ContentType:   aws.String(obj.contentType),
+		LastModified:  aws.Time(obj.modified),
+	}, nil
+}
+
+func (m *S3Mock) PutObject(_ context.Context, input *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, _ := io.ReadAll(input.Body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[*input.Key] = s3Object{data: data, contentType: aws.ToString(input.ContentType), modified: time.Now()}
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *S3Mock) ListObjectsV2(_ context.Context, input *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, aws.ToString(input.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	start := 0
+	if input.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*input.ContinuationToken)
+	} else {
+		for start < len(keys) && keys[start] <= aws.ToString(input.StartAfter) {
+			start++
+		}
+	}
+
+	end := len(keys)
+	if input.MaxKeys != nil {
+		end = min(start+int(*input.MaxKeys), len(keys))
+	}
+
+	out := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(end < len(keys))}
+
+	for _, key := range keys[start:end] {
+		out.Contents = append(out.Contents, types.Object{Key: aws.String(key), Size: aws.Int64(int64(len(m.objects[key].data))), LastModified: aws.Time(m.objects[key].modified)})
+	}
+
+	if end < len(keys) {
+		out.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+
+	return out, nil
+}
+
+func (m *S3Mock) DeleteObject(_ context.Context, input *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, *input.Key)
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (m *S3Mock) HeadObject(_ context.Context, input *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	obj, ok := m.objects[*input.Key]
+	if !ok {
+		return nil, &types.NotFound{}
+	}
+
+	return &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(obj.data))), ContentType: aws.String(obj.contentType), LastModified: aws.Time(obj.modified)}, nil
+}
+
+func TestS3Store(t *testing.T) {
+	testStore(t, func(t *testing.T) blob.Store {
+		return blob.NewS3Store(&S3Mock{objects: map[string]s3Object{}}, "any-bucket")
+	})
+}
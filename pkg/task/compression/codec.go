@@ -0,0 +1,206 @@
+package compression
+
+import (
+	"io"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// DefaultLevel makes codecs use their default compression level. It is negative as 0 is a real level of many codecs
+// (ex. gzip without compression, brotli fastest)
+const DefaultLevel = -1
+
+// Codec creates readers and writers of one compression format.
+// Implement it and call RegisterCodec to make a custom format available to all compression tasks.
+type Codec interface {
+	// NewReader returns a reader decompressing r
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	// NewWriter returns a writer compressing into w. Level is codec specific, DefaultLevel uses codec default
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		GZIP_TYPE:    &gzipCodec{},
+		ZSTD_TYPE:    &zstdCodec{},
+		ZLIB_TYPE:    &zlibCodec{},
+		DEFLATE_TYPE: &deflateCodec{},
+		SNAPPY_TYPE:  &snappyCodec{},
+		LZ4_TYPE:     &lz4Codec{},
+		BROTLI_TYPE:  &brotliCodec{},
+	}
+)
+
+// RegisterCodec makes codec available under name, replacing any codec previously registered with the same name
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[name] = codec
+}
+
+// GetCodec returns the codec registered under name or ErrUnknownCompressionType
+func GetCodec(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, ErrUnknownCompressionType
+	}
+
+	return codec, nil
+}
+
+type gzipCodec struct{}
+
+func (c *gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (c *gzipCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		return gzip.NewWriter(w), nil
+	}
+
+	return gzip.NewWriterLevel(w, level)
+}
+
+type zstdCodec struct{}
+
+func (c *zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zstdReader{d}, nil
+}
+
+// NewWriter accepts zstd levels (1-22), mapped to the closest encoder level
+func (c *zstdCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		return zstd.NewWriter(w)
+	}
+
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+}
+
+type zlibCodec struct{}
+
+func (c *zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func (c *zlibCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		return zlib.NewWriter(w), nil
+	}
+
+	return zlib.NewWriterLevel(w, level)
+}
+
+// deflateCodec is raw deflate (RFC 1951), without zlib or gzip headers
+type deflateCodec struct{}
+
+func (c *deflateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+func (c *deflateCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		level = flate.DefaultCompression
+	}
+
+	return flate.NewWriter(w, level)
+}
+
+// snappyCodec uses the snappy framing format, compatible with other snappy implementations
+type snappyCodec struct{}
+
+func (c *snappyCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return &s2Reader{s2.NewReader(r)}, nil
+}
+
+// NewWriter accepts levels 1 (fast), 2 (better) and 3 (best)
+func (c *snappyCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	opts := []s2.WriterOption{s2.WriterSnappyCompat()}
+
+	switch {
+	case level == 2:
+		opts = append(opts, s2.WriterBetterCompression())
+	case level >= 3:
+		opts = append(opts, s2.WriterBestCompression())
+	}
+
+	return s2.NewWriter(w, opts...), nil
+}
+
+type lz4Codec struct{}
+
+func (c *lz4Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return &lz4Reader{lz4.NewReader(r)}, nil
+}
+
+// NewWriter accepts levels 1-9
+func (c *lz4Codec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	lw := lz4.NewWriter(w)
+
+	if level == DefaultLevel {
+		return lw, nil
+	}
+
+	levels := []lz4.CompressionLevel{lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4, lz4.Level5, lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9}
+	level = min(max(level, 1), len(levels))
+
+	if err := lw.Apply(lz4.CompressionLevelOption(levels[level-1])); err != nil {
+		return nil, err
+	}
+
+	return lw, nil
+}
+
+type brotliCodec struct{}
+
+func (c *brotliCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return &brotliReader{brotli.NewReader(r)}, nil
+}
+
+// NewWriter accepts levels 0-11
+func (c *brotliCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == DefaultLevel {
+		level = brotli.DefaultCompression
+	}
+
+	return brotli.NewWriterLevel(w, level), nil
+}
+
+// zstdReader releases decoder resources on Close, keeping Reset available for pooling
+type zstdReader struct{ *zstd.Decoder }
+
+func (r *zstdReader) Close() error {
+	r.Decoder.Close()
+
+	return nil
+}
+
+// s2Reader, lz4Reader and brotliReader add a no-op Close, keeping Reset available for pooling
+type s2Reader struct{ *s2.Reader }
+
+func (r *s2Reader) Close() error { return nil }
+
+type lz4Reader struct{ *lz4.Reader }
+
+func (r *lz4Reader) Close() error { return nil }
+
+type brotliReader struct{ *brotli.Reader }
+
+func (r *brotliReader) Close() error { return nil }
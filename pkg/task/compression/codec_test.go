@@ -0,0 +1,150 @@
+package compression_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+var codecTypes = []string{
+	compression.GZIP_TYPE,
+	compression.ZSTD_TYPE,
+	compression.ZLIB_TYPE,
+	compression.DEFLATE_TYPE,
+	compression.SNAPPY_TYPE,
+	compression.LZ4_TYPE,
+	compression.BROTLI_TYPE,
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	input := []byte(strings.Repeat("hello-world ", 1000))
+
+	for _, compressionType := range codecTypes {
+		for _, level := range []int{compression.DefaultLevel, 1, 3, 9} {
+			t.Run(compressionType, func(t *testing.T) {
+				compressed, err := compression.NewCompressor(compressionType, logger, &compression.CompressorOpts{Level: &level}).
+					Run(context.TODO(), input, map[string]interface{}{}, "compressor")
+				if err != nil {
+					t.Fatalf("Compressor.Run() level %d error = %v", level, err)
+				}
+
+				if len(compressed) >= len(input) {
+					t.Errorf("Compressor.Run() level %d = %d bytes, want less than %d", level, len(compressed), len(input))
+				}
+
+				got, err := compression.NewDecompressor(compressionType, logger, nil).
+					Run(context.TODO(), compressed, map[string]interface{}{}, "decompressor")
+				if err != nil {
+					t.Fatalf("Decompressor.Run() level %d error = %v", level, err)
+				}
+
+				if !reflect.DeepEqual(got, input) {
+					t.Errorf("Decompressor.Run() level %d = %d bytes, want original input", level, len(got))
+				}
+			})
+		}
+	}
+}
+
+func TestCompressor_RunLevelZero(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	input := []byte(strings.Repeat("hello-world ", 1000))
+	zero, defaultLevel := 0, compression.DefaultLevel
+
+	tests := []struct {
+		name       string
+		opts       *compression.CompressorOpts
+		wantStored bool
+	}{
+		{"It uses codec default without level", nil, false},
+		{"It uses codec default with DefaultLevel", &compression.CompressorOpts{Level: &defaultLevel}, false},
+		{"It uses level 0 as a real level (gzip without compression)", &compression.CompressorOpts{Level: &zero}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := compression.NewCompressor(compression.GZIP_TYPE, logger, tt.opts).Run(context.TODO(), input, map[string]interface{}{}, "compressor")
+			if err != nil {
+				t.Fatalf("Compressor.Run() error = %v", err)
+			}
+
+			if stored := len(compressed) > len(input); stored != tt.wantStored {
+				t.Errorf("Compressor.Run() = %d bytes from %d, want stored %v", len(compressed), len(input), tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestGetCodec_Unknown(t *testing.T) {
+	if _, err := compression.GetCodec("rar"); !errors.Is(err, compression.ErrUnknownCompressionType) {
+		t.Errorf("GetCodec() error = %v, want ErrUnknownCompressionType", err)
+	}
+
+	if _, err := compression.NewDecompressor("rar", slog.Default(), nil).Run(context.TODO(), []byte("data"), map[string]interface{}{}, ""); !errors.Is(err, compression.ErrUnknownCompressionType) {
+		t.Errorf("Decompressor.Run() error = %v, want ErrUnknownCompressionType", err)
+	}
+}
+
+// reverseCodec is a toy codec reversing bytes, used to test custom registration
+type reverseCodec struct{}
+
+type reverseWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (r *reverseWriter) Write(p []byte) (int, error) {
+	return r.buf.Write(p)
+}
+
+func (r *reverseWriter) Close() error {
+	_, err := r.w.Write(reverse(r.buf.Bytes()))
+
+	return err
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+
+	return out
+}
+
+func (c reverseCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(reverse(b))), nil
+}
+
+func (c reverseCodec) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) {
+	return &reverseWriter{w: w}, nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	compression.RegisterCodec("reverse", reverseCodec{})
+
+	compressed, err := compression.NewCompressor("reverse", logger, nil).Run(context.TODO(), []byte("abc"), map[string]interface{}{}, "")
+	if err != nil || string(compressed) != "cba" {
+		t.Fatalf("Compressor.Run() = %s, %v, want cba", compressed, err)
+	}
+
+	got, err := compression.NewDecompressor("reverse", logger, nil).Run(context.TODO(), compressed, map[string]interface{}{}, "")
+	if err != nil || string(got) != "abc" {
+		t.Errorf("Decompressor.Run() = %s, %v, want abc", got, err)
+	}
+}
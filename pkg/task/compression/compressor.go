@@ -1,75 +1,94 @@
 package compression
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"io"
 	"log/slog"
-	"os"
-
-	"github.com/klauspost/compress/gzip"
-
-	"github.com/klauspost/compress/zstd"
+	"sync"
 )
 
 var (
 	ErrUnknownCompressionType = errors.New("unknown-compression-type")
 )
 
-// Compressor is a generic task capable of compress a []byte into zstd or gzip.
+// Compressor is a generic task capable of compress a []byte into any registered codec.
 type Compressor[I, O []byte] struct {
-	// CompressionType is either "gzip" or "zstd"
+	// CompressionType must be a registered codec (ex. "gzip", "zstd", "lz4")
 	compressionType string
 	logger          *slog.Logger
+	opts            *CompressorOpts
+
+	// encoders are created on first Run and reused between messages
+	initOnce sync.Once
+	encoders *encoderPool
+	initErr  error
 }
 
-func NewWriter(compressionType string, writer io.Writer) (io.WriteCloser, error) {
-	var compressor io.WriteCloser
-	var err error
-	switch compressionType {
-	case "gzip":
-		compressor = gzip.NewWriter(writer)
-	case "zstd":
-		compressor, err = zstd.NewWriter(writer)
-	default:
-		return nil, ErrUnknownCompressionType
+type CompressorOpts struct {
+	// Level is the codec specific compression level (if not given, will use codec default)
+	Level *int
+	// Dictionary used by zstd (as generated by "zstd --train"). Greatly improves small messages compression ratio.
+	// Other codecs return ErrDictionaryUnsupported
+	Dictionary []byte
+}
+
+// level returns the given Level or DefaultLevel
+func (o *CompressorOpts) level() int {
+	if o.Level == nil {
+		return DefaultLevel
 	}
 
+	return *o.Level
+}
+
+// NewWriter returns a writer compressing into writer with the codec registered under compressionType
+func NewWriter(compressionType string, writer io.Writer) (io.WriteCloser, error) {
+	return NewWriterLevel(compressionType, writer, DefaultLevel)
+}
+
+// NewWriterLevel is like NewWriter but using the given compression level
+func NewWriterLevel(compressionType string, writer io.Writer, level int) (io.WriteCloser, error) {
+	codec, err := GetCodec(compressionType)
 	if err != nil {
 		return nil, err
 	}
 
-	return compressor, nil
+	return codec.NewWriter(writer, level)
 }
 
-// Creates a new Compressor task.
-func NewCompressor[T, K []byte](compressionType string, logger *slog.Logger) *Compressor[T, K] {
+// Creates a new Compressor task. opts is optional (nil)
+func NewCompressor[T, K []byte](compressionType string, logger *slog.Logger, opts *CompressorOpts) *Compressor[T, K] {
 	d := new(Compressor[T, K])
 
 	d.compressionType = compressionType
 	d.logger = logger
+	d.opts = opts
+
+	if d.opts == nil {
+		d.opts = &CompressorOpts{}
+	}
 
 	return d
 }
 
 // Run receives output from previous worker, calls adaptFn and compress it into selected format.
+// Encoders are reused between messages, Run is safe for concurrent use.
 func (d *Compressor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
-	var buf bytes.Buffer
-	zw, err := NewWriter(d.compressionType, &buf)
-	if err != nil {
-		return nil, err
+	d.initOnce.Do(func() {
+		d.encoders, d.initErr = newEncoderPool(d.compressionType, d.opts.level(), d.opts.Dictionary)
+	})
+
+	if d.initErr != nil {
+		d.logger.Error("error creating encoder", "error", d.initErr, "compression_type", d.compressionType)
+		return nil, d.initErr
 	}
 
-	_, err = zw.Write(input)
+	result, err := d.encoders.encode(input)
 	if err != nil {
+		d.logger.Error("error compressing data", "error", err, "compression_type", d.compressionType)
 		return nil, err
 	}
 
-	zw.Close()
-
-	result := buf.Bytes()
-	os.WriteFile("/tmp/dat5", result, 0644)
-
 	return result, nil
 }
@@ -72,6 +72,7 @@ func TestCompressor_Run(t *testing.T) {
 			d := compression.NewCompressor(
 				tt.fields.compressionType,
 				tt.fields.logger,
+				nil,
 			)
 			got, err := d.Run(tt.args.in0, tt.args.input, tt.args.meta, tt.args.in3)
 			if (err != nil) != tt.wantErr {
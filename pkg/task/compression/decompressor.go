@@ -2,13 +2,14 @@ package compression
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 
-	"github.com/klauspost/compress/zstd"
+	"github.com/otaviohenrique/vecna/pkg/metrics"
 )
 
 const (
@@ -17,56 +18,200 @@ const (
 	DEFLATE_TYPE = "deflate"
 	SNAPPY_TYPE  = "snappy"
 	ZSTD_TYPE    = "zstd"
+	LZ4_TYPE     = "lz4"
+	BROTLI_TYPE  = "brotli"
+	// METADATA_TYPE makes Decompressor read the compression type from metadata (DecompressorOpts.CompressionTypeMetaKey).
+	// Data without compression type on metadata is returned as is.
+	METADATA_TYPE = "metadata"
+	// AUTO_TYPE makes Decompressor detect the compression type from magic bytes (see Detect) and record it on
+	// metadata under worker name. Data without known signature is returned as is.
+	AUTO_TYPE = "auto"
 )
 
-func NewReader(compressionType string, reader io.Reader) (io.Reader, error) {
-	var compressor io.Reader
-	var err error
-	switch compressionType {
-	case GZIP_TYPE:
-		compressor, err = gzip.NewReader(reader)
-	case ZSTD_TYPE:
-		compressor, err = zstd.NewReader(reader)
-	default:
-		return nil, errors.New("unknown-compression-type")
-	}
+const (
+	// RejectedMaxSize is the PayloadRejected reason when decompressed data exceeds DecompressorOpts.MaxSize
+	RejectedMaxSize = "max_size"
+	// RejectedMaxRatio is the PayloadRejected reason when decompressed data exceeds DecompressorOpts.MaxRatio
+	RejectedMaxRatio = "max_ratio"
+)
 
+var (
+	// ErrDecompressedTooLarge is returned when decompressed data exceeds DecompressorOpts limits
+	ErrDecompressedTooLarge = errors.New("decompressed data too large")
+	// ErrMissingMetaKey is returned by METADATA_TYPE decompressors without DecompressorOpts.CompressionTypeMetaKey
+	ErrMissingMetaKey = errors.New("metadata compression type needs a CompressionTypeMetaKey")
+)
+
+// NewReader returns a reader decompressing reader with the codec registered under compressionType
+func NewReader(compressionType string, reader io.Reader) (io.ReadCloser, error) {
+	codec, err := GetCodec(compressionType)
 	if err != nil {
 		return nil, err
 	}
 
-	return compressor, nil
+	return codec.NewReader(reader)
 }
 
-// Decompressor is a generic task capable of decompress []byte compressed with ZSTD or GZIP
+// Decompressor is a generic task capable of decompress []byte compressed with any registered codec
 type Decompressor[T, K []byte] struct {
-	// Compression type which it will focus on decompressing. Must be either zstd or gzip
+	// Compression type which it will focus on decompressing. Must be a registered codec, METADATA_TYPE or AUTO_TYPE
 	compressionType string
 	logger          *slog.Logger
+	opts            *DecompressorOpts
+
+	// decoders by compression type, created on demand and reused between messages
+	mu       sync.Mutex
+	decoders map[string]*decoderPool
+}
+
+type DecompressorOpts struct {
+	// CompressionTypeMetaKey is where METADATA_TYPE reads the compression type on metadata, the name of the worker
+	// which detected it (ex. a S3Downloader with DetectCompression)
+	CompressionTypeMetaKey string
+	// Dictionary used by zstd, must be the one used to compress data.
+	// Other codecs return ErrDictionaryUnsupported when compressionType is explicit, with METADATA_TYPE or AUTO_TYPE it
+	// is only used for zstd messages
+	Dictionary []byte
+	// MaxSize is the maximum decompressed size in bytes, protecting against decompression bombs. 0 means unlimited
+	MaxSize int64
+	// MaxRatio is the maximum decompressed/compressed size ratio (ex. 100). 0 means unlimited
+	MaxRatio float64
+	// Metric receives PayloadRejected when a limit is exceeded and it implements metrics.PayloadRejecter. Optional
+	Metric metrics.Metric
 }
 
-func NewDecompressor[I, O []byte](compressionType string, logger *slog.Logger) *Decompressor[I, O] {
+// NewDecompressor creates a Decompressor. opts is optional (nil)
+func NewDecompressor[I, O []byte](compressionType string, logger *slog.Logger, opts *DecompressorOpts) *Decompressor[I, O] {
 	d := new(Decompressor[I, O])
 
 	d.compressionType = compressionType
 	d.logger = logger
+	d.opts = opts
+	d.decoders = map[string]*decoderPool{}
+
+	if d.opts == nil {
+		d.opts = &DecompressorOpts{}
+	}
 
 	return d
 }
 
-// The return data from Run will be []byte decompressed
-func (d *Decompressor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
-	r := bytes.NewReader(input)
-	reader, err := NewReader(d.compressionType, r)
+// getDecoders returns the decoder pool of compressionType, creating it on first use
+func (d *Decompressor[I, O]) getDecoders(compressionType string) (*decoderPool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if p, ok := d.decoders[compressionType]; ok {
+		return p, nil
+	}
+
+	dict := d.opts.Dictionary
+	if compressionType != ZSTD_TYPE && compressionType != d.compressionType {
+		// detected codecs other than zstd don't use the dictionary
+		dict = nil
+	}
 
+	p, err := newDecoderPool(compressionType, dict)
 	if err != nil {
 		return nil, err
 	}
-	decompressed, err := io.ReadAll(reader)
 
+	d.decoders[compressionType] = p
+
+	return p, nil
+}
+
+// The return data from Run will be []byte decompressed. Decoders are reused between messages, Run is safe for concurrent use.
+// Payloads exceeding MaxSize or MaxRatio are rejected with ErrDecompressedTooLarge, reading at most one byte over the limit.
+func (d *Decompressor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	compressionType := d.compressionType
+
+	if compressionType == METADATA_TYPE {
+		if d.opts.CompressionTypeMetaKey == "" {
+			return nil, ErrMissingMetaKey
+		}
+
+		compressionType, _ = meta[d.opts.CompressionTypeMetaKey].(string)
+
+		if compressionType == "" {
+			return O(input), nil
+		}
+	}
+
+	if compressionType == AUTO_TYPE {
+		var err error
+
+		compressionType, err = Detect(input)
+		if err != nil {
+			d.logger.Error("error detecting compression", "error", err)
+			return nil, err
+		}
+
+		if compressionType == "" {
+			return O(input), nil
+		}
+
+		meta[name] = compressionType
+	}
+
+	decoders, err := d.getDecoders(compressionType)
 	if err != nil {
 		return nil, err
 	}
 
+	reader, err := decoders.get(bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+
+	limit, reason := d.limit(len(input))
+
+	var src io.Reader = reader
+	if limit >= 0 {
+		src = io.LimitReader(reader, limit+1)
+	}
+
+	decompressed, err := io.ReadAll(src)
+	if err != nil {
+		reader.Close()
+
+		return nil, err
+	}
+
+	if limit >= 0 && int64(len(decompressed)) > limit {
+		// decoder stopped in the middle of the stream, it can't be reused
+		reader.Close()
+
+		d.logger.Warn("decompressed payload rejected", "reason", reason, "limit", limit, "compressed_size", len(input))
+
+		if rejecter, ok := d.opts.Metric.(metrics.PayloadRejecter); ok {
+			rejecter.PayloadRejected(name, reason)
+		}
+
+		return nil, fmt.Errorf("%w: more than %d bytes (%s)", ErrDecompressedTooLarge, limit, reason)
+	}
+
+	decoders.put(reader)
+
 	return decompressed, nil
 }
+
+// limit returns the maximum decompressed size of a payload with compressedSize bytes and the limit reason.
+// Returns -1 when unlimited.
+func (d *Decompressor[I, O]) limit(compressedSize int) (int64, string) {
+	limit, reason := int64(-1), ""
+
+	if d.opts.MaxSize > 0 {
+		limit, reason = d.opts.MaxSize, RejectedMaxSize
+	}
+
+	if d.opts.MaxRatio > 0 {
+		ratioLimit := int64(float64(compressedSize) * d.opts.MaxRatio)
+
+		if limit < 0 || ratioLimit < limit {
+			limit, reason = ratioLimit, RejectedMaxRatio
+		}
+	}
+
+	return limit, reason
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/otaviohenrique/vecna/pkg/metrics"
 	"github.com/otaviohenrique/vecna/pkg/task/compression"
 )
 
@@ -68,6 +71,56 @@ func TestDecompressor_Run(t *testing.T) {
 			in3:   "test"},
 			[]byte("test-decompression-zstd"), false,
 		},
+		{"It decompress using compression type from metadata", fields{
+			compressionType: compression.METADATA_TYPE,
+			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		}, args{
+			in0:   context.TODO(),
+			input: CompressZstd("test-decompression-metadata"),
+			meta:  map[string]interface{}{"download": "zstd"},
+			in3:   "test"},
+			[]byte("test-decompression-metadata"), false,
+		},
+		{"It passes data through when metadata has no compression type", fields{
+			compressionType: compression.METADATA_TYPE,
+			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		}, args{
+			in0:   context.TODO(),
+			input: []byte("plain-data"),
+			meta:  map[string]interface{}{},
+			in3:   "test"},
+			[]byte("plain-data"), false,
+		},
+		{"It detects compression type automatically", fields{
+			compressionType: compression.AUTO_TYPE,
+			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		}, args{
+			in0:   context.TODO(),
+			input: CompressZstd("test-decompression-auto"),
+			meta:  map[string]interface{}{},
+			in3:   "test"},
+			[]byte("test-decompression-auto"), false,
+		},
+		{"It passes uncompressed data through on auto detection", fields{
+			compressionType: compression.AUTO_TYPE,
+			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		}, args{
+			in0:   context.TODO(),
+			input: []byte(`{"plain":"data"}`),
+			meta:  map[string]interface{}{},
+			in3:   "test"},
+			[]byte(`{"plain":"data"}`), false,
+		},
+		{"It returns error on unsupported format on auto detection", fields{
+			compressionType: compression.AUTO_TYPE,
+			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		}, args{
+			in0:   context.TODO(),
+			input: []byte("BZh91AY&SY"),
+			meta:  map[string]interface{}{},
+			in3:   "test"},
+			nil, true,
+		},
 		{"It returns adaptFN error correctly", fields{
 			compressionType: "gzip",
 			logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
@@ -94,6 +147,7 @@ func TestDecompressor_Run(t *testing.T) {
 			d := compression.NewDecompressor(
 				tt.fields.compressionType,
 				tt.fields.logger,
+				&compression.DecompressorOpts{CompressionTypeMetaKey: "download"},
 			)
 			got, err := d.Run(tt.args.in0, tt.args.input, tt.args.meta, tt.args.in3)
 			if (err != nil) != tt.wantErr {
@@ -106,3 +160,61 @@ func TestDecompressor_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestDecompressor_RunLimits(t *testing.T) {
+	bomb := CompressGzip(strings.Repeat("0", 1<<20))
+
+	tests := []struct {
+		name       string
+		opts       *compression.DecompressorOpts
+		input      []byte
+		wantErr    bool
+		wantReason bool
+	}{
+		{"It accepts payload under max size", &compression.DecompressorOpts{MaxSize: 1 << 20}, bomb, false, false},
+		{"It rejects payload over max size", &compression.DecompressorOpts{MaxSize: 1024}, bomb, true, true},
+		{"It rejects payload over max ratio", &compression.DecompressorOpts{MaxRatio: 100}, bomb, true, true},
+		{"It accepts payload under max ratio", &compression.DecompressorOpts{MaxRatio: 100}, CompressGzip("small-payload"), false, false},
+		{"It applies the smallest limit", &compression.DecompressorOpts{MaxSize: 1 << 30, MaxRatio: 10}, bomb, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := metrics.NewMockMetrics()
+			tt.opts.Metric = metric
+
+			d := compression.NewDecompressor(compression.GZIP_TYPE, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			// runs twice to make sure rejected decoders aren't reused
+			for i := 0; i < 2; i++ {
+				_, err := d.Run(context.TODO(), tt.input, map[string]interface{}{}, "decompressor")
+				if errors.Is(err, compression.ErrDecompressedTooLarge) != tt.wantErr {
+					t.Fatalf("Decompressor.Run() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+
+			if got := metric.PayloadRejectedCalled["decompressor"] == 2; got != tt.wantReason {
+				t.Errorf("PayloadRejected called = %v, want %v", metric.PayloadRejectedCalled["decompressor"], tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDecompressor_RunLimitsWithoutPayloadRejecter(t *testing.T) {
+	// embedding the interface hides PayloadRejected, as a Metric implemented before it existed
+	metric := struct{ metrics.Metric }{metrics.NewMockMetrics()}
+
+	d := compression.NewDecompressor(compression.GZIP_TYPE, slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		&compression.DecompressorOpts{MaxSize: 1024, Metric: metric})
+
+	if _, err := d.Run(context.TODO(), CompressGzip(strings.Repeat("0", 1<<20)), map[string]interface{}{}, "decompressor"); !errors.Is(err, compression.ErrDecompressedTooLarge) {
+		t.Errorf("Decompressor.Run() error = %v, want %v", err, compression.ErrDecompressedTooLarge)
+	}
+}
+
+func TestDecompressor_RunMetadataWithoutMetaKey(t *testing.T) {
+	d := compression.NewDecompressor(compression.METADATA_TYPE, slog.Default(), nil)
+
+	if _, err := d.Run(context.TODO(), CompressZstd("data"), map[string]interface{}{"download": "zstd"}, "decompressor"); !errors.Is(err, compression.ErrMissingMetaKey) {
+		t.Errorf("Decompressor.Run() error = %v, want %v", err, compression.ErrMissingMetaKey)
+	}
+}
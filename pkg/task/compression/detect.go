@@ -0,0 +1,111 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedCompression is returned by Detect when data has a known compression signature without a codec
+var ErrUnsupportedCompression = errors.New("unsupported compression format")
+
+// MagicLen is the number of leading bytes Detect needs to recognize every signature
+const MagicLen = 10
+
+type signature struct {
+	name  string
+	magic []byte
+	// codec is false for formats we recognize but can't decompress
+	codec bool
+}
+
+// signatures checked by Detect. brotli and raw deflate have no magic bytes and can't be detected
+var signatures = []signature{
+	{GZIP_TYPE, []byte{0x1f, 0x8b}, true},
+	{ZSTD_TYPE, []byte{0x28, 0xb5, 0x2f, 0xfd}, true},
+	{LZ4_TYPE, []byte{0x04, 0x22, 0x4d, 0x18}, true},
+	{SNAPPY_TYPE, []byte{0xff, 0x06, 0x00, 0x00, 's', 'N', 'a', 'P', 'p', 'Y'}, true},
+	{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, false},
+	{"lz4-legacy", []byte{0x02, 0x21, 0x4c, 0x18}, false},
+	{"7z", []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}, false},
+}
+
+// Detect returns the compression type of data based on its magic bytes (only the first MagicLen bytes are needed).
+// Returns "" when no signature matches (data is treated as uncompressed) and ErrUnsupportedCompression
+// when data is compressed with a format without codec (ex. bzip2, xz).
+func Detect(data []byte) (string, error) {
+	for _, s := range signatures {
+		if !bytes.HasPrefix(data, s.magic) {
+			continue
+		}
+
+		if !s.codec {
+			return "", fmt.Errorf("%w: %s", ErrUnsupportedCompression, s.name)
+		}
+
+		return s.name, nil
+	}
+
+	if isBzip2(data) {
+		return "", fmt.Errorf("%w: bzip2", ErrUnsupportedCompression)
+	}
+
+	if isZlib(data) {
+		return ZLIB_TYPE, nil
+	}
+
+	return "", nil
+}
+
+// isBzip2 checks bzip2 header: "BZh" followed by the block size ('1'-'9'), so text starting with "BZh" doesn't match
+func isBzip2(data []byte) bool {
+	return len(data) >= 4 && bytes.HasPrefix(data, []byte("BZh")) && data[3] >= '1' && data[3] <= '9'
+}
+
+// isZlib checks zlib header (RFC 1950): deflate method with 32K window and a valid header checksum.
+// Only FLG bytes outside printable ASCII are accepted (levels 0-1, default and 7-9), so text starting with "x" never
+// matches. Levels 2-5 write FLG 0x5e ('^', as in "x^2") and aren't detected, use ZLIB_TYPE (or headers) for them.
+func isZlib(data []byte) bool {
+	if len(data) < 2 || data[0] != 0x78 {
+		return false
+	}
+
+	switch data[1] {
+	case 0x01, 0x9c, 0xda:
+		return true
+	}
+
+	return false
+}
+
+// DetectHeaders returns the compression type based on Content-Encoding or Content-Type of an object or response
+// (ex. from object storage metadata). Returns "" when it isn't compressed or the encoding is unknown.
+func DetectHeaders(contentEncoding string, contentType string) string {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "gzip", "x-gzip":
+		return GZIP_TYPE
+	case "zstd":
+		return ZSTD_TYPE
+	case "br":
+		return BROTLI_TYPE
+	case "deflate":
+		// HTTP deflate content encoding is zlib wrapped (RFC 9110)
+		return ZLIB_TYPE
+	}
+
+	switch strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0])) {
+	case "application/gzip", "application/x-gzip":
+		return GZIP_TYPE
+	case "application/zstd":
+		return ZSTD_TYPE
+	case "application/zlib":
+		return ZLIB_TYPE
+	case "application/x-lz4":
+		return LZ4_TYPE
+	case "application/x-snappy-framed":
+		return SNAPPY_TYPE
+	}
+
+	return ""
+}
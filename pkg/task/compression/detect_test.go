@@ -0,0 +1,118 @@
+package compression_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+func compress(t testing.TB, compressionType string, s string) []byte {
+	var buf bytes.Buffer
+
+	w, err := compression.NewWriter(compressionType, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	w.Write([]byte(s))
+	w.Close()
+
+	return buf.Bytes()
+}
+
+// zlibLevel compresses s with zlib at level, which sets the FLG byte of its header
+func zlibLevel(t testing.TB, level int, s string) []byte {
+	var buf bytes.Buffer
+
+	w, err := zlib.NewWriterLevel(&buf, level)
+	if err != nil {
+		t.Fatalf("zlib.NewWriterLevel() error = %v", err)
+	}
+
+	w.Write([]byte(s))
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           []byte
+		want            string
+		wantUnsupported bool
+	}{
+		{"It detects gzip", compress(t, compression.GZIP_TYPE, "data"), compression.GZIP_TYPE, false},
+		{"It detects zstd", compress(t, compression.ZSTD_TYPE, "data"), compression.ZSTD_TYPE, false},
+		{"It detects zlib", compress(t, compression.ZLIB_TYPE, "data"), compression.ZLIB_TYPE, false},
+		{"It detects lz4", compress(t, compression.LZ4_TYPE, "data"), compression.LZ4_TYPE, false},
+		{"It detects snappy", compress(t, compression.SNAPPY_TYPE, "data"), compression.SNAPPY_TYPE, false},
+		{"It detects zlib best speed", zlibLevel(t, zlib.BestSpeed, "data"), compression.ZLIB_TYPE, false},
+		{"It detects zlib best compression", zlibLevel(t, zlib.BestCompression, "data"), compression.ZLIB_TYPE, false},
+		{"It returns empty for plain text", []byte("x marks the spot"), "", false},
+		{"It returns empty for text looking like a zlib header", []byte("x^2 + 1"), "", false},
+		{"It returns empty for json", []byte(`{"a":1}`), "", false},
+		{"It returns empty for empty data", nil, "", false},
+		{"It rejects bzip2", []byte("BZh91AY&SY"), "", true},
+		{"It returns empty for text starting with BZh", []byte("BZh, a text"), "", false},
+		{"It rejects xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compression.Detect(tt.input)
+			if errors.Is(err, compression.ErrUnsupportedCompression) != tt.wantUnsupported {
+				t.Fatalf("Detect() error = %v, wantUnsupported %v", err, tt.wantUnsupported)
+			}
+
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompressor_RunAutoRecordsMetadata(t *testing.T) {
+	meta := map[string]interface{}{}
+
+	got, err := compression.NewDecompressor(compression.AUTO_TYPE, slog.Default(), nil).Run(context.TODO(), compress(t, compression.LZ4_TYPE, "lz4-data"), meta, "decompressor")
+	if err != nil || string(got) != "lz4-data" {
+		t.Fatalf("Decompressor.Run() = %s, %v, want lz4-data", got, err)
+	}
+
+	if meta["decompressor"] != compression.LZ4_TYPE {
+		t.Errorf("Decompressor.Run() metadata = %v, want %v", meta["decompressor"], compression.LZ4_TYPE)
+	}
+}
+
+func TestDetectHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		contentType     string
+		want            string
+	}{
+		{"It detects gzip encoding", "gzip", "application/json", compression.GZIP_TYPE},
+		{"It detects deflate encoding as zlib", "deflate", "", compression.ZLIB_TYPE},
+		{"It detects content types with parameters", "", "application/x-gzip; charset=binary", compression.GZIP_TYPE},
+		{"It prefers the encoding over the content type", "br", "application/zstd", compression.BROTLI_TYPE},
+		{"It detects x-gzip encoding", "x-gzip", "", compression.GZIP_TYPE},
+		{"It detects zstd encoding", "zstd", "", compression.ZSTD_TYPE},
+		{"It detects lz4 content type", "", "application/x-lz4", compression.LZ4_TYPE},
+		{"It detects snappy content type", "", "application/x-snappy-framed", compression.SNAPPY_TYPE},
+		{"It detects zlib content type", "", "application/zlib", compression.ZLIB_TYPE},
+		{"It returns empty without headers", "", "", ""},
+		{"It returns empty when not compressed", "identity", "application/json", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compression.DetectHeaders(tt.contentEncoding, tt.contentType); got != tt.want {
+				t.Errorf("DetectHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
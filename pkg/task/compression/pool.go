@@ -0,0 +1,183 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrDictionaryUnsupported is returned when a dictionary is given to a codec other than zstd
+var ErrDictionaryUnsupported = errors.New("dictionary is only supported by zstd")
+
+// resetWriter is implemented by every built-in writer, allowing it to be reused
+type resetWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// encoderPool reuses encoders of one codec and level between messages. It is safe for concurrent use.
+type encoderPool struct {
+	codec Codec
+	level int
+	pool  sync.Pool
+	// zstd replaces the pool for the built-in zstd codec, EncodeAll is safe for concurrent use
+	zstd *zstd.Encoder
+}
+
+func newEncoderPool(compressionType string, level int, dict []byte) (*encoderPool, error) {
+	codec, err := GetCodec(compressionType)
+	if err != nil {
+		return nil, err
+	}
+
+	p := new(encoderPool)
+
+	p.codec = codec
+	p.level = level
+
+	if _, ok := codec.(*zstdCodec); ok {
+		opts := []zstd.EOption{}
+
+		if level != DefaultLevel {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+
+		if dict != nil {
+			opts = append(opts, zstd.WithEncoderDict(dict))
+		}
+
+		p.zstd, err = zstd.NewWriter(nil, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	}
+
+	if dict != nil {
+		return nil, ErrDictionaryUnsupported
+	}
+
+	return p, nil
+}
+
+func (p *encoderPool) encode(src []byte) ([]byte, error) {
+	if p.zstd != nil {
+		return p.zstd.EncodeAll(src, nil), nil
+	}
+
+	var buf bytes.Buffer
+
+	w, ok := p.pool.Get().(resetWriter)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		zw, err := p.codec.NewWriter(&buf, p.level)
+		if err != nil {
+			return nil, err
+		}
+
+		// custom codecs without Reset aren't pooled
+		if w, ok = zw.(resetWriter); !ok {
+			return encodeWith(zw, src, &buf)
+		}
+	}
+
+	out, err := encodeWith(w, src, &buf)
+	if err == nil {
+		p.pool.Put(w)
+	}
+
+	return out, err
+}
+
+func encodeWith(w io.WriteCloser, src []byte, buf *bytes.Buffer) ([]byte, error) {
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decoderPool reuses decoders of one codec between messages. It is safe for concurrent use.
+type decoderPool struct {
+	newReader func(io.Reader) (io.ReadCloser, error)
+	pool      sync.Pool
+}
+
+func newDecoderPool(compressionType string, dict []byte) (*decoderPool, error) {
+	codec, err := GetCodec(compressionType)
+	if err != nil {
+		return nil, err
+	}
+
+	p := new(decoderPool)
+
+	p.newReader = codec.NewReader
+
+	if _, ok := codec.(*zstdCodec); ok {
+		// pooled decoders decode one small message at a time, background goroutines don't pay off
+		opts := []zstd.DOption{zstd.WithDecoderConcurrency(1)}
+
+		if dict != nil {
+			opts = append(opts, zstd.WithDecoderDicts(dict))
+		}
+
+		p.newReader = func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r, opts...)
+			if err != nil {
+				return nil, err
+			}
+
+			return &zstdReader{d}, nil
+		}
+	} else if dict != nil {
+		return nil, ErrDictionaryUnsupported
+	}
+
+	return p, nil
+}
+
+// get returns a decoder reading from r, reusing a pooled one when possible
+func (p *decoderPool) get(r io.Reader) (io.ReadCloser, error) {
+	if d, ok := p.pool.Get().(io.ReadCloser); ok {
+		if err := resetReader(d, r); err != nil {
+			return nil, err
+		}
+
+		return d, nil
+	}
+
+	return p.newReader(r)
+}
+
+// put returns a fully read decoder to the pool, decoders without Reset are closed instead
+func (p *decoderPool) put(d io.ReadCloser) {
+	switch d.(type) {
+	case interface{ Reset(io.Reader) error }, interface{ Reset(io.Reader, []byte) error }, interface{ Reset(io.Reader) }:
+		p.pool.Put(d)
+	default:
+		d.Close()
+	}
+}
+
+// resetReader points a pooled decoder to r (only decoders accepted by put are pooled)
+func resetReader(d io.ReadCloser, r io.Reader) error {
+	switch d := d.(type) {
+	case interface{ Reset(io.Reader) error }:
+		return d.Reset(r)
+	case interface{ Reset(io.Reader, []byte) error }:
+		return d.Reset(r, nil)
+	case interface{ Reset(io.Reader) }:
+		d.Reset(r)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package compression_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+var benchPayload = []byte(strings.Repeat(`{"id":123,"name":"vecna","tags":["a","b","c"]}`, 40))
+
+func TestCompressorDecompressor_RunConcurrently(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, compressionType := range codecTypes {
+		t.Run(compressionType, func(t *testing.T) {
+			c := compression.NewCompressor(compressionType, logger, nil)
+			d := compression.NewDecompressor(compressionType, logger, nil)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 200)
+
+			for i := 0; i < 200; i++ {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+
+					input := []byte(fmt.Sprintf("message-%d-%s", i, strings.Repeat("x", i)))
+
+					compressed, err := c.Run(context.TODO(), input, map[string]interface{}{}, "")
+					if err != nil {
+						errs <- err
+						return
+					}
+
+					got, err := d.Run(context.TODO(), compressed, map[string]interface{}{}, "")
+					if err != nil {
+						errs <- err
+						return
+					}
+
+					if !bytes.Equal(got, input) {
+						errs <- fmt.Errorf("round trip of message %d = %s", i, got)
+					}
+				}(i)
+			}
+
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCompressorDecompressor_RunDictionary(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	samples := make([][]byte, 0, 600)
+	for i := 0; i < 600; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"id":%d,"name":"vecna-%d","kind":"event","score":%d,"tags":["a","b","c"]}`, i, i*7, i%13)))
+	}
+
+	dict, err := zstd.BuildDict(zstd.BuildDictOptions{ID: 1, Contents: samples, History: bytes.Join(samples[:20], nil), Offsets: [3]int{1, 4, 8}, Level: zstd.SpeedFastest})
+	if err != nil {
+		t.Fatalf("BuildDict() error = %v", err)
+	}
+
+	input := samples[42]
+
+	withDict, err := compression.NewCompressor(compression.ZSTD_TYPE, logger, &compression.CompressorOpts{Dictionary: dict}).
+		Run(context.TODO(), input, map[string]interface{}{}, "")
+	if err != nil {
+		t.Fatalf("Compressor.Run() error = %v", err)
+	}
+
+	withoutDict, _ := compression.NewCompressor(compression.ZSTD_TYPE, logger, nil).Run(context.TODO(), input, map[string]interface{}{}, "")
+	if len(withDict) >= len(withoutDict) {
+		t.Errorf("Compressor.Run() with dictionary = %d bytes, want less than %d", len(withDict), len(withoutDict))
+	}
+
+	got, err := compression.NewDecompressor(compression.AUTO_TYPE, logger, &compression.DecompressorOpts{Dictionary: dict}).
+		Run(context.TODO(), withDict, map[string]interface{}{}, "")
+	if err != nil || !bytes.Equal(got, input) {
+		t.Errorf("Decompressor.Run() = %s, %v, want %s", got, err, input)
+	}
+
+	if _, err := compression.NewDecompressor(compression.ZSTD_TYPE, logger, nil).Run(context.TODO(), withDict, map[string]interface{}{}, ""); err == nil {
+		t.Errorf("Decompressor.Run() without dictionary error = nil, want error")
+	}
+
+	if _, err := compression.NewCompressor(compression.GZIP_TYPE, logger, &compression.CompressorOpts{Dictionary: dict}).
+		Run(context.TODO(), input, map[string]interface{}{}, ""); !errors.Is(err, compression.ErrDictionaryUnsupported) {
+		t.Errorf("Compressor.Run() error = %v, want ErrDictionaryUnsupported", err)
+	}
+}
+
+// BenchmarkNewWriter compresses every message with a new writer, as Compressor did before pooling
+func BenchmarkNewWriter(b *testing.B) {
+	for _, compressionType := range []string{compression.GZIP_TYPE, compression.ZSTD_TYPE} {
+		b.Run(compressionType, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				var buf bytes.Buffer
+
+				w, _ := compression.NewWriter(compressionType, &buf)
+				w.Write(benchPayload)
+				w.Close()
+			}
+		})
+	}
+}
+
+func BenchmarkCompressor_Run(b *testing.B) {
+	for _, compressionType := range []string{compression.GZIP_TYPE, compression.ZSTD_TYPE} {
+		b.Run(compressionType, func(b *testing.B) {
+			c := compression.NewCompressor(compressionType, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				c.Run(context.TODO(), benchPayload, nil, "")
+			}
+		})
+	}
+}
+
+// BenchmarkNewReader decompresses every message with a new reader, as Decompressor did before pooling
+func BenchmarkNewReader(b *testing.B) {
+	for _, compressionType := range []string{compression.GZIP_TYPE, compression.ZSTD_TYPE} {
+		b.Run(compressionType, func(b *testing.B) {
+			compressed := compress(b, compressionType, string(benchPayload))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				r, _ := compression.NewReader(compressionType, bytes.NewReader(compressed))
+				io.ReadAll(r)
+				r.Close()
+			}
+		})
+	}
+}
+
+func BenchmarkDecompressor_Run(b *testing.B) {
+	for _, compressionType := range []string{compression.GZIP_TYPE, compression.ZSTD_TYPE} {
+		b.Run(compressionType, func(b *testing.B) {
+			compressed := compress(b, compressionType, string(benchPayload))
+			d := compression.NewDecompressor(compressionType, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				d.Run(context.TODO(), compressed, nil, "")
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package compression
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+// readCloser closes both the decompression reader and the underlying compressed stream
+type readCloser struct {
+	io.Reader
+	closeFn func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.closeFn()
+}
+
+// asOutput returns stream as O, closing it when O can't hold it
+func asOutput[O io.ReadCloser](stream io.ReadCloser) (O, error) {
+	out, ok := stream.(O)
+	if !ok {
+		stream.Close()
+
+		return out, task.ErrStreamOutputType
+	}
+
+	return out, nil
+}
+
+// StreamDecompressor is a generic task that decompresses a stream lazily, without loading it into memory.
+// Output must be closed by next worker, closing it also closes the input stream.
+type StreamDecompressor[I, O io.ReadCloser] struct {
+	// Compression type which it will focus on decompressing. Must be a registered codec or AUTO_TYPE
+	compressionType string
+	logger          *slog.Logger
+}
+
+func NewStreamDecompressor[I, O io.ReadCloser](compressionType string, logger *slog.Logger) *StreamDecompressor[I, O] {
+	d := new(StreamDecompressor[I, O])
+
+	d.compressionType = compressionType
+	d.logger = logger
+
+	return d
+}
+
+// Run wraps the input stream into a decompressed stream
+func (d *StreamDecompressor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	var out O
+
+	compressionType := d.compressionType
+	var src io.Reader = input
+
+	if compressionType == AUTO_TYPE {
+		buffered := bufio.NewReader(input)
+		src = buffered
+
+		// Peek returns less bytes (and io.EOF) on short streams, still enough for detection
+		magic, err := buffered.Peek(MagicLen)
+		if err != nil && err != io.EOF {
+			input.Close()
+
+			return out, err
+		}
+
+		compressionType, err = Detect(magic)
+		if err != nil {
+			d.logger.Error("error detecting compression", "error", err)
+			input.Close()
+
+			return out, err
+		}
+
+		if compressionType == "" {
+			return asOutput[O](&readCloser{Reader: buffered, closeFn: input.Close})
+		}
+
+		meta[name] = compressionType
+	}
+
+	reader, err := NewReader(compressionType, src)
+	if err != nil {
+		input.Close()
+
+		return out, err
+	}
+
+	return asOutput[O](&readCloser{
+		Reader: reader,
+		closeFn: func() error {
+			reader.Close()
+
+			return input.Close()
+		},
+	})
+}
+
+// StreamCompressor is a generic task that compresses a stream lazily, without loading it into memory.
+// Compression happens on a goroutine as output is read. Output must be closed by next worker.
+type StreamCompressor[I, O io.ReadCloser] struct {
+	// CompressionType must be a registered codec
+	compressionType string
+	logger          *slog.Logger
+	opts            *CompressorOpts
+}
+
+// NewStreamCompressor creates a StreamCompressor. opts is optional (nil)
+func NewStreamCompressor[I, O io.ReadCloser](compressionType string, logger *slog.Logger, opts *CompressorOpts) *StreamCompressor[I, O] {
+	c := new(StreamCompressor[I, O])
+
+	c.compressionType = compressionType
+	c.logger = logger
+	c.opts = opts
+
+	if c.opts == nil {
+		c.opts = &CompressorOpts{}
+	}
+
+	return c
+}
+
+// Run returns a stream with input compressed. Errors while compressing are returned on output Read()
+func (c *StreamCompressor[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	var out O
+
+	pr, pw := io.Pipe()
+
+	zw, err := NewWriterLevel(c.compressionType, pw, c.opts.level())
+	if err != nil {
+		input.Close()
+
+		return out, err
+	}
+
+	go func() {
+		defer input.Close()
+
+		_, err := io.Copy(zw, input)
+
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			c.logger.Error("error compressing stream", "error", err)
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return asOutput[O](pr)
+}
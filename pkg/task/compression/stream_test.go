@@ -0,0 +1,110 @@
+package compression_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+func TestStreamCompressorDecompressor_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		compressionType string
+		input           string
+		wantErr         bool
+	}{
+		{"It streams gzip correctly", "gzip", strings.Repeat("stream-gzip", 1000), false},
+		{"It streams zstd correctly", "zstd", strings.Repeat("stream-zstd", 1000), false},
+		{"It returns error when compression type is unknown", "unknown", "data", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+			c := compression.NewStreamCompressor[io.ReadCloser, io.ReadCloser](tt.compressionType, logger, nil)
+			compressed, err := c.Run(context.TODO(), io.NopCloser(strings.NewReader(tt.input)), map[string]interface{}{}, "compressor")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamCompressor.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			d := compression.NewStreamDecompressor[io.ReadCloser, io.ReadCloser](tt.compressionType, logger)
+			decompressed, err := d.Run(context.TODO(), compressed, map[string]interface{}{}, "decompressor")
+			if err != nil {
+				t.Fatalf("StreamDecompressor.Run() error = %v", err)
+			}
+			defer decompressed.Close()
+
+			got, err := io.ReadAll(decompressed)
+			if err != nil {
+				t.Fatalf("reading decompressed stream error = %v", err)
+			}
+
+			if !bytes.Equal(got, []byte(tt.input)) {
+				t.Errorf("StreamDecompressor.Run() = %v, want %v", len(got), len(tt.input))
+			}
+		})
+	}
+}
+
+func TestStreamDecompressor_RunAuto(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name     string
+		input    []byte
+		want     string
+		wantType string
+		wantErr  bool
+	}{
+		{"It detects gzip stream", CompressGzip("auto-gzip"), "auto-gzip", "gzip", false},
+		{"It detects zstd stream", CompressZstd("auto-zstd"), "auto-zstd", "zstd", false},
+		{"It passes short plain stream through", []byte("abc"), "abc", "", false},
+		{"It returns error on unsupported format", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := map[string]interface{}{}
+
+			d := compression.NewStreamDecompressor[io.ReadCloser, io.ReadCloser](compression.AUTO_TYPE, logger)
+			out, err := d.Run(context.TODO(), io.NopCloser(bytes.NewReader(tt.input)), meta, "decompressor")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamDecompressor.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+			defer out.Close()
+
+			got, err := io.ReadAll(out)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("StreamDecompressor.Run() = %s, %v, want %s", got, err, tt.want)
+			}
+
+			if gotType, _ := meta["decompressor"].(string); gotType != tt.wantType {
+				t.Errorf("StreamDecompressor.Run() compression type = %v, want %v", gotType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestStreamCompressor_RunOutputType(t *testing.T) {
+	c := compression.NewStreamCompressor[io.ReadCloser, *os.File]("gzip", slog.New(slog.NewTextHandler(os.Stdout, nil)), nil)
+
+	_, err := c.Run(context.TODO(), io.NopCloser(strings.NewReader("data")), map[string]interface{}{}, "compressor")
+	if !errors.Is(err, task.ErrStreamOutputType) {
+		t.Errorf("StreamCompressor.Run() error = %v, want %v", err, task.ErrStreamOutputType)
+	}
+}
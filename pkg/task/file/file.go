@@ -0,0 +1,48 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrWriterClosed = errors.New("file writer closed")
+
+// FileInfo contains the information about a read file, appended on metadata under worker name
+type FileInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// File is a file read by FileReader or FileWatcher
+type File struct {
+	Data []byte
+	FileInfo
+}
+
+// readFile reads a whole file, appending its FileInfo on metadata under name
+func readFile(path string, meta map[string]interface{}, name string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &File{Data: data, FileInfo: FileInfo{Path: path, Size: int64(len(data)), ModTime: info.ModTime()}}
+
+	meta[name] = &out.FileInfo
+
+	return out, nil
+}
+
+// hidden files are skipped by sources, they include temporary files of FileWriter
+func hidden(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
@@ -0,0 +1,139 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type FileReaderOpts struct {
+	// Patterns of files to be read (filepath.Glob syntax, ex. "data/*.json")
+	Patterns []string
+}
+
+// FileReader is a source task (to be used with ProducerWorker) which reads, once, every file matching the patterns.
+// Files are globbed on the first Run() and every Run() returns one of them (sorted by path), appending its FileInfo to metadata.
+// Once every file was emitted it returns task.ErrNoData and Done() is closed. Files failing to be read are logged and
+// returned as errors once, they aren't read again.
+type FileReader[I task.Nullable, O *File] struct {
+	logger *slog.Logger
+	opts   *FileReaderOpts
+
+	mu      sync.Mutex
+	paths   []string
+	globbed bool
+	done    chan struct{}
+	closed  bool
+}
+
+// NewFileReader creates a FileReader, validating the patterns
+func NewFileReader[I task.Nullable, O *File](logger *slog.Logger, opts *FileReaderOpts) (*FileReader[I, O], error) {
+	if len(opts.Patterns) == 0 {
+		return nil, errors.New("file reader needs patterns")
+	}
+
+	for _, pattern := range opts.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	r := new(FileReader[I, O])
+
+	r.logger = logger
+	r.opts = opts
+	r.done = make(chan struct{})
+
+	return r, nil
+}
+
+// Done is closed once the last file was returned by Run (or when no file matches the patterns)
+func (r *FileReader[I, O]) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *FileReader[I, O]) Run(_ context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.globbed {
+		if err := r.glob(); err != nil {
+			r.logger.Error("error globbing files", "error", err, "patterns", r.opts.Patterns)
+			return nil, err
+		}
+	}
+
+	for len(r.paths) > 0 {
+		path := r.paths[0]
+		r.paths = r.paths[1:]
+
+		file, err := readFile(path, meta, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			r.logger.Warn("file removed before being read", "path", path)
+			continue
+		}
+
+		if err != nil {
+			r.logger.Error("error reading file", "error", err, "path", path)
+			return nil, err
+		}
+
+		r.logger.Debug("file read", "path", path, "size", file.Size)
+
+		if len(r.paths) == 0 {
+			r.finish()
+		}
+
+		return file, nil
+	}
+
+	r.finish()
+
+	return nil, task.ErrNoData
+}
+
+// finish closes done, once
+func (r *FileReader[I, O]) finish() {
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+}
+
+// glob lists files matching any pattern, skipping directories and hidden files
+func (r *FileReader[I, O]) glob() error {
+	for _, pattern := range r.opts.Patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || hidden(path) {
+				continue
+			}
+
+			r.paths = append(r.paths, path)
+		}
+	}
+
+	slices.Sort(r.paths)
+	r.paths = slices.Compact(r.paths)
+	r.globbed = true
+
+	r.logger.Info("files globbed", "patterns", r.opts.Patterns, "count", len(r.paths))
+
+	if len(r.paths) == 0 {
+		r.finish()
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/file"
+)
+
+// writeFiles creates files (relative path -> content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewFileReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *file.FileReaderOpts
+		wantErr bool
+	}{
+		{"It creates the reader", &file.FileReaderOpts{Patterns: []string{"*.json"}}, false},
+		{"It returns error without patterns", &file.FileReaderOpts{}, true},
+		{"It returns error for bad patterns", &file.FileReaderOpts{Patterns: []string{"[.json"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := file.NewFileReader(slog.Default(), tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("NewFileReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileReader_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"It reads every matching file sorted", []string{"*.json"}, []string{"a.json", "b.json"}},
+		{"It reads files of many patterns once", []string{"*.json", "a.*", "sub/*"}, []string{"a.json", "a.txt", "b.json", "sub/c.json"}},
+		{"It skips hidden files and directories", []string{"*"}, []string{"a.json", "a.txt", "b.json"}},
+		{"It emits nothing without matches", []string{"*.csv"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"b.json": "b.json", "a.json": "a.json", "a.txt": "a.txt", ".hidden.json": ".hidden.json", "sub/c.json": "sub/c.json"})
+
+			patterns := make([]string, len(tt.patterns))
+			for i, pattern := range tt.patterns {
+				patterns[i] = filepath.Join(dir, pattern)
+			}
+
+			r, err := file.NewFileReader(slog.New(slog.NewTextHandler(os.Stdout, nil)), &file.FileReaderOpts{Patterns: patterns})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+
+			for {
+				meta := map[string]interface{}{}
+
+				f, err := r.Run(context.TODO(), task.Nullable{}, meta, "reader")
+				if errors.Is(err, task.ErrNoData) {
+					break
+				}
+
+				if err != nil {
+					t.Fatalf("FileReader.Run() error = %v", err)
+				}
+
+				rel, _ := filepath.Rel(dir, f.Path)
+				if string(f.Data) != filepath.ToSlash(rel) || f.Size != int64(len(f.Data)) {
+					t.Errorf("FileReader.Run() = %+v, want content %s", f, rel)
+				}
+
+				if info, ok := meta["reader"].(*file.FileInfo); !ok || info.Path != f.Path {
+					t.Errorf("FileReader.Run() meta = %v, want info of %s", meta["reader"], f.Path)
+				}
+
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileReader.Run() files = %v, want %v", got, tt.want)
+			}
+
+			select {
+			case <-r.Done():
+			default:
+				t.Errorf("FileReader.Done() not closed after every file was emitted")
+			}
+		})
+	}
+}
+
+func TestFileReader_RunDone(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.json": "a", "b.json": "b", "c.json": "c"})
+
+	r, err := file.NewFileReader(slog.New(slog.NewTextHandler(os.Stdout, nil)), &file.FileReaderOpts{Patterns: []string{filepath.Join(dir, "*.json")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := func() bool {
+		select {
+		case <-r.Done():
+			return true
+		default:
+			return false
+		}
+	}
+
+	if _, err := r.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "reader"); err != nil || done() {
+		t.Fatalf("FileReader.Run() error = %v, done %v, want first file", err, done())
+	}
+
+	// c.json turns into a directory after being globbed, failing to be read
+	os.Remove(filepath.Join(dir, "c.json"))
+	os.Mkdir(filepath.Join(dir, "c.json"), 0o755)
+
+	if _, err := r.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "reader"); err != nil || done() {
+		t.Fatalf("FileReader.Run() error = %v, done %v, want second file before done", err, done())
+	}
+
+	if _, err := r.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "reader"); err == nil || done() {
+		t.Fatalf("FileReader.Run() error = %v, done %v, want read error before done", err, done())
+	}
+
+	if _, err := r.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "reader"); !errors.Is(err, task.ErrNoData) || !done() {
+		t.Errorf("FileReader.Run() error = %v, done %v, want %v and done", err, done(), task.ErrNoData)
+	}
+}
@@ -0,0 +1,185 @@
+package file
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const DefaultPollInterval = time.Second
+
+type FileWatcherOpts struct {
+	// Pattern filters file names (filepath.Match syntax on the base name, ex. "*.json"). Defaults to every file
+	Pattern string
+	// Recursive watches subdirectories too
+	Recursive bool
+	// PollInterval is the minimum interval between scans of the directory. Defaults to DefaultPollInterval
+	PollInterval time.Duration
+	// MinAge skips files modified in the last MinAge until they stop changing, so files still being written aren't emitted.
+	// Not needed when writers rename complete files into the directory (as FileWriter does)
+	MinAge time.Duration
+	// SkipExisting doesn't emit files already on the directory on the first scan
+	SkipExisting bool
+}
+
+// FileWatcher is a source task (to be used with ProducerWorker) which polls a directory for new files.
+// Every Run() returns one new file (oldest first), appending its FileInfo to metadata. Files are emitted again when their
+// modification time or size change. Hidden files (as temporary files of FileWriter) are skipped.
+// Emitted files are only kept in memory, so every file is emitted again after a restart unless SkipExisting is given.
+// When there is no file to emit it returns task.ErrNoData.
+type FileWatcher[I task.Nullable, O *File] struct {
+	dir    string
+	logger *slog.Logger
+	opts   *FileWatcherOpts
+
+	mu        sync.Mutex
+	buffer    []*FileInfo
+	seen      map[string]FileInfo
+	scanned   bool
+	scannedAt time.Time
+}
+
+// NewFileWatcher creates a FileWatcher on dir. opts is optional (nil)
+func NewFileWatcher[I task.Nullable, O *File](dir string, logger *slog.Logger, opts *FileWatcherOpts) (*FileWatcher[I, O], error) {
+	w := new(FileWatcher[I, O])
+
+	w.dir = dir
+	w.logger = logger
+	w.opts = opts
+	w.seen = map[string]FileInfo{}
+
+	if w.opts == nil {
+		w.opts = &FileWatcherOpts{}
+	}
+
+	if w.opts.Pattern != "" {
+		if _, err := filepath.Match(w.opts.Pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.opts.PollInterval == 0 {
+		w.opts.PollInterval = DefaultPollInterval
+	}
+
+	return w, nil
+}
+
+func (w *FileWatcher[I, O]) Run(_ context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buffer) == 0 && time.Since(w.scannedAt) >= w.opts.PollInterval {
+		if err := w.scan(); err != nil {
+			w.logger.Error("error scanning directory", "error", err, "dir", w.dir)
+			return nil, err
+		}
+	}
+
+	for len(w.buffer) > 0 {
+		info := w.buffer[0]
+		w.buffer = w.buffer[1:]
+
+		file, err := readFile(info.Path, meta, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			w.logger.Warn("file removed before being read", "path", info.Path)
+			continue
+		}
+
+		if err != nil {
+			// forgetting it, the next scan finds the file again and retries it
+			delete(w.seen, info.Path)
+
+			w.logger.Error("error reading file", "error", err, "path", info.Path)
+			return nil, err
+		}
+
+		w.logger.Debug("new file read", "path", info.Path, "size", file.Size)
+
+		return file, nil
+	}
+
+	return nil, task.ErrNoData
+}
+
+// scan buffers files not seen yet (or changed since seen), forgetting removed ones
+func (w *FileWatcher[I, O]) scan() error {
+	now := time.Now()
+	seen := make(map[string]FileInfo, len(w.seen))
+
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != w.dir && errors.Is(err, fs.ErrNotExist) {
+				// removed while scanning
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			if path != w.dir && (!w.opts.Recursive || hidden(path)) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if hidden(path) || !d.Type().IsRegular() {
+			return nil
+		}
+
+		if match, _ := filepath.Match(w.opts.Pattern, d.Name()); w.opts.Pattern != "" && !match {
+			return nil
+		}
+
+		stat, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		info := FileInfo{Path: path, Size: stat.Size(), ModTime: stat.ModTime()}
+
+		if now.Sub(info.ModTime) < w.opts.MinAge {
+			// still being written, compared again on the next scan
+			if prev, ok := w.seen[path]; ok {
+				seen[path] = prev
+			}
+
+			return nil
+		}
+
+		seen[path] = info
+
+		if prev, ok := w.seen[path]; (ok && prev.Size == info.Size && prev.ModTime.Equal(info.ModTime)) || (!w.scanned && w.opts.SkipExisting) {
+			return nil
+		}
+
+		w.buffer = append(w.buffer, &info)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(w.buffer, func(a, b *FileInfo) int {
+		return cmp.Or(a.ModTime.Compare(b.ModTime), cmp.Compare(a.Path, b.Path))
+	})
+
+	w.seen = seen
+	w.scanned = true
+	w.scannedAt = now
+
+	w.logger.Debug("directory scanned", "dir", w.dir, "files", len(seen), "new", len(w.buffer))
+
+	return nil
+}
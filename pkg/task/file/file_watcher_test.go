@@ -0,0 +1,167 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/file"
+)
+
+// watch runs the watcher until it returns ErrNoData, returning emitted files relative to dir
+func watch(t *testing.T, w *file.FileWatcher[task.Nullable, *file.File], dir string) []string {
+	t.Helper()
+
+	var got []string
+
+	for {
+		meta := map[string]interface{}{}
+
+		f, err := w.Run(context.TODO(), task.Nullable{}, meta, "watcher")
+		if errors.Is(err, task.ErrNoData) {
+			return got
+		}
+
+		if err != nil {
+			t.Fatalf("FileWatcher.Run() error = %v", err)
+		}
+
+		if info, ok := meta["watcher"].(*file.FileInfo); !ok || info.Path != f.Path {
+			t.Errorf("FileWatcher.Run() meta = %v, want info of %s", meta["watcher"], f.Path)
+		}
+
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+}
+
+// age sets the modification time of a file to d ago
+func age(t *testing.T, path string, d time.Duration) {
+	t.Helper()
+
+	modified := time.Now().Add(-d)
+
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileWatcher_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *file.FileWatcherOpts
+		want      []string
+		wantAfter []string
+	}{
+		{
+			"It emits existing files oldest first, then new and changed ones",
+			&file.FileWatcherOpts{PollInterval: time.Nanosecond},
+			[]string{"b.json", "a.json", "a.txt"},
+			[]string{"a.json", "c.json"},
+		},
+		{
+			"It filters names and skips existing files",
+			&file.FileWatcherOpts{PollInterval: time.Nanosecond, Pattern: "*.json", SkipExisting: true},
+			nil,
+			[]string{"a.json", "c.json"},
+		},
+		{
+			"It watches subdirectories",
+			&file.FileWatcherOpts{PollInterval: time.Nanosecond, Pattern: "*.json", Recursive: true},
+			[]string{"b.json", "a.json", "sub/d.json"},
+			[]string{"a.json", "c.json"},
+		},
+		{
+			"It waits files to stop changing",
+			&file.FileWatcherOpts{PollInterval: time.Nanosecond, MinAge: time.Minute},
+			[]string{"b.json", "a.json"},
+			[]string{"a.json", "c.json"},
+		},
+		{
+			"It doesn't scan before the poll interval",
+			&file.FileWatcherOpts{PollInterval: time.Hour},
+			[]string{"b.json", "a.json", "a.txt"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"a.json": "a", "b.json": "b", "a.txt": "a", ".file-tmp-c.json": "c", "sub/d.json": "d"})
+			age(t, filepath.Join(dir, "b.json"), 3*time.Hour)
+			age(t, filepath.Join(dir, "a.json"), 2*time.Hour)
+			age(t, filepath.Join(dir, "sub/d.json"), time.Hour)
+
+			w, err := file.NewFileWatcher(dir, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := watch(t, w, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileWatcher.Run() files = %v, want %v", got, tt.want)
+			}
+
+			// renamed as FileWriter does, a.json rewritten
+			os.Rename(filepath.Join(dir, ".file-tmp-c.json"), filepath.Join(dir, "c.json"))
+			writeFiles(t, dir, map[string]string{"a.json": "changed"})
+			age(t, filepath.Join(dir, "a.json"), 90*time.Minute)
+			age(t, filepath.Join(dir, "c.json"), 80*time.Minute)
+			os.Remove(filepath.Join(dir, "b.json"))
+
+			if got := watch(t, w, dir); !reflect.DeepEqual(got, tt.wantAfter) {
+				t.Errorf("FileWatcher.Run() files after changes = %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestFileWatcher_RunMissingDir(t *testing.T) {
+	w, err := file.NewFileWatcher(filepath.Join(t.TempDir(), "missing"), slog.Default(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "watcher"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("FileWatcher.Run() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestFileWatcher_RunRetriesFailedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.json": "a", "b.json": "b"})
+
+	path := filepath.Join(dir, "b.json")
+	info, _ := os.Stat(path)
+
+	w, err := file.NewFileWatcher(dir, slog.New(slog.NewTextHandler(os.Stdout, nil)), &file.FileWatcherOpts{PollInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err := w.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "watcher"); err != nil || f.Path != filepath.Join(dir, "a.json") {
+		t.Fatalf("FileWatcher.Run() = %v, %v, want a.json", f, err)
+	}
+
+	// b.json is buffered, a directory in its place fails the read
+	os.Remove(path)
+	os.Mkdir(path, 0o755)
+
+	if _, err := w.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "watcher"); err == nil {
+		t.Fatalf("FileWatcher.Run() error = nil, want read error")
+	}
+
+	// restored as it was, it must not look already seen
+	os.Remove(path)
+	writeFiles(t, dir, map[string]string{"b.json": "b"})
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+
+	if got := watch(t, w, dir); !reflect.DeepEqual(got, []string{"b.json"}) {
+		t.Errorf("FileWatcher.Run() after read error = %v, want [b.json]", got)
+	}
+}
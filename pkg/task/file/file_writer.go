@@ -0,0 +1,220 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	// tmpPrefix of files being written, renamed to their final name on rotation
+	tmpPrefix = ".file-tmp-"
+	// TimeFormat of the creation time on file names
+	TimeFormat = "20060102T150405.000000000"
+)
+
+type FileWriterOpts struct {
+	// Prefix of file names. Files are named Prefix + creation time (UTC, TimeFormat) + "-" + sequence + Extension
+	Prefix string
+	// Extension of file names (ex. ".jsonl")
+	Extension string
+	// Delimiter written after every input. Defaults to "\n", give an empty slice for none
+	Delimiter []byte
+	// MaxSize rotates the file once it reaches this many bytes, inputs are never split between files. Disabled when zero
+	MaxSize int64
+	// MaxAge rotates the file this long after it was created, even when no input arrives. Disabled when zero
+	MaxAge time.Duration
+	// Perm of written files. Defaults to 0644
+	Perm fs.FileMode
+}
+
+// FileWriter is a task which appends every input to a file of dir, rotating it by size and/or age.
+// Files are written under a hidden temporary name and renamed when rotated (or on Close), so readers
+// (as FileWatcher) never see partial files. The final path of the file is appended on metadata under worker name.
+// Temporary files left by a crash are kept on dir, starting with ".file-tmp-".
+type FileWriter[I []byte, O task.Nullable] struct {
+	dir    string
+	logger *slog.Logger
+	opts   *FileWriterOpts
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	path   string
+	size   int64
+	seq    int
+	timer  *time.Timer
+	closed bool
+}
+
+// NewFileWriter creates a FileWriter on dir, creating it when it doesn't exist. opts is optional (nil)
+func NewFileWriter[I []byte, O task.Nullable](dir string, logger *slog.Logger, opts *FileWriterOpts) (*FileWriter[I, O], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := new(FileWriter[I, O])
+
+	w.dir = dir
+	w.logger = logger
+	w.opts = opts
+
+	if w.opts == nil {
+		w.opts = &FileWriterOpts{}
+	}
+
+	if w.opts.Delimiter == nil {
+		w.opts.Delimiter = []byte("\n")
+	}
+
+	if w.opts.Perm == 0 {
+		w.opts.Perm = 0o644
+	}
+
+	return w, nil
+}
+
+func (w *FileWriter[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return O(task.Nullable{}), ErrWriterClosed
+	}
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			w.logger.Error("error creating file", "error", err, "dir", w.dir)
+			return O(task.Nullable{}), err
+		}
+	}
+
+	path := w.path
+
+	if err := w.write(input); err != nil {
+		w.logger.Error("error writing file", "error", err, "path", path)
+		return O(task.Nullable{}), err
+	}
+
+	if w.opts.MaxSize > 0 && w.size >= w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return O(task.Nullable{}), err
+		}
+	}
+
+	meta[name] = path
+
+	return O(task.Nullable{}), nil
+}
+
+func (w *FileWriter[I, O]) write(input []byte) error {
+	n, err := w.writer.Write(input)
+	w.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	n, err = w.writer.Write(w.opts.Delimiter)
+	w.size += int64(n)
+
+	return err
+}
+
+// Rotate renames the current file to its final name, the next input starts a new file
+func (w *FileWriter[I, O]) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rotate()
+}
+
+// Close rotates the current file, inputs given after it return ErrWriterClosed
+func (w *FileWriter[I, O]) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+
+	return w.rotate()
+}
+
+// open creates a temporary file, named after the final one
+func (w *FileWriter[I, O]) open() error {
+	base := fmt.Sprintf("%s%s-%06d%s", w.opts.Prefix, time.Now().UTC().Format(TimeFormat), w.seq, w.opts.Extension)
+	path := filepath.Join(w.dir, base)
+
+	file, err := os.OpenFile(filepath.Join(w.dir, tmpPrefix+base), os.O_CREATE|os.O_EXCL|os.O_WRONLY, w.opts.Perm)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.writer = bufio.NewWriter(file)
+	w.path = path
+	w.size = 0
+	w.seq++
+
+	if w.opts.MaxAge > 0 {
+		w.timer = time.AfterFunc(w.opts.MaxAge, func() { w.expire(file) })
+	}
+
+	return nil
+}
+
+// expire rotates file when its MaxAge is reached, unless it was already rotated
+func (w *FileWriter[I, O]) expire(file *os.File) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != file {
+		return
+	}
+
+	// errors are logged by rotate, the next input starts a new file anyway
+	w.rotate()
+}
+
+// rotate flushes, syncs and renames the current file to its final name
+func (w *FileWriter[I, O]) rotate() error {
+	if w.file == nil {
+		return nil
+	}
+
+	file, writer, path := w.file, w.writer, w.path
+	w.file, w.writer = nil, nil
+
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	err := writer.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		w.logger.Error("error rotating file", "error", err, "path", path)
+		return err
+	}
+
+	w.logger.Info("file rotated", "path", path, "size", w.size)
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task/file"
+)
+
+// readDir returns contents of files on dir, sorted by name, and names of hidden ones
+func readDir(t *testing.T, dir string) ([]string, []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var contents, hidden []string
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			hidden = append(hidden, entry.Name())
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contents = append(contents, string(data))
+	}
+
+	return contents, hidden
+}
+
+func TestFileWriter_Run(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *file.FileWriterOpts
+		inputs []string
+		want   []string
+	}{
+		{"It writes inputs delimited by new lines", nil, []string{"a", "b", "c"}, []string{"a\nb\nc\n"}},
+		{"It writes inputs with a custom delimiter", &file.FileWriterOpts{Delimiter: []byte{}}, []string{"a", "b"}, []string{"ab"}},
+		{"It rotates files by size", &file.FileWriterOpts{MaxSize: 4}, []string{"a", "b", "c", "long", "d"}, []string{"a\nb\n", "c\nlong\n", "d\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			w, err := file.NewFileWriter(filepath.Join(dir, "out"), slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var paths []string
+
+			for _, input := range tt.inputs {
+				meta := map[string]interface{}{}
+
+				if _, err := w.Run(context.TODO(), []byte(input), meta, "writer"); err != nil {
+					t.Fatalf("FileWriter.Run() error = %v", err)
+				}
+
+				path, _ := meta["writer"].(string)
+				paths = append(paths, path)
+			}
+
+			if contents, _ := readDir(t, filepath.Join(dir, "out")); len(contents) != len(tt.want)-1 {
+				t.Errorf("FileWriter.Run() files before Close = %v, want only %d rotated", contents, len(tt.want)-1)
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("FileWriter.Close() error = %v", err)
+			}
+
+			contents, hidden := readDir(t, filepath.Join(dir, "out"))
+			if !reflect.DeepEqual(contents, tt.want) || len(hidden) > 0 {
+				t.Errorf("FileWriter.Run() files = %q (hidden %v), want %q", contents, hidden, tt.want)
+			}
+
+			for _, path := range slices.Compact(paths) {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("FileWriter.Run() metadata path %s error = %v", path, err)
+				}
+			}
+
+			if _, err := w.Run(context.TODO(), []byte("late"), map[string]interface{}{}, "writer"); !errors.Is(err, file.ErrWriterClosed) {
+				t.Errorf("FileWriter.Run() after Close error = %v, want %v", err, file.ErrWriterClosed)
+			}
+		})
+	}
+}
+
+func TestFileWriter_RunMaxAge(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := file.NewFileWriter(dir, slog.Default(), &file.FileWriterOpts{Prefix: "events-", Extension: ".jsonl", MaxAge: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer w.Close()
+
+	if _, err := w.Run(context.TODO(), []byte("a"), map[string]interface{}{}, "writer"); err != nil {
+		t.Fatalf("FileWriter.Run() error = %v", err)
+	}
+
+	if contents, hidden := readDir(t, dir); len(contents) != 0 || len(hidden) != 1 {
+		t.Fatalf("FileWriter.Run() files = %v (hidden %v), want only a temporary file", contents, hidden)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		contents, hidden := readDir(t, dir)
+		if len(contents) == 1 && len(hidden) == 0 {
+			if contents[0] != "a\n" {
+				t.Errorf("FileWriter rotated file = %q, want %q", contents[0], "a\n")
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("FileWriter didn't rotate after MaxAge, files %v (hidden %v)", contents, hidden)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "events-*-000000.jsonl"))
+	if len(matches) != 1 {
+		t.Errorf("FileWriter rotated file name doesn't follow Prefix/Extension, matches %v", matches)
+	}
+}
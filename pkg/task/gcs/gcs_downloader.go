@@ -0,0 +1,124 @@
+package gcs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+// GCSDownloader is a generic task capable of download a object from Google Cloud Storage based on a given path and bucket name
+// it will receive the bucket name on the constructor function NewGCSDownloader and the path is the input of Run()
+type GCSDownloader[I string, O *GCSDownloaderOutput] struct {
+	// GCS client to be used
+	client *storage.Client
+	// Bucket name where all objects will be downloaded
+	bucketName string
+	logger     *slog.Logger
+	opts       *GCSDownloaderOpts
+}
+
+type GCSDownloaderOpts struct {
+	// Offset where the download starts, negative values are relative to the end of the object (ex. -1024 for the last KB)
+	Offset int64
+	// Length downloaded from Offset. Defaults to the rest of the object
+	Length int64
+	// ReadCompressed downloads objects stored with Content-Encoding gzip as they are, instead of decompressing them
+	// (GCS decompressive transcoding)
+	ReadCompressed bool
+	// GenerationMetaKey when given, an int64 on metadata under it downloads that generation of the object instead of the live one
+	GenerationMetaKey string
+	// DetectCompression fills CompressionType from ContentEncoding/ContentType, also appending it on metadata under worker name
+	// to be read by a Decompressor with compression.METADATA_TYPE
+	DetectCompression bool
+}
+
+type GCSDownloaderOutput struct {
+	Data            []byte
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+	// Size of the whole object, Data may be smaller when downloading a range
+	Size         int64
+	StartOffset  int64
+	Generation   int64
+	LastModified time.Time
+	// Decompressed is true when GCS decompressed the object (Content-Encoding gzip) while downloading it
+	Decompressed bool
+	// CompressionType detected when DetectCompression is enabled ("" when not compressed or unknown)
+	CompressionType string
+}
+
+// NewGCSDownloader creates a GCSDownloader. opts is optional (nil)
+func NewGCSDownloader[I string, O *GCSDownloaderOutput](client *storage.Client, bucketName string, logger *slog.Logger, opts *GCSDownloaderOpts) *GCSDownloader[I, O] {
+	d := new(GCSDownloader[I, O])
+
+	d.client = client
+	d.bucketName = bucketName
+	d.logger = logger
+	d.opts = opts
+
+	if d.opts == nil {
+		d.opts = &GCSDownloaderOpts{}
+	}
+
+	return d
+}
+
+// The return from Run() will be a GCSDownloaderOutput (containing object as []data and its attributes)
+// When DetectCompression is enabled, the detected compression type is added to metadata under worker name.
+func (d *GCSDownloader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	obj := d.client.Bucket(d.bucketName).Object(string(input)).ReadCompressed(d.opts.ReadCompressed)
+
+	if generation, ok := meta[d.opts.GenerationMetaKey].(int64); ok && d.opts.GenerationMetaKey != "" {
+		obj = obj.Generation(generation)
+	}
+
+	length := d.opts.Length
+	if length == 0 {
+		length = -1
+	}
+
+	reader, err := obj.NewRangeReader(ctx, d.opts.Offset, length)
+	if err != nil {
+		d.logger.Error("error downloading object", "error", err, "path", input)
+
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		d.logger.Error("error reading downloaded object", "error", err, "path", input)
+
+		return nil, err
+	}
+
+	d.logger.Debug("object downloaded successfully", "path", input)
+
+	out := &GCSDownloaderOutput{
+		Data:            body,
+		ContentType:     reader.Attrs.ContentType,
+		ContentEncoding: reader.Attrs.ContentEncoding,
+		CacheControl:    reader.Attrs.CacheControl,
+		Size:            reader.Attrs.Size,
+		StartOffset:     reader.Attrs.StartOffset,
+		Generation:      reader.Attrs.Generation,
+		LastModified:    reader.Attrs.LastModified,
+		Decompressed:    reader.Attrs.Decompressed,
+	}
+
+	if d.opts.DetectCompression && !out.Decompressed {
+		out.CompressionType = compression.DetectHeaders(out.ContentEncoding, out.ContentType)
+
+		if out.CompressionType != "" {
+			meta[name] = out.CompressionType
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package gcs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/fsouza/fake-gcs-server/fakestorage"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+	"github.com/otaviohenrique/vecna/pkg/task/gcs"
+)
+
+// newServer starts an in-memory GCS server with the given objects
+func newServer(t *testing.T, objects ...fakestorage.Object) *fakestorage.Server {
+	t.Helper()
+
+	srv, err := fakestorage.NewServerWithOptions(fakestorage.Options{InitialObjects: objects, NoListener: true})
+	if err != nil {
+		t.Fatalf("fakestorage.NewServerWithOptions() error = %v", err)
+	}
+	t.Cleanup(srv.Stop)
+
+	srv.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "any-bucket"})
+
+	return srv
+}
+
+func object(name string, content string, contentType string, contentEncoding string) fakestorage.Object {
+	return fakestorage.Object{
+		ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "any-bucket", Name: name, ContentType: contentType, ContentEncoding: contentEncoding},
+		Content:     []byte(content),
+	}
+}
+
+func TestGCSDownloader_Run(t *testing.T) {
+	tests := []struct {
+		name                string
+		opts                *gcs.GCSDownloaderOpts
+		path                string
+		wantData            string
+		wantContentType     string
+		wantCompressionType string
+		wantErr             error
+	}{
+		{"It downloads objects", nil, "path/to/obj", "any-data", "application/json", "", nil},
+		{"It downloads ranges", &gcs.GCSDownloaderOpts{Offset: 4, Length: 4}, "path/to/obj", "data", "application/json", "", nil},
+		{"It downloads the end of objects", &gcs.GCSDownloaderOpts{Offset: -4}, "path/to/obj", "data", "application/json", "", nil},
+		{"It detects compression", &gcs.GCSDownloaderOpts{DetectCompression: true}, "path/to/obj.zst", "compressed", "application/zstd", compression.ZSTD_TYPE, nil},
+		{"It returns not found objects error", nil, "path/to/missing", "", "", "", storage.ErrObjectNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t,
+				object("path/to/obj", "any-data", "application/json", ""),
+				object("path/to/obj.zst", "compressed", "application/zstd", ""),
+			)
+
+			d := gcs.NewGCSDownloader(srv.Client(), "any-bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			meta := map[string]interface{}{}
+
+			got, err := d.Run(context.TODO(), tt.path, meta, "download")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GCSDownloader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if string(got.Data) != tt.wantData || got.ContentType != tt.wantContentType || got.CompressionType != tt.wantCompressionType {
+				t.Errorf("GCSDownloader.Run() = %s %s %s, want %s %s %s", got.Data, got.ContentType, got.CompressionType, tt.wantData, tt.wantContentType, tt.wantCompressionType)
+			}
+
+			if got.Size != int64(len("any-data")) && tt.wantCompressionType == "" {
+				t.Errorf("GCSDownloader.Run() size = %d, want %d", got.Size, len("any-data"))
+			}
+
+			if tt.wantCompressionType != "" && meta["download"] != tt.wantCompressionType {
+				t.Errorf("GCSDownloader.Run() meta = %v, want compression %s", meta, tt.wantCompressionType)
+			}
+		})
+	}
+}
+
+func TestGCSDownloader_RunGeneration(t *testing.T) {
+	srv := newServer(t, object("path/to/obj", "any-data", "application/json", ""))
+
+	d := gcs.NewGCSDownloader(srv.Client(), "any-bucket", slog.Default(), &gcs.GCSDownloaderOpts{GenerationMetaKey: "generation"})
+
+	got, err := d.Run(context.TODO(), "path/to/obj", map[string]interface{}{}, "download")
+	if err != nil {
+		t.Fatalf("GCSDownloader.Run() error = %v", err)
+	}
+
+	if _, err := d.Run(context.TODO(), "path/to/obj", map[string]interface{}{"generation": got.Generation}, "download"); err != nil {
+		t.Errorf("GCSDownloader.Run() with generation %d error = %v", got.Generation, err)
+	}
+
+	if _, err := d.Run(context.TODO(), "path/to/obj", map[string]interface{}{"generation": got.Generation + 1}, "download"); !errors.Is(err, storage.ErrObjectNotExist) {
+		t.Errorf("GCSDownloader.Run() with unknown generation error = %v, want %v", err, storage.ErrObjectNotExist)
+	}
+}
@@ -0,0 +1,140 @@
+package gcs
+
+import (
+	"context"
+	"log/slog"
+	"text/template"
+
+	"cloud.google.com/go/storage"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/upload"
+)
+
+var (
+	ErrMissingKey = upload.ErrMissingKey
+	ErrEmptyKey   = upload.ErrEmptyKey
+)
+
+// GCSUploader is a task which will upload a given data on the given path (object name) of one bucket
+type GCSUploader[I *GCSUploaderInput, O task.Nullable] struct {
+	// GCS client to be used
+	client *storage.Client
+	// Bucket name where all objects will be stored (unless overridden by input)
+	bucketName  string
+	logger      *slog.Logger
+	opts        *GCSUploaderOpts
+	keyTemplate *upload.KeyTemplate
+}
+
+// GCSUploaderOpts are the defaults applied to every uploaded object. Fields set on GCSUploaderInput override them.
+type GCSUploaderOpts struct {
+	// KeyTemplate renders the object name from WorkerData metadata when input Path is empty.
+	// Ex. template.Must(template.New("key").Parse("dt={{.date}}/{{.uuid}}.json.gz"))
+	// Missing metadata keys return an error.
+	KeyTemplate *template.Template
+	// ContentType of uploaded objects (ex. application/json)
+	ContentType string
+	// ContentEncoding of uploaded objects (ex. gzip)
+	ContentEncoding string
+	// CacheControl of uploaded objects (ex. no-cache)
+	CacheControl string
+	// Metadata is user defined metadata
+	Metadata map[string]string
+	// StorageClass of uploaded objects (ex. NEARLINE)
+	StorageClass string
+	// KMSKeyName encrypts uploaded objects with a customer managed key
+	KMSKeyName string
+	// IfNotExists only uploads objects which don't exist yet, a precondition error is returned otherwise
+	IfNotExists bool
+	// ChunkSize of resumable uploads, objects smaller than it are uploaded in a single request.
+	// Defaults to the client default (16MB)
+	ChunkSize int
+}
+
+// GCSUploaderInput is a envelope containing all the information needed to upload object to GCS
+// Should be returned by adaptFn
+type GCSUploaderInput struct {
+	// Path (object name) to upload the object. When empty it is rendered from KeyTemplate
+	Path string
+	// Bytes to be uploaded
+	Content []byte
+	// Bucket overrides the bucket given on constructor
+	Bucket string
+	// ContentType overrides GCSUploaderOpts.ContentType
+	ContentType string
+	// ContentEncoding overrides GCSUploaderOpts.ContentEncoding
+	ContentEncoding string
+	// Metadata is merged with GCSUploaderOpts.Metadata (input wins)
+	Metadata map[string]string
+	// StorageClass overrides GCSUploaderOpts.StorageClass
+	StorageClass string
+}
+
+// NewGCSUploader creates a GCSUploader. opts is optional (nil)
+func NewGCSUploader[I *GCSUploaderInput, O task.Nullable](client *storage.Client, bucketName string, logger *slog.Logger, opts *GCSUploaderOpts) *GCSUploader[I, O] {
+	u := new(GCSUploader[I, O])
+
+	u.client = client
+	u.bucketName = bucketName
+	u.logger = logger
+	u.opts = opts
+
+	if u.opts == nil {
+		u.opts = &GCSUploaderOpts{}
+	}
+
+	u.keyTemplate = upload.NewKeyTemplate(u.opts.KeyTemplate)
+
+	return u
+}
+
+// Run() will be called by worker and should return a pointer to TaskData.
+// It doesn't merge nothing on metadata given and only return errors if any
+func (u *GCSUploader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	err := u.uploadObject(ctx, input, meta)
+
+	return O(task.Nullable{}), err
+}
+
+func (u *GCSUploader[I, O]) uploadObject(ctx context.Context, input *GCSUploaderInput, meta map[string]interface{}) error {
+	key, err := u.keyTemplate.Key(input.Path, meta)
+	if err != nil {
+		u.logger.Error("error building object upload", "error", err, "path", input.Path)
+		return err
+	}
+
+	obj := u.client.Bucket(upload.Override(input.Bucket, u.bucketName)).Object(key)
+	if u.opts.IfNotExists {
+		obj = obj.If(storage.Conditions{DoesNotExist: true})
+	}
+
+	// the upload is aborted when ctx is canceled before Close
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := obj.NewWriter(ctx)
+	w.ContentType = upload.Override(input.ContentType, u.opts.ContentType)
+	w.ContentEncoding = upload.Override(input.ContentEncoding, u.opts.ContentEncoding)
+	w.CacheControl = u.opts.CacheControl
+	w.Metadata = upload.Merge(u.opts.Metadata, input.Metadata)
+	w.StorageClass = upload.Override(input.StorageClass, u.opts.StorageClass)
+	w.KMSKeyName = u.opts.KMSKeyName
+
+	if u.opts.ChunkSize > 0 {
+		w.ChunkSize = u.opts.ChunkSize
+	}
+
+	if _, err := w.Write(input.Content); err != nil {
+		u.logger.Error("error uploading object", "error", err, "path", key)
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		u.logger.Error("error uploading object", "error", err, "path", key)
+		return err
+	}
+
+	u.logger.Debug("object uploaded successfully", "path", key)
+
+	return nil
+}
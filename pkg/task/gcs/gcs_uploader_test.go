@@ -0,0 +1,120 @@
+package gcs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/otaviohenrique/vecna/pkg/task/gcs"
+)
+
+func TestGCSUploader_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            *gcs.GCSUploaderOpts
+		input           *gcs.GCSUploaderInput
+		meta            map[string]interface{}
+		wantPath        string
+		wantContentType string
+		wantMetadata    map[string]string
+		wantErr         bool
+	}{
+		{
+			"It uploads objects",
+			nil,
+			&gcs.GCSUploaderInput{Path: "path/to/obj", Content: []byte("any-data")},
+			map[string]interface{}{},
+			"path/to/obj", "", nil, false,
+		},
+		{
+			"It applies defaults and input overrides",
+			&gcs.GCSUploaderOpts{ContentType: "text/plain", Metadata: map[string]string{"team": "data", "source": "vecna"}},
+			&gcs.GCSUploaderInput{Path: "path/to/obj", Content: []byte("any-data"), ContentType: "application/json", Metadata: map[string]string{"source": "orders"}},
+			map[string]interface{}{},
+			"path/to/obj", "application/json", map[string]string{"team": "data", "source": "orders"}, false,
+		},
+		{
+			"It renders the path from metadata",
+			&gcs.GCSUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("dt={{.date}}/{{.id}}.json"))},
+			&gcs.GCSUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{"date": "2024-01-01", "id": "1"},
+			"dt=2024-01-01/1.json", "", nil, false,
+		},
+		{
+			"It returns error when metadata misses template keys",
+			&gcs.GCSUploaderOpts{KeyTemplate: template.Must(template.New("key").Parse("dt={{.date}}/{{.id}}.json"))},
+			&gcs.GCSUploaderInput{Content: []byte("any-data")},
+			map[string]interface{}{"date": "2024-01-01"},
+			"", "", nil, true,
+		},
+		{
+			"It returns error uploading to unknown buckets",
+			nil,
+			&gcs.GCSUploaderInput{Path: "path/to/obj", Content: []byte("any-data"), Bucket: "unknown-bucket"},
+			map[string]interface{}{},
+			"", "", nil, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+
+			u := gcs.NewGCSUploader(srv.Client(), "any-bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			_, err := u.Run(context.TODO(), tt.input, tt.meta, "upload")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GCSUploader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			obj, err := srv.GetObject("any-bucket", tt.wantPath)
+			if err != nil {
+				t.Fatalf("GetObject(%s) error = %v", tt.wantPath, err)
+			}
+
+			if string(obj.Content) != "any-data" || (tt.wantContentType != "" && obj.ContentType != tt.wantContentType) || len(obj.Metadata) != len(tt.wantMetadata) {
+				t.Errorf("GCSUploader.Run() uploaded %s %s %v, want any-data %s %v", obj.Content, obj.ContentType, obj.Metadata, tt.wantContentType, tt.wantMetadata)
+			}
+
+			for k, v := range tt.wantMetadata {
+				if obj.Metadata[k] != v {
+					t.Errorf("GCSUploader.Run() metadata %s = %s, want %s", k, obj.Metadata[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestGCSUploader_RunIfNotExists(t *testing.T) {
+	srv := newServer(t, object("path/to/obj", "old-data", "", ""))
+
+	u := gcs.NewGCSUploader(srv.Client(), "any-bucket", slog.Default(), &gcs.GCSUploaderOpts{IfNotExists: true})
+
+	if _, err := u.Run(context.TODO(), &gcs.GCSUploaderInput{Path: "path/to/obj", Content: []byte("new-data")}, map[string]interface{}{}, "upload"); err == nil {
+		t.Errorf("GCSUploader.Run() error = nil, want precondition error")
+	}
+
+	if _, err := u.Run(context.TODO(), &gcs.GCSUploaderInput{Path: "path/to/new", Content: []byte("new-data")}, map[string]interface{}{}, "upload"); err != nil {
+		t.Errorf("GCSUploader.Run() error = %v", err)
+	}
+
+	obj, _ := srv.GetObject("any-bucket", "path/to/obj")
+	if string(obj.Content) != "old-data" {
+		t.Errorf("GCSUploader.Run() overwrote object with %s", obj.Content)
+	}
+
+}
+
+func TestGCSUploader_RunWithoutKey(t *testing.T) {
+	u := gcs.NewGCSUploader(newServer(t).Client(), "any-bucket", slog.Default(), nil)
+
+	if _, err := u.Run(context.TODO(), &gcs.GCSUploaderInput{Content: []byte("any-data")}, map[string]interface{}{}, "upload"); !errors.Is(err, gcs.ErrMissingKey) {
+		t.Errorf("GCSUploader.Run() error = %v, want %v", err, gcs.ErrMissingKey)
+	}
+}
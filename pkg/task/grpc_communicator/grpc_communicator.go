@@ -0,0 +1,106 @@
+package grpccommunicator
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Invoker calls one unary method, usually a generated client method value (ex. client.GetUser)
+type Invoker[Req any, Resp any] func(ctx context.Context, req Req, opts ...grpc.CallOption) (Resp, error)
+
+// RequestBuilder builds the request message from task input and metadata
+type RequestBuilder[I any, Req any] func(input I, meta map[string]interface{}) (Req, error)
+
+// CallInfo is appended on metadata under worker name with the response metadata of the call
+type CallInfo struct {
+	Code    codes.Code
+	Header  metadata.MD
+	Trailer metadata.MD
+}
+
+type GRPCUnaryOpts struct {
+	// Timeout of every call. No timeout besides ctx when zero
+	Timeout time.Duration
+	// Metadata sent on every call
+	Metadata metadata.MD
+	// MetadataFrom builds per message metadata (ex. forwarding a request id), its keys replace the same keys of Metadata
+	MetadataFrom func(meta map[string]interface{}) metadata.MD
+	// CallOptions given to every call (ex. grpc.WaitForReady(true))
+	CallOptions []grpc.CallOption
+}
+
+// GRPCUnary is a task which calls a unary gRPC method for every input.
+// Request is built by builder and the call is made by invoker, keeping this task independent of generated code.
+// Response header, trailer and status code are appended on metadata as *CallInfo.
+type GRPCUnary[I any, Req any, O any] struct {
+	invoke Invoker[Req, O]
+	build  RequestBuilder[I, Req]
+	logger *slog.Logger
+	opts   *GRPCUnaryOpts
+}
+
+// NewGRPCUnary creates a GRPCUnary. opts is optional (nil)
+func NewGRPCUnary[I any, Req any, O any](invoke Invoker[Req, O], build RequestBuilder[I, Req], logger *slog.Logger, opts *GRPCUnaryOpts) *GRPCUnary[I, Req, O] {
+	gu := new(GRPCUnary[I, Req, O])
+
+	gu.invoke = invoke
+	gu.build = build
+	gu.logger = logger
+	gu.opts = opts
+
+	if gu.opts == nil {
+		gu.opts = &GRPCUnaryOpts{}
+	}
+
+	return gu
+}
+
+func (gu *GRPCUnary[I, Req, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	var resp O
+
+	req, err := gu.build(input, meta)
+	if err != nil {
+		gu.logger.Error("error building grpc request", "error", err)
+		return resp, err
+	}
+
+	md := gu.opts.Metadata.Copy()
+	if gu.opts.MetadataFrom != nil {
+		// keys given per message replace the fixed ones instead of being sent twice
+		for k, v := range gu.opts.MetadataFrom(meta) {
+			md.Set(k, v...)
+		}
+	}
+
+	if len(md) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	if gu.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, gu.opts.Timeout)
+		defer cancel()
+	}
+
+	info := &CallInfo{}
+	callOpts := append([]grpc.CallOption{grpc.Header(&info.Header), grpc.Trailer(&info.Trailer)}, gu.opts.CallOptions...)
+
+	resp, err = gu.invoke(ctx, req, callOpts...)
+
+	info.Code = status.Code(err)
+	meta[name] = info
+
+	if err != nil {
+		gu.logger.Error("error calling grpc method", "error", err, "code", info.Code)
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,164 @@
+package grpccommunicator_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	grpccommunicator "github.com/otaviohenrique/vecna/pkg/task/grpc_communicator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// echoMetadata sends back x-request-id and every x-tenant value as header and the served method as trailer
+func echoMetadata(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	grpc.SetHeader(ctx, metadata.MD{"x-request-id": md.Get("x-request-id"), "x-tenant": md.Get("x-tenant")})
+	grpc.SetTrailer(ctx, metadata.Pairs("x-method", info.FullMethod))
+
+	return handler(ctx, req)
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func newHealthClient(t *testing.T) healthpb.HealthClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(echoMetadata))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func buildCheck(service string, meta map[string]interface{}) (*healthpb.HealthCheckRequest, error) {
+	if service == "" {
+		return nil, errors.New("empty service")
+	}
+
+	return &healthpb.HealthCheckRequest{Service: service}, nil
+}
+
+func TestGRPCUnary_Run(t *testing.T) {
+	client := newHealthClient(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name       string
+		input      string
+		opts       *grpccommunicator.GRPCUnaryOpts
+		wantStatus healthpb.HealthCheckResponse_ServingStatus
+		wantCode   codes.Code
+		wantErr    bool
+		wantInfo   bool
+	}{
+		{"It calls the method", "users", nil, healthpb.HealthCheckResponse_SERVING, codes.OK, false, true},
+		{"It returns status errors", "orders", nil, healthpb.HealthCheckResponse_UNKNOWN, codes.NotFound, true, true},
+		{"It returns builder errors", "", nil, healthpb.HealthCheckResponse_UNKNOWN, codes.Unknown, true, false},
+		{"It sends metadata", "users", &grpccommunicator.GRPCUnaryOpts{
+			Metadata: metadata.Pairs("x-tenant", "acme"),
+			MetadataFrom: func(meta map[string]interface{}) metadata.MD {
+				return metadata.Pairs("x-request-id", meta["request_id"].(string))
+			},
+			Timeout: time.Second,
+		}, healthpb.HealthCheckResponse_SERVING, codes.OK, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gu := grpccommunicator.NewGRPCUnary(client.Check, buildCheck, logger, tt.opts)
+
+			meta := map[string]interface{}{"request_id": "req-1"}
+
+			resp, err := gu.Run(context.TODO(), tt.input, meta, "grpc")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GRPCUnary.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := resp.GetStatus(); got != tt.wantStatus {
+				t.Errorf("GRPCUnary.Run() status = %v, want %v", got, tt.wantStatus)
+			}
+
+			if err != nil && status.Code(err) != tt.wantCode {
+				t.Errorf("GRPCUnary.Run() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+
+			info, ok := meta["grpc"].(*grpccommunicator.CallInfo)
+			if ok != tt.wantInfo {
+				t.Fatalf("GRPCUnary.Run() metadata = %v, want CallInfo %v", meta["grpc"], tt.wantInfo)
+			}
+
+			if !ok {
+				return
+			}
+
+			if info.Code != tt.wantCode {
+				t.Errorf("CallInfo.Code = %v, want %v", info.Code, tt.wantCode)
+			}
+
+			if got := firstValue(info.Trailer, "x-method"); got != healthpb.Health_Check_FullMethodName {
+				t.Errorf("CallInfo.Trailer x-method = %s, want %s", got, healthpb.Health_Check_FullMethodName)
+			}
+
+			if tt.opts != nil {
+				if got := firstValue(info.Header, "x-request-id"); got != "req-1" {
+					t.Errorf("CallInfo.Header x-request-id = %s, want req-1", got)
+				}
+
+				if got := firstValue(info.Header, "x-tenant"); got != "acme" {
+					t.Errorf("CallInfo.Header x-tenant = %s, want acme", got)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCUnary_RunMetadataFromReplacesKeys(t *testing.T) {
+	gu := grpccommunicator.NewGRPCUnary(newHealthClient(t).Check, buildCheck, slog.New(slog.NewTextHandler(os.Stdout, nil)), &grpccommunicator.GRPCUnaryOpts{
+		Metadata: metadata.Pairs("x-tenant", "acme"),
+		MetadataFrom: func(meta map[string]interface{}) metadata.MD {
+			return metadata.Pairs("x-tenant", meta["tenant"].(string))
+		},
+	})
+
+	meta := map[string]interface{}{"tenant": "globex"}
+
+	if _, err := gu.Run(context.TODO(), "users", meta, "grpc"); err != nil {
+		t.Fatalf("GRPCUnary.Run() error = %v", err)
+	}
+
+	if got := meta["grpc"].(*grpccommunicator.CallInfo).Header.Get("x-tenant"); !slices.Equal(got, []string{"globex"}) {
+		t.Errorf("GRPCUnary.Run() sent x-tenant = %v, want [globex]", got)
+	}
+}
@@ -0,0 +1,56 @@
+package httpcommunicator_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httpcommunicator "github.com/otaviohenrique/vecna/pkg/task/http_communicator"
+)
+
+// onlyReader hides bytes.Reader type, so http.NewRequest can't set GetBody
+type onlyReader struct{ io.Reader }
+
+func TestAuthenticators(t *testing.T) {
+	now := func() time.Time { return time.Unix(1700000000, 0) }
+	secret := []byte("secret")
+	wantSignature := "sha256=" + httpcommunicator.Sign(secret, "1700000000", []byte("payload"))
+
+	tests := []struct {
+		name   string
+		auth   httpcommunicator.Authenticator
+		body   io.Reader
+		header string
+		want   string
+	}{
+		{"It sets bearer token", &httpcommunicator.BearerAuth{Token: "abc"}, nil, "Authorization", "Bearer abc"},
+		{"It sets basic auth", &httpcommunicator.BasicAuth{Username: "user", Password: "pass"}, nil, "Authorization", "Basic dXNlcjpwYXNz"},
+		{"It signs body", &httpcommunicator.HMACAuth{Secret: secret, Now: now}, bytes.NewReader([]byte("payload")), httpcommunicator.DefaultSignatureHeader, wantSignature},
+		{"It signs streamed body", &httpcommunicator.HMACAuth{Secret: secret, Now: now}, onlyReader{strings.NewReader("payload")}, httpcommunicator.DefaultSignatureHeader, wantSignature},
+		{"It sends signing timestamp", &httpcommunicator.HMACAuth{Secret: secret, Now: now}, nil, httpcommunicator.DefaultTimestampHeader, "1700000000"},
+		{"It uses custom signature header", &httpcommunicator.HMACAuth{Secret: secret, Now: now, SignatureHeader: "X-Hub-Signature-256"},
+			strings.NewReader("payload"), "X-Hub-Signature-256", wantSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost", tt.body)
+
+			if err := tt.auth.Authenticate(req); err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+
+			if got := req.Header.Get(tt.header); got != tt.want {
+				t.Errorf("Authenticate() %s = %v, want %v", tt.header, got, tt.want)
+			}
+
+			if tt.body != nil {
+				if body, _ := io.ReadAll(req.Body); string(body) != "payload" {
+					t.Errorf("Authenticate() left body = %s, want payload", body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package httpcommunicator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// DefaultMaxBodySize is used by HTTPBytesCommunicator and HTTPJSONCommunicator when HTTPCommunicatorOpts.MaxBodySize is zero
+const DefaultMaxBodySize = 10 << 20
+
+// ErrBodyTooLarge is returned when the response body is bigger than HTTPCommunicatorOpts.MaxBodySize
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ResponseInfo is appended on metadata under worker name by HTTPBytesCommunicator and HTTPJSONCommunicator
+type ResponseInfo struct {
+	Status     string
+	StatusCode int
+	Header     http.Header
+}
+
+// HTTPBytesCommunicator performs requests like HTTPCommunicator, but reads and closes the response body,
+// returning it as []byte. Status and headers are appended on metadata as *ResponseInfo.
+type HTTPBytesCommunicator[I RequestOpts, O []byte] struct {
+	client *http.Client
+	logger *slog.Logger
+	opts   *HTTPCommunicatorOpts
+}
+
+// NewHTTPBytesCommunicator creates a HTTPBytesCommunicator. opts is optional (nil)
+func NewHTTPBytesCommunicator[I RequestOpts, O []byte](client *http.Client, logger *slog.Logger, opts *HTTPCommunicatorOpts) *HTTPBytesCommunicator[I, O] {
+	hc := new(HTTPBytesCommunicator[I, O])
+
+	hc.client = client
+	hc.logger = logger
+	hc.opts = defaultOpts(opts)
+
+	return hc
+}
+
+func (hc *HTTPBytesCommunicator[I, O]) Run(ctx context.Context, i I, meta map[string]interface{}, name string) (O, error) {
+	req := RequestOpts(i)
+
+	body, err := doAndRead(ctx, hc.client, hc.opts, req, meta, name)
+	if err != nil {
+		hc.logger.Error("error doing request", "error", err, "method", req.Method, "url", req.URL)
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// HTTPJSONCommunicator performs requests like HTTPCommunicator, but reads and closes the response body,
+// returning it decoded from JSON into O. Empty bodies (ex. 204) return O zero value.
+// Status and headers are appended on metadata as *ResponseInfo.
+type HTTPJSONCommunicator[I RequestOpts, O any] struct {
+	client *http.Client
+	logger *slog.Logger
+	opts   *HTTPCommunicatorOpts
+}
+
+// NewHTTPJSONCommunicator creates a HTTPJSONCommunicator. opts is optional (nil)
+func NewHTTPJSONCommunicator[I RequestOpts, O any](client *http.Client, logger *slog.Logger, opts *HTTPCommunicatorOpts) *HTTPJSONCommunicator[I, O] {
+	hc := new(HTTPJSONCommunicator[I, O])
+
+	hc.client = client
+	hc.logger = logger
+	hc.opts = defaultOpts(opts)
+
+	return hc
+}
+
+func (hc *HTTPJSONCommunicator[I, O]) Run(ctx context.Context, i I, meta map[string]interface{}, name string) (O, error) {
+	var target O
+
+	req := RequestOpts(i)
+
+	body, err := doAndRead(ctx, hc.client, hc.opts, req, meta, name)
+	if err != nil {
+		hc.logger.Error("error doing request", "error", err, "method", req.Method, "url", req.URL)
+		return target, err
+	}
+
+	if len(body) == 0 {
+		return target, nil
+	}
+
+	if err := json.Unmarshal(body, &target); err != nil {
+		hc.logger.Error("error decoding response", "error", err, "method", req.Method, "url", req.URL)
+
+		var nullTarget O
+
+		return nullTarget, err
+	}
+
+	return target, nil
+}
+
+// doAndRead performs the request and reads the whole body (up to MaxBodySize), always closing it
+func doAndRead(ctx context.Context, client *http.Client, opts *HTTPCommunicatorOpts, ro RequestOpts, meta map[string]interface{}, name string) ([]byte, error) {
+	resp, err := do(ctx, client, opts, ro)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	meta[name] = &ResponseInfo{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+
+	limit := opts.MaxBodySize
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes", ErrBodyTooLarge, resp.ContentLength)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, limit)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,165 @@
+package httpcommunicator_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpcommunicator "github.com/otaviohenrique/vecna/pkg/task/http_communicator"
+)
+
+// closeTracker wraps every response body recording if it was closed
+type closeTracker struct {
+	bodies []*trackedBody
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+
+	return b.ReadCloser.Close()
+}
+
+func (c *closeTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &trackedBody{ReadCloser: resp.Body}
+	c.bodies = append(c.bodies, body)
+	resp.Body = body
+
+	return resp, nil
+}
+
+func (c *closeTracker) allClosed() bool {
+	for _, b := range c.bodies {
+		if !b.closed {
+			return false
+		}
+	}
+
+	return len(c.bodies) > 0
+}
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func decodingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":1,"name":"vecna"}`)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/invalid":
+			io.WriteString(w, `{"id":`)
+		case "/big":
+			io.WriteString(w, strings.Repeat("a", 2048))
+		case "/chunked":
+			for i := 0; i < 4; i++ {
+				io.WriteString(w, strings.Repeat("a", 512))
+				w.(http.Flusher).Flush()
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestHTTPJSONCommunicator_Run(t *testing.T) {
+	server := decodingServer()
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    *httpcommunicator.HTTPCommunicatorOpts
+		want    *item
+		wantErr bool
+	}{
+		{"It decodes JSON body", "/item", nil, &item{ID: 1, Name: "vecna"}, false},
+		{"It returns zero value on empty body", "/empty", nil, nil, false},
+		{"It returns error on invalid JSON", "/invalid", nil, nil, true},
+		{"It returns error on big body", "/big", &httpcommunicator.HTTPCommunicatorOpts{MaxBodySize: 1024}, nil, true},
+		{"It returns error on rejected status", "/missing", &httpcommunicator.HTTPCommunicatorOpts{StatusPolicy: httpcommunicator.Accept2xx}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &closeTracker{}
+			hc := httpcommunicator.NewHTTPJSONCommunicator[httpcommunicator.RequestOpts, *item](
+				&http.Client{Transport: tracker}, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			got, err := hc.Run(context.TODO(), httpcommunicator.RequestOpts{Method: http.MethodGet, URL: server.URL + tt.path}, map[string]interface{}{}, "http")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HTTPJSONCommunicator.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HTTPJSONCommunicator.Run() = %+v, want %+v", got, tt.want)
+			}
+
+			if !tracker.allClosed() {
+				t.Errorf("HTTPJSONCommunicator.Run() left response body open")
+			}
+		})
+	}
+}
+
+func TestHTTPBytesCommunicator_Run(t *testing.T) {
+	server := decodingServer()
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    *httpcommunicator.HTTPCommunicatorOpts
+		want    []byte
+		wantErr error
+	}{
+		{"It returns body", "/item", nil, []byte(`{"id":1,"name":"vecna"}`), nil},
+		{"It returns error on big body", "/big", &httpcommunicator.HTTPCommunicatorOpts{MaxBodySize: 1024}, nil, httpcommunicator.ErrBodyTooLarge},
+		{"It returns error on big chunked body", "/chunked", &httpcommunicator.HTTPCommunicatorOpts{MaxBodySize: 1024}, nil, httpcommunicator.ErrBodyTooLarge},
+		{"It accepts body within limit", "/chunked", &httpcommunicator.HTTPCommunicatorOpts{MaxBodySize: 2048}, []byte(strings.Repeat("a", 2048)), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &closeTracker{}
+			hc := httpcommunicator.NewHTTPBytesCommunicator(&http.Client{Transport: tracker}, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			meta := map[string]interface{}{}
+
+			got, err := hc.Run(context.TODO(), httpcommunicator.RequestOpts{Method: http.MethodGet, URL: server.URL + tt.path}, meta, "http")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HTTPBytesCommunicator.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HTTPBytesCommunicator.Run() = %s, want %s", got, tt.want)
+			}
+
+			if info := meta["http"].(*httpcommunicator.ResponseInfo); info.StatusCode != http.StatusOK {
+				t.Errorf("HTTPBytesCommunicator.Run() metadata = %+v", info)
+			}
+
+			if !tracker.allClosed() {
+				t.Errorf("HTTPBytesCommunicator.Run() left response body open")
+			}
+		})
+	}
+}
@@ -2,25 +2,32 @@ package httpcommunicator
 
 import (
 	"context"
-	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // RequestOpts contains all information needed to make the request
 type RequestOpts struct {
-	// Only accept POST, GET, HEAD, POSTFORM
+	// Any HTTP method (GET, POST, PUT, PATCH, DELETE...). POSTFORM sends UrlValues as a form with POST
 	Method      string
 	URL         string
 	ContentType string
 	Body        io.Reader
 	UrlValues   url.Values
+	// Header is merged over HTTPCommunicatorOpts.Header
+	Header http.Header
+	// Query params added to URL query
+	Query url.Values
+	// Auth overrides HTTPCommunicatorOpts.Auth for this request
+	Auth Authenticator
 }
 
 // Response from Request.
-// Call Close() on Body is next worker responsability
+// Call Close() on Body is next worker responsability, if it may fail prefer HTTPBytesCommunicator or HTTPJSONCommunicator
+// which read and close the body themselves
 type RequestResponse struct {
 	Status     string
 	StatusCode int
@@ -28,42 +35,44 @@ type RequestResponse struct {
 	Header     http.Header
 }
 
+// HTTPCommunicatorOpts are applied to every request
+type HTTPCommunicatorOpts struct {
+	// Header sent on every request
+	Header http.Header
+	// Auth authenticates every request (see BearerAuth, BasicAuth and HMACAuth)
+	Auth Authenticator
+	// StatusPolicy decides which statuses are errors (*StatusError). Defaults to AcceptAll
+	StatusPolicy StatusPolicy
+	// MaxBodySize is the maximum response body read by HTTPBytesCommunicator and HTTPJSONCommunicator.
+	// Defaults to DefaultMaxBodySize
+	MaxBodySize int64
+}
+
 // HTTPCommunicator client performs HTTP requests based on RequestOpts returned by adaptFn
 type HTTPCommunicator[I RequestOpts, O *RequestResponse] struct {
 	// HTTP client to be used to perform requests
 	client *http.Client
 	logger *slog.Logger
+	opts   *HTTPCommunicatorOpts
 }
 
-func NewHTTPCommunicator[I RequestOpts, O *RequestResponse](client *http.Client, logger *slog.Logger) *HTTPCommunicator[I, O] {
+// NewHTTPCommunicator creates a HTTPCommunicator. opts is optional (nil)
+func NewHTTPCommunicator[I RequestOpts, O *RequestResponse](client *http.Client, logger *slog.Logger, opts *HTTPCommunicatorOpts) *HTTPCommunicator[I, O] {
 	hc := new(HTTPCommunicator[I, O])
 
 	hc.client = client
 	hc.logger = logger
+	hc.opts = defaultOpts(opts)
 
 	return hc
 }
 
-func (hc *HTTPCommunicator[I, O]) Run(_ context.Context, i I, ctx map[string]interface{}, _ string) (O, error) {
+func (hc *HTTPCommunicator[I, O]) Run(ctx context.Context, i I, meta map[string]interface{}, _ string) (O, error) {
 	req := RequestOpts(i)
 
-	var resp *http.Response
-	var err error
-
-	switch req.Method {
-	case "POST":
-		resp, err = hc.client.Post(req.URL, req.ContentType, req.Body)
-	case "GET":
-		resp, err = hc.client.Get(req.URL)
-	case "HEAD":
-		resp, err = hc.client.Head(req.URL)
-	case "POSTFORM":
-		resp, err = hc.client.PostForm(req.URL, req.UrlValues)
-	default:
-		return nil, errors.New("unknown request type given to HTTPCommunicator")
-	}
-
+	resp, err := do(ctx, hc.client, hc.opts, req)
 	if err != nil {
+		hc.logger.Error("error doing request", "error", err, "method", req.Method, "url", req.URL)
 		return nil, err
 	}
 
@@ -74,3 +83,96 @@ func (hc *HTTPCommunicator[I, O]) Run(_ context.Context, i I, ctx map[string]int
 		Header:     resp.Header,
 	}, nil
 }
+
+// defaultOpts returns a copy of opts with defaults filled, the caller opts are kept untouched
+func defaultOpts(opts *HTTPCommunicatorOpts) *HTTPCommunicatorOpts {
+	o := &HTTPCommunicatorOpts{}
+
+	if opts != nil {
+		*o = *opts
+	}
+
+	if o.StatusPolicy == nil {
+		o.StatusPolicy = AcceptAll
+	}
+
+	return o
+}
+
+// newRequest builds the request from RequestOpts, applying opts headers and auth
+func newRequest(ctx context.Context, opts *HTTPCommunicatorOpts, ro RequestOpts) (*http.Request, error) {
+	method := strings.ToUpper(ro.Method)
+	body := ro.Body
+	contentType := ro.ContentType
+
+	if method == "POSTFORM" {
+		method = http.MethodPost
+		body = strings.NewReader(ro.UrlValues.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, ro.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ro.Query) > 0 {
+		query := req.URL.Query()
+		for k, values := range ro.Query {
+			for _, v := range values {
+				query.Add(k, v)
+			}
+		}
+
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for _, header := range []http.Header{opts.Header, ro.Header} {
+		for k, values := range header {
+			req.Header.Del(k)
+
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	auth := opts.Auth
+	if ro.Auth != nil {
+		auth = ro.Auth
+	}
+
+	if auth != nil {
+		if err := auth.Authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// do performs the request, checking the response status against the policy.
+// On success the caller must close the response body
+func do(ctx context.Context, client *http.Client, opts *HTTPCommunicatorOpts, ro RequestOpts) (*http.Response, error) {
+	req, err := newRequest(ctx, opts, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.StatusPolicy(resp.StatusCode) {
+		defer resp.Body.Close()
+
+		return nil, newStatusError(resp)
+	}
+
+	return resp, nil
+}
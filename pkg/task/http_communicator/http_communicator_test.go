@@ -3,12 +3,17 @@ package httpcommunicator_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
 	httpcommunicator "github.com/otaviohenrique/vecna/pkg/task/http_communicator"
@@ -67,6 +72,7 @@ func TestHTTPCommunicator_Run(t *testing.T) {
 			hc := httpcommunicator.NewHTTPCommunicator(
 				tt.fields.client,
 				tt.fields.logger,
+				nil,
 			)
 
 			tt.args.i.URL = server.URL
@@ -98,3 +104,117 @@ func TestHTTPCommunicator_Run(t *testing.T) {
 		})
 	}
 }
+
+// echoServer answers with the request method, query, headers and body as JSON and with the status from "status" query
+func echoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if status := r.URL.Query().Get("status"); status != "" {
+			code, _ := strconv.Atoi(status)
+			w.WriteHeader(code)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method": r.Method,
+			"query":  r.URL.RawQuery,
+			"header": r.Header,
+			"body":   string(body),
+		})
+	}))
+}
+
+type echo struct {
+	Method string      `json:"method"`
+	Query  string      `json:"query"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+func TestHTTPCommunicator_RunRequestOpts(t *testing.T) {
+	server := echoServer()
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		opts       *httpcommunicator.HTTPCommunicatorOpts
+		req        httpcommunicator.RequestOpts
+		check      func(e echo) bool
+		wantStatus int
+	}{
+		{"It sends any method with body", nil,
+			httpcommunicator.RequestOpts{Method: http.MethodPatch, Body: strings.NewReader("patch-body"), ContentType: "text/plain"},
+			func(e echo) bool {
+				return e.Method == "PATCH" && e.Body == "patch-body" && e.Header.Get("Content-Type") == "text/plain"
+			}, 0},
+		{"It sends DELETE without body", nil, httpcommunicator.RequestOpts{Method: http.MethodDelete},
+			func(e echo) bool { return e.Method == "DELETE" && e.Body == "" }, 0},
+		{"It merges query params", nil,
+			httpcommunicator.RequestOpts{Method: http.MethodGet, URL: "/path?a=1", Query: url.Values{"b": {"2"}}},
+			func(e echo) bool { return e.Query == "a=1&b=2" }, 0},
+		{"It merges request headers over default headers",
+			&httpcommunicator.HTTPCommunicatorOpts{Header: http.Header{"X-Default": {"d"}, "X-Override": {"default"}}},
+			httpcommunicator.RequestOpts{Method: http.MethodPut, Header: http.Header{"X-Override": {"request"}}},
+			func(e echo) bool {
+				return e.Header.Get("X-Default") == "d" && e.Header.Get("X-Override") == "request"
+			}, 0},
+		{"It posts forms", nil,
+			httpcommunicator.RequestOpts{Method: "POSTFORM", UrlValues: url.Values{"field": {"value"}}},
+			func(e echo) bool {
+				return e.Method == "POST" && e.Body == "field=value" && e.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+			}, 0},
+		{"It authenticates requests", &httpcommunicator.HTTPCommunicatorOpts{Auth: &httpcommunicator.BearerAuth{Token: "token"}},
+			httpcommunicator.RequestOpts{Method: http.MethodGet},
+			func(e echo) bool { return e.Header.Get("Authorization") == "Bearer token" }, 0},
+		{"It lets request auth override default auth", &httpcommunicator.HTTPCommunicatorOpts{Auth: &httpcommunicator.BearerAuth{Token: "token"}},
+			httpcommunicator.RequestOpts{Method: http.MethodGet, Auth: &httpcommunicator.BasicAuth{Username: "user", Password: "pass"}},
+			func(e echo) bool { return strings.HasPrefix(e.Header.Get("Authorization"), "Basic ") }, 0},
+		{"It accepts any status by default", nil, httpcommunicator.RequestOpts{Method: http.MethodGet, URL: "/?status=500"},
+			func(e echo) bool { return true }, 0},
+		{"It returns StatusError when policy rejects status", &httpcommunicator.HTTPCommunicatorOpts{StatusPolicy: httpcommunicator.Accept2xx},
+			httpcommunicator.RequestOpts{Method: http.MethodGet, URL: "/?status=404"}, nil, 404},
+		{"It accepts chosen statuses", &httpcommunicator.HTTPCommunicatorOpts{StatusPolicy: httpcommunicator.AcceptStatus(200, 404)},
+			httpcommunicator.RequestOpts{Method: http.MethodGet, URL: "/?status=404"},
+			func(e echo) bool { return true }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := httpcommunicator.NewHTTPCommunicator(server.Client(), slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			tt.req.URL = server.URL + tt.req.URL
+
+			got, err := hc.Run(context.TODO(), tt.req, map[string]interface{}{}, "http")
+
+			var statusErr *httpcommunicator.StatusError
+			if tt.wantStatus != 0 {
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus || len(statusErr.Body) == 0 {
+					t.Fatalf("HTTPCommunicator.Run() error = %v, want StatusError %d", err, tt.wantStatus)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("HTTPCommunicator.Run() error = %v", err)
+			}
+			defer got.Body.Close()
+
+			var e echo
+			json.NewDecoder(got.Body).Decode(&e)
+
+			if !tt.check(e) {
+				t.Errorf("HTTPCommunicator.Run() sent %+v", e)
+			}
+		})
+	}
+}
+
+func TestNewHTTPCommunicator_KeepsCallerOpts(t *testing.T) {
+	opts := &httpcommunicator.HTTPCommunicatorOpts{}
+
+	httpcommunicator.NewHTTPCommunicator(http.DefaultClient, slog.New(slog.NewTextHandler(os.Stdout, nil)), opts)
+
+	if opts.StatusPolicy != nil {
+		t.Errorf("NewHTTPCommunicator() filled StatusPolicy on caller opts")
+	}
+}
@@ -0,0 +1,57 @@
+package httpcommunicator
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// maxErrorBody is how much of a rejected response body is kept on StatusError
+const maxErrorBody = 4096
+
+// StatusPolicy returns true when a response status is accepted, otherwise the request returns *StatusError
+type StatusPolicy func(statusCode int) bool
+
+var (
+	// AcceptAll accepts every status, leaving the check to the next worker
+	AcceptAll StatusPolicy = func(int) bool { return true }
+	// Accept2xx turns every non-2xx status into *StatusError
+	Accept2xx StatusPolicy = func(statusCode int) bool { return statusCode >= 200 && statusCode < 300 }
+)
+
+// AcceptStatus accepts only the given statuses
+func AcceptStatus(statusCodes ...int) StatusPolicy {
+	return func(statusCode int) bool {
+		return slices.Contains(statusCodes, statusCode)
+	}
+}
+
+// StatusError is returned when the response status is rejected by StatusPolicy
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	// Body contains the first bytes of the response body (up to 4 KiB)
+	Body []byte
+}
+
+func newStatusError(resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       body,
+	}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %s", e.Status)
+}
+
+// Temporary reports whether the request may succeed if retried (5xx, 408 and 429)
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
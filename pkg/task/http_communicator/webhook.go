@@ -0,0 +1,252 @@
+package httpcommunicator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	// WebhookIDHeader carries an id kept across retries of the same delivery, allowing receivers to deduplicate
+	WebhookIDHeader = "X-Webhook-Id"
+	// WebhookAttemptHeader carries the attempt number (starting at 1)
+	WebhookAttemptHeader = "X-Webhook-Attempt"
+
+	DefaultWebhookMaxAttempts    = 5
+	DefaultWebhookInitialBackoff = 500 * time.Millisecond
+	DefaultWebhookMaxBackoff     = 30 * time.Second
+)
+
+var (
+	// ErrWebhookRejected is returned when the receiver answers 4xx (except 408 and 429), the delivery isn't retried
+	ErrWebhookRejected = errors.New("webhook rejected by receiver")
+	// ErrWebhookFailed is returned when every attempt failed with network errors, 5xx, 408 or 429
+	ErrWebhookFailed = errors.New("webhook delivery failed")
+	// ErrWebhookRetryAfter is returned when the receiver asks (Retry-After) to wait longer than MaxBackoff
+	ErrWebhookRetryAfter = errors.New("webhook Retry-After exceeds MaxBackoff")
+)
+
+// WebhookRequest is one delivery, returned by adaptFn
+type WebhookRequest struct {
+	URL string
+	// Payload is serialized to JSON
+	Payload any
+	// Header is merged over WebhookSenderOpts.Header
+	Header http.Header
+	// Secret overrides WebhookSenderOpts.Secret (ex. one secret per customer)
+	Secret []byte
+}
+
+// WebhookAttempt is the result of one delivery attempt
+type WebhookAttempt struct {
+	StatusCode int
+	Error      string
+	// Wait is how long the sender waited before the next attempt
+	Wait time.Duration
+}
+
+// WebhookDelivery is appended on metadata under worker name with every attempt made
+type WebhookDelivery struct {
+	ID        string
+	Delivered bool
+	Attempts  []WebhookAttempt
+}
+
+type WebhookSenderOpts struct {
+	// Secret signs every payload with HMAC-SHA256 (see HMACAuth). No signature when empty
+	Secret []byte
+	// SignatureHeader defaults to DefaultSignatureHeader
+	SignatureHeader string
+	// TimestampHeader defaults to DefaultTimestampHeader
+	TimestampHeader string
+	// Header sent on every delivery
+	Header http.Header
+	// MaxAttempts defaults to DefaultWebhookMaxAttempts
+	MaxAttempts int
+	// InitialBackoff is doubled after every failed attempt. Defaults to DefaultWebhookInitialBackoff
+	InitialBackoff time.Duration
+	// MaxBackoff caps backoff, a longer Retry-After fails the delivery with ErrWebhookRetryAfter.
+	// Defaults to DefaultWebhookMaxBackoff
+	MaxBackoff time.Duration
+}
+
+// WebhookSender is a task which delivers JSON payloads to webhooks, signing and retrying them.
+// Network errors, 5xx, 408 and 429 are retried with exponential backoff (Retry-After is honored),
+// other 4xx fail at once with ErrWebhookRejected.
+type WebhookSender[I *WebhookRequest, O task.Nullable] struct {
+	client *http.Client
+	logger *slog.Logger
+	opts   *WebhookSenderOpts
+}
+
+// NewWebhookSender creates a WebhookSender. opts is optional (nil)
+func NewWebhookSender[I *WebhookRequest, O task.Nullable](client *http.Client, logger *slog.Logger, opts *WebhookSenderOpts) *WebhookSender[I, O] {
+	ws := new(WebhookSender[I, O])
+
+	ws.client = client
+	ws.logger = logger
+	ws.opts = &WebhookSenderOpts{}
+
+	if opts != nil {
+		*ws.opts = *opts
+	}
+
+	if ws.opts.MaxAttempts == 0 {
+		ws.opts.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+
+	if ws.opts.InitialBackoff == 0 {
+		ws.opts.InitialBackoff = DefaultWebhookInitialBackoff
+	}
+
+	if ws.opts.MaxBackoff == 0 {
+		ws.opts.MaxBackoff = DefaultWebhookMaxBackoff
+	}
+
+	return ws
+}
+
+func (ws *WebhookSender[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	req := (*WebhookRequest)(input)
+
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return O(task.Nullable{}), err
+	}
+
+	delivery := &WebhookDelivery{ID: newDeliveryID()}
+	meta[name] = delivery
+
+	err = ws.deliver(ctx, req, payload, delivery)
+	if err != nil {
+		ws.logger.Error("error delivering webhook", "error", err, "url", req.URL, "id", delivery.ID, "attempts", len(delivery.Attempts))
+	} else {
+		ws.logger.Debug("webhook delivered", "url", req.URL, "id", delivery.ID, "attempts", len(delivery.Attempts))
+	}
+
+	return O(task.Nullable{}), err
+}
+
+func (ws *WebhookSender[I, O]) deliver(ctx context.Context, req *WebhookRequest, payload []byte, delivery *WebhookDelivery) error {
+	opts := &HTTPCommunicatorOpts{Header: ws.opts.Header, StatusPolicy: Accept2xx}
+
+	secret := req.Secret
+	if secret == nil {
+		secret = ws.opts.Secret
+	}
+
+	if len(secret) > 0 {
+		opts.Auth = &HMACAuth{Secret: secret, SignatureHeader: ws.opts.SignatureHeader, TimestampHeader: ws.opts.TimestampHeader}
+	}
+
+	backoff := ws.opts.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		header := req.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		header.Set(WebhookIDHeader, delivery.ID)
+		header.Set(WebhookAttemptHeader, strconv.Itoa(attempt))
+
+		resp, err := do(ctx, ws.client, opts, RequestOpts{
+			Method:      http.MethodPost,
+			URL:         req.URL,
+			ContentType: "application/json",
+			Body:        bytes.NewReader(payload),
+			Header:      header,
+		})
+
+		result := WebhookAttempt{}
+
+		if err == nil {
+			resp.Body.Close()
+
+			result.StatusCode = resp.StatusCode
+			delivery.Attempts = append(delivery.Attempts, result)
+			delivery.Delivered = true
+
+			return nil
+		}
+
+		result.Error = err.Error()
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			result.StatusCode = statusErr.StatusCode
+
+			if !statusErr.Temporary() {
+				delivery.Attempts = append(delivery.Attempts, result)
+
+				return fmt.Errorf("%w: %w", ErrWebhookRejected, err)
+			}
+		}
+
+		if attempt >= ws.opts.MaxAttempts || ctx.Err() != nil {
+			delivery.Attempts = append(delivery.Attempts, result)
+
+			return fmt.Errorf("%w after %d attempts: %w", ErrWebhookFailed, attempt, err)
+		}
+
+		result.Wait = min(backoff, ws.opts.MaxBackoff)
+		if statusErr != nil {
+			if retryAfter, ok := parseRetryAfter(statusErr.Header.Get("Retry-After")); ok {
+				if retryAfter > ws.opts.MaxBackoff {
+					delivery.Attempts = append(delivery.Attempts, result)
+
+					return fmt.Errorf("%w: %v after %d attempts: %w", ErrWebhookRetryAfter, retryAfter, attempt, err)
+				}
+
+				result.Wait = retryAfter
+			}
+		}
+
+		delivery.Attempts = append(delivery.Attempts, result)
+		backoff *= 2
+
+		timer := time.NewTimer(result.Wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("%w after %d attempts: %w", ErrWebhookFailed, attempt, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter parses Retry-After as seconds or HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,129 @@
+package httpcommunicator_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	httpcommunicator "github.com/otaviohenrique/vecna/pkg/task/http_communicator"
+)
+
+// webhookServer answers each attempt with the next status of statuses (last one repeats)
+type webhookServer struct {
+	statuses   []int
+	retryAfter string
+	mu         sync.Mutex
+	requests   []*http.Request
+	bodies     []string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, string(body))
+
+	status := s.statuses[min(len(s.requests), len(s.statuses))-1]
+	if status == http.StatusTooManyRequests && s.retryAfter != "" {
+		w.Header().Set("Retry-After", s.retryAfter)
+	}
+
+	w.WriteHeader(status)
+}
+
+func TestWebhookSender_Run(t *testing.T) {
+	secret := []byte("webhook-secret")
+
+	tests := []struct {
+		name         string
+		server       *webhookServer
+		wantErr      error
+		wantAttempts int
+		wantWait     time.Duration
+	}{
+		{"It delivers at first attempt", &webhookServer{statuses: []int{200}}, nil, 1, 0},
+		{"It retries server errors", &webhookServer{statuses: []int{503, 500, 204}}, nil, 3, 0},
+		{"It doesn't retry client errors", &webhookServer{statuses: []int{400}}, httpcommunicator.ErrWebhookRejected, 1, 0},
+		{"It gives up after max attempts", &webhookServer{statuses: []int{502}}, httpcommunicator.ErrWebhookFailed, 3, 0},
+		{"It honors Retry-After", &webhookServer{statuses: []int{429, 200}, retryAfter: "1"}, nil, 2, time.Second},
+		{"It fails when Retry-After exceeds MaxBackoff", &webhookServer{statuses: []int{429, 200}, retryAfter: "10"}, httpcommunicator.ErrWebhookRetryAfter, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.server)
+			defer server.Close()
+
+			ws := httpcommunicator.NewWebhookSender(server.Client(), slog.New(slog.NewTextHandler(os.Stdout, nil)), &httpcommunicator.WebhookSenderOpts{
+				Secret:         secret,
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+			})
+
+			meta := map[string]interface{}{}
+
+			_, err := ws.Run(context.TODO(), &httpcommunicator.WebhookRequest{URL: server.URL, Payload: map[string]int{"id": 1}}, meta, "webhook")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WebhookSender.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			delivery := meta["webhook"].(*httpcommunicator.WebhookDelivery)
+			if len(delivery.Attempts) != tt.wantAttempts || len(tt.server.requests) != tt.wantAttempts || delivery.Delivered != (tt.wantErr == nil) {
+				t.Fatalf("WebhookSender.Run() delivery = %+v, requests %d", delivery, len(tt.server.requests))
+			}
+
+			if tt.wantWait != 0 && delivery.Attempts[0].Wait != tt.wantWait {
+				t.Errorf("WebhookSender.Run() waited %v, want %v", delivery.Attempts[0].Wait, tt.wantWait)
+			}
+
+			for i, r := range tt.server.requests {
+				timestamp := r.Header.Get(httpcommunicator.DefaultTimestampHeader)
+				wantSignature := "sha256=" + httpcommunicator.Sign(secret, timestamp, []byte(tt.server.bodies[i]))
+
+				if tt.server.bodies[i] != `{"id":1}` || r.Header.Get(httpcommunicator.DefaultSignatureHeader) != wantSignature {
+					t.Errorf("attempt %d body = %s, signature = %s", i, tt.server.bodies[i], r.Header.Get(httpcommunicator.DefaultSignatureHeader))
+				}
+
+				if r.Header.Get(httpcommunicator.WebhookIDHeader) != delivery.ID || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("attempt %d headers = %v", i, r.Header)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookSender_RunContextCancel(t *testing.T) {
+	server := httptest.NewServer(&webhookServer{statuses: []int{503}})
+	defer server.Close()
+
+	ws := httpcommunicator.NewWebhookSender(server.Client(), slog.New(slog.NewTextHandler(os.Stdout, nil)), &httpcommunicator.WebhookSenderOpts{
+		InitialBackoff: time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := ws.Run(ctx, &httpcommunicator.WebhookRequest{URL: server.URL, Payload: "data"}, map[string]interface{}{}, "webhook")
+	if !errors.Is(err, httpcommunicator.ErrWebhookFailed) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WebhookSender.Run() error = %v, want ErrWebhookFailed and deadline exceeded", err)
+	}
+}
+
+func TestNewWebhookSender_KeepsCallerOpts(t *testing.T) {
+	opts := &httpcommunicator.WebhookSenderOpts{}
+
+	httpcommunicator.NewWebhookSender(http.DefaultClient, slog.New(slog.NewTextHandler(os.Stdout, nil)), opts)
+
+	if opts.MaxAttempts != 0 || opts.InitialBackoff != 0 || opts.MaxBackoff != 0 {
+		t.Errorf("NewWebhookSender() changed caller opts to %+v", opts)
+	}
+}
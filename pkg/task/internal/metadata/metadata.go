@@ -0,0 +1,26 @@
+// Package metadata converts values read from worker metadata, shared by the messaging tasks
+package metadata
+
+// Bytes converts string and []byte metadata values
+func Bytes(v interface{}) ([]byte, bool) {
+	switch value := v.(type) {
+	case string:
+		return []byte(value), true
+	case []byte:
+		return value, true
+	default:
+		return nil, false
+	}
+}
+
+// String converts string and []byte metadata values
+func String(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case []byte:
+		return string(value), true
+	default:
+		return "", false
+	}
+}
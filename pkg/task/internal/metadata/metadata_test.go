@@ -0,0 +1,32 @@
+package metadata_test
+
+import (
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+)
+
+func TestBytesAndString(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   string
+		wantOk bool
+	}{
+		{"It converts strings", "value", "value", true},
+		{"It converts bytes", []byte("value"), "value", true},
+		{"It refuses other types", 10, "", false},
+		{"It refuses missing values", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := metadata.Bytes(tt.value); string(got) != tt.want || ok != tt.wantOk {
+				t.Errorf("Bytes() = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOk)
+			}
+
+			if got, ok := metadata.String(tt.value); got != tt.want || ok != tt.wantOk {
+				t.Errorf("String() = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package upload renders object keys and merges object attributes, shared by the object storage uploaders
+package upload
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+)
+
+var (
+	ErrMissingKey = errors.New("no Path given and no KeyTemplate configured")
+	ErrEmptyKey   = errors.New("KeyTemplate rendered an empty key")
+)
+
+// KeyTemplate renders object keys from worker metadata, failing on missing metadata keys
+type KeyTemplate struct {
+	tmpl *template.Template
+}
+
+// NewKeyTemplate clones t, so setting missingkey=error doesn't change the caller template. A nil t returns a
+// KeyTemplate which only accepts given paths
+func NewKeyTemplate(t *template.Template) *KeyTemplate {
+	k := new(KeyTemplate)
+
+	if t != nil {
+		k.tmpl = template.Must(t.Clone())
+		k.tmpl.Option("missingkey=error")
+	}
+
+	return k
+}
+
+// Key returns path, or renders the template with meta when path is empty, rejecting empty keys
+func (k *KeyTemplate) Key(path string, meta map[string]interface{}) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	if k.tmpl == nil {
+		return "", ErrMissingKey
+	}
+
+	var b strings.Builder
+
+	if err := k.tmpl.Execute(&b, meta); err != nil {
+		return "", err
+	}
+
+	if b.Len() == 0 {
+		return "", ErrEmptyKey
+	}
+
+	return b.String(), nil
+}
+
+// Override returns value, or fallback when value is empty
+func Override(value string, fallback string) string {
+	if value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// Merge returns base with override keys over it, nil when both are empty
+func Merge(base map[string]string, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(base)+len(override))
+
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range override {
+		merged[k] = v
+	}
+
+	return merged
+}
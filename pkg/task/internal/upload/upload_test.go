@@ -0,0 +1,58 @@
+package upload_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/otaviohenrique/vecna/pkg/task/internal/upload"
+)
+
+func TestKeyTemplate_Key(t *testing.T) {
+	tmpl := template.Must(template.New("key").Parse("{{.prefix}}/{{.id}}"))
+
+	tests := []struct {
+		name    string
+		tmpl    *template.Template
+		path    string
+		meta    map[string]interface{}
+		want    string
+		wantErr error
+	}{
+		{"It returns the given path", tmpl, "given/path", nil, "given/path", nil},
+		{"It renders the template", tmpl, "", map[string]interface{}{"prefix": "dt=2024", "id": 1}, "dt=2024/1", nil},
+		{"It fails on missing metadata keys", tmpl, "", map[string]interface{}{"prefix": "dt=2024"}, "", nil},
+		{"It rejects empty keys", template.Must(template.New("key").Parse("{{.prefix}}")), "", map[string]interface{}{"prefix": ""}, "", upload.ErrEmptyKey},
+		{"It needs a path without template", nil, "", nil, "", upload.ErrMissingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upload.NewKeyTemplate(tt.tmpl).Key(tt.path, tt.meta)
+			if got != tt.want || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) || (tt.want == "" && err == nil) {
+				t.Errorf("KeyTemplate.Key() = %s, %v, want %s, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// the caller template still renders missing keys as <no value>
+	if err := tmpl.Execute(io.Discard, map[string]interface{}{}); err != nil {
+		t.Errorf("NewKeyTemplate() changed the caller template, Execute() error = %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	if got := upload.Merge(nil, nil); got != nil {
+		t.Errorf("Merge() = %v, want nil", got)
+	}
+
+	got := upload.Merge(map[string]string{"a": "base", "b": "base"}, map[string]string{"b": "override"})
+	if want := map[string]string{"a": "base", "b": "override"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+
+	if got := upload.Override("", "fallback"); got != "fallback" {
+		t.Errorf("Override() = %s, want fallback", got)
+	}
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"errors"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+var (
+	// ErrMessageNotTracked is returned acknowledging a message which isn't in flight anymore (ex. its partition was revoked)
+	ErrMessageNotTracked = errors.New("kafka message not tracked, its partition may have been revoked")
+	// ErrConsumerClosed is returned by Run after Close
+	ErrConsumerClosed = errors.New("kafka consumer closed")
+)
+
+// KafkaMessage is a consumed record, also appended on metadata under worker name to be acknowledged later (see KafkaCommitter)
+type KafkaMessage struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string][]byte
+	Timestamp time.Time
+	// epoch is the partition leader epoch, used on commit for truncation detection
+	epoch int32
+}
+
+func newKafkaMessage(r *kgo.Record) *KafkaMessage {
+	msg := &KafkaMessage{
+		Topic:     r.Topic,
+		Partition: r.Partition,
+		Offset:    r.Offset,
+		Key:       r.Key,
+		Value:     r.Value,
+		Headers:   make(map[string][]byte, len(r.Headers)),
+		Timestamp: r.Timestamp,
+		epoch:     r.LeaderEpoch,
+	}
+
+	for _, h := range r.Headers {
+		msg.Headers[h.Key] = h.Value
+	}
+
+	return msg
+}
@@ -0,0 +1,42 @@
+package kafka
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+// Acker acknowledges consumed messages, implemented by KafkaConsumer
+type Acker interface {
+	Ack(*KafkaMessage) error
+}
+
+// KafkaCommitter acknowledges messages of a KafkaConsumer, allowing their offsets to be committed.
+// Put it after the last stage of the pipeline, adapting the message kept on metadata by the consumer
+type KafkaCommitter[I *KafkaMessage, O task.Nullable] struct {
+	consumer Acker
+	logger   *slog.Logger
+}
+
+func NewKafkaCommitter[I *KafkaMessage, O task.Nullable](consumer Acker, logger *slog.Logger) *KafkaCommitter[I, O] {
+	c := new(KafkaCommitter[I, O])
+
+	c.consumer = consumer
+	c.logger = logger
+
+	return c
+}
+
+// Run() acknowledges the given message. It only returns errors
+func (c *KafkaCommitter[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	msg := (*KafkaMessage)(input)
+
+	if err := c.consumer.Ack(msg); err != nil {
+		c.logger.Error("error acknowledging kafka message", "error", err, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+		return O(task.Nullable{}), err
+	}
+
+	return O(task.Nullable{}), nil
+}
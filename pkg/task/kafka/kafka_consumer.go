@@ -0,0 +1,255 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	DefaultMaxPollRecords = 100
+	DefaultMaxInFlight    = 1000
+)
+
+type KafkaConsumerOpts struct {
+	// Brokers to bootstrap the client
+	Brokers []string
+	// Group is the consumer group, partitions of Topics are balanced between its members
+	Group string
+	// Topics consumed
+	Topics []string
+	// PollTimeout is how long Run waits for records before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+	// MaxPollRecords fetched at once, they are buffered and emitted one per Run. Defaults to DefaultMaxPollRecords
+	MaxPollRecords int
+	// MaxInFlight messages of a partition waiting acknowledgement, fetching of the partition pauses when reached until
+	// its messages are acknowledged (or skipped with Nack). Defaults to DefaultMaxInFlight
+	MaxInFlight int
+	// CommitInterval between commits of acknowledged offsets. Defaults to the client default (5s)
+	CommitInterval time.Duration
+	// ClientOpts are given to the client (ex. SASL, TLS or kgo.ConsumeResetOffset)
+	ClientOpts []kgo.Opt
+}
+
+// KafkaConsumer is a source task (to be used with ProducerWorker) which consumes topics as a member of a consumer group.
+// Every Run() returns one message, also appended on metadata under worker name. Offsets are only committed after
+// messages are acknowledged (see KafkaCommitter), never past an unacknowledged message of the same partition,
+// so messages not acknowledged are delivered again after a restart or rebalance (at least once).
+// When there is no message to emit it returns task.ErrNoData.
+type KafkaConsumer[I task.Nullable, O *KafkaMessage] struct {
+	client *kgo.Client
+	logger *slog.Logger
+	opts   *KafkaConsumerOpts
+
+	// pollMu serializes polling, Run is called by every goroutine of the worker pool
+	pollMu  sync.Mutex
+	mu      sync.Mutex
+	buffer  []*kgo.Record
+	tracker *offsetTracker
+}
+
+// NewKafkaConsumer creates a KafkaConsumer and its client, joining the group on the first Run
+func NewKafkaConsumer[I task.Nullable, O *KafkaMessage](logger *slog.Logger, opts *KafkaConsumerOpts) (*KafkaConsumer[I, O], error) {
+	if opts.Group == "" || len(opts.Topics) == 0 {
+		return nil, errors.New("kafka consumer needs a group and topics")
+	}
+
+	c := new(KafkaConsumer[I, O])
+
+	c.logger = logger
+	c.opts = opts
+	c.tracker = newOffsetTracker()
+
+	if c.opts.PollTimeout == 0 {
+		c.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	if c.opts.MaxPollRecords == 0 {
+		c.opts.MaxPollRecords = DefaultMaxPollRecords
+	}
+
+	if c.opts.MaxInFlight == 0 {
+		c.opts.MaxInFlight = DefaultMaxInFlight
+	}
+
+	clientOpts := []kgo.Opt{
+		kgo.SeedBrokers(opts.Brokers...),
+		kgo.ConsumerGroup(opts.Group),
+		kgo.ConsumeTopics(opts.Topics...),
+		kgo.AutoCommitMarks(),
+		kgo.OnPartitionsRevoked(c.onRevoked),
+		kgo.OnPartitionsLost(c.onLost),
+	}
+
+	if opts.CommitInterval > 0 {
+		clientOpts = append(clientOpts, kgo.AutoCommitInterval(opts.CommitInterval))
+	}
+
+	client, err := kgo.NewClient(append(clientOpts, opts.ClientOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.client = client
+
+	return c, nil
+}
+
+func (c *KafkaConsumer[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	msg, err := c.next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	meta[name] = msg
+
+	c.logger.Debug("kafka message consumed", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+	return msg, nil
+}
+
+// next pops a buffered message, polling when the buffer is empty
+func (c *KafkaConsumer[I, O]) next(ctx context.Context) (*KafkaMessage, error) {
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+
+	if msg := c.pop(); msg != nil {
+		return msg, nil
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, c.opts.PollTimeout)
+	defer cancel()
+
+	fetches := c.client.PollRecords(pollCtx, c.opts.MaxPollRecords)
+	if fetches.IsClientClosed() {
+		return nil, ErrConsumerClosed
+	}
+
+	for _, fetchErr := range fetches.Errors() {
+		if errors.Is(fetchErr.Err, context.DeadlineExceeded) || errors.Is(fetchErr.Err, context.Canceled) {
+			continue
+		}
+
+		c.logger.Error("kafka fetch error", "error", fetchErr.Err, "topic", fetchErr.Topic, "partition", fetchErr.Partition)
+	}
+
+	c.mu.Lock()
+	c.buffer = append(c.buffer, fetches.Records()...)
+	c.mu.Unlock()
+
+	if msg := c.pop(); msg != nil {
+		return msg, nil
+	}
+
+	return nil, task.ErrNoData
+}
+
+// pop returns the next buffered message of a partition below MaxInFlight, tracking it as in flight.
+// Fetching of a partition is paused when it reaches MaxInFlight
+func (c *KafkaConsumer[I, O]) pop() *KafkaMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.buffer, func(r *kgo.Record) bool {
+		return c.tracker.pending(r.Topic, r.Partition) < c.opts.MaxInFlight
+	})
+	if i < 0 {
+		return nil
+	}
+
+	msg := newKafkaMessage(c.buffer[i])
+	c.buffer = slices.Delete(c.buffer, i, i+1)
+
+	c.tracker.track(msg)
+
+	if c.tracker.pending(msg.Topic, msg.Partition) == c.opts.MaxInFlight {
+		c.logger.Warn("kafka partition reached max in flight, pausing it", "topic", msg.Topic, "partition", msg.Partition)
+		c.client.PauseFetchPartitions(map[string][]int32{msg.Topic: {msg.Partition}})
+	}
+
+	return msg
+}
+
+// Ack acknowledges a message returned by Run, marking its partition to be committed up to the first unacknowledged message
+func (c *KafkaConsumer[I, O]) Ack(msg *KafkaMessage) error {
+	c.mu.Lock()
+	full := c.tracker.pending(msg.Topic, msg.Partition) >= c.opts.MaxInFlight
+	offset, advanced, err := c.tracker.ack(msg)
+	resume := full && c.tracker.pending(msg.Topic, msg.Partition) < c.opts.MaxInFlight
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if advanced {
+		c.client.MarkCommitOffsets(map[string]map[int32]kgo.EpochOffset{msg.Topic: {msg.Partition: offset}})
+	}
+
+	if resume {
+		c.client.ResumeFetchPartitions(map[string][]int32{msg.Topic: {msg.Partition}})
+	}
+
+	return nil
+}
+
+// Nack skips a message returned by Run which failed, so it doesn't hold the commits of its partition forever.
+// Kafka can't redeliver a single message, produce it to a dead letter topic first when it must be kept
+func (c *KafkaConsumer[I, O]) Nack(msg *KafkaMessage) error {
+	c.logger.Warn("skipping failed kafka message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+	return c.Ack(msg)
+}
+
+// Commit commits acknowledged offsets now, instead of waiting CommitInterval
+func (c *KafkaConsumer[I, O]) Commit(ctx context.Context) error {
+	return c.client.CommitMarkedOffsets(ctx)
+}
+
+// Close commits acknowledged offsets and leaves the group
+func (c *KafkaConsumer[I, O]) Close(ctx context.Context) error {
+	err := c.Commit(ctx)
+
+	c.mu.Lock()
+	inFlight := c.tracker.inFlight()
+	c.mu.Unlock()
+
+	c.logger.Info("closing kafka consumer", "group", c.opts.Group, "in_flight", inFlight)
+	c.client.Close()
+
+	return err
+}
+
+func (c *KafkaConsumer[I, O]) onRevoked(ctx context.Context, client *kgo.Client, revoked map[string][]int32) {
+	c.forget(revoked)
+
+	if err := client.CommitMarkedOffsets(ctx); err != nil {
+		c.logger.Error("error committing revoked partitions", "error", err)
+	}
+}
+
+func (c *KafkaConsumer[I, O]) onLost(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+	c.forget(lost)
+}
+
+// forget drops in flight and buffered messages of partitions no longer assigned
+func (c *KafkaConsumer[I, O]) forget(partitions map[string][]int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tracker.revoke(partitions)
+	// paused partitions stay paused across rebalances, a new assignment starts fetching again
+	c.client.ResumeFetchPartitions(partitions)
+
+	c.buffer = slices.DeleteFunc(c.buffer, func(r *kgo.Record) bool {
+		return slices.Contains(partitions[r.Topic], r.Partition)
+	})
+
+	c.logger.Info("kafka partitions revoked", "partitions", partitions)
+}
@@ -0,0 +1,240 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/kafka"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func newCluster(t *testing.T, topics ...string) []string {
+	t.Helper()
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topics...))
+	if err != nil {
+		t.Fatalf("kfake.NewCluster() error = %v", err)
+	}
+	t.Cleanup(cluster.Close)
+
+	return cluster.ListenAddrs()
+}
+
+func produce(t *testing.T, brokers []string, topic string, n int) {
+	t.Helper()
+
+	client, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	if err != nil {
+		t.Fatalf("kgo.NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < n; i++ {
+		record := &kgo.Record{Topic: topic, Value: []byte(fmt.Sprintf("event-%d", i)), Headers: []kgo.RecordHeader{{Key: "source", Value: []byte("test")}}}
+		if err := client.ProduceSync(context.TODO(), record).FirstErr(); err != nil {
+			t.Fatalf("ProduceSync() error = %v", err)
+		}
+	}
+}
+
+// consume runs the consumer until n messages are returned
+func consume(t *testing.T, c *kafka.KafkaConsumer[task.Nullable, *kafka.KafkaMessage], n int) []*kafka.KafkaMessage {
+	t.Helper()
+
+	msgs := []*kafka.KafkaMessage{}
+	deadline := time.Now().Add(10 * time.Second)
+
+	for len(msgs) < n && time.Now().Before(deadline) {
+		meta := map[string]interface{}{}
+
+		msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "kafka")
+		if errors.Is(err, task.ErrNoData) {
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("KafkaConsumer.Run() error = %v", err)
+		}
+
+		if meta["kafka"] != msg {
+			t.Errorf("KafkaConsumer.Run() metadata = %v, want the returned message", meta["kafka"])
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	if len(msgs) != n {
+		t.Fatalf("KafkaConsumer.Run() returned %d messages, want %d", len(msgs), n)
+	}
+
+	return msgs
+}
+
+func newConsumer(t *testing.T, brokers []string) *kafka.KafkaConsumer[task.Nullable, *kafka.KafkaMessage] {
+	t.Helper()
+
+	c, err := kafka.NewKafkaConsumer(slog.New(slog.NewTextHandler(os.Stdout, nil)), &kafka.KafkaConsumerOpts{
+		Brokers:     brokers,
+		Group:       "vecna",
+		Topics:      []string{"events"},
+		PollTimeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewKafkaConsumer() error = %v", err)
+	}
+
+	return c
+}
+
+func TestKafkaConsumer_Run(t *testing.T) {
+	brokers := newCluster(t, "events")
+	produce(t, brokers, "events", 5)
+
+	c := newConsumer(t, brokers)
+	msgs := consume(t, c, 5)
+
+	for i, msg := range msgs {
+		if msg.Offset != int64(i) || string(msg.Value) != fmt.Sprintf("event-%d", i) || string(msg.Headers["source"]) != "test" {
+			t.Errorf("KafkaConsumer.Run() = offset %d value %s headers %v, want offset %d event-%d", msg.Offset, msg.Value, msg.Headers, i, i)
+		}
+	}
+
+	// nothing more to consume
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "kafka"); !errors.Is(err, task.ErrNoData) {
+		t.Errorf("KafkaConsumer.Run() error = %v, want ErrNoData", err)
+	}
+
+	c.Close(context.TODO())
+
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "kafka"); !errors.Is(err, kafka.ErrConsumerClosed) {
+		t.Errorf("KafkaConsumer.Run() after Close error = %v, want ErrConsumerClosed", err)
+	}
+}
+
+func TestKafkaConsumer_CommitOnAck(t *testing.T) {
+	tests := []struct {
+		name       string
+		ack        []int
+		nack       []int
+		wantOffset int64
+	}{
+		{"It commits nothing without acks", nil, nil, 0},
+		{"It commits every acked message", []int{0, 1, 2, 3, 4}, nil, -1},
+		{"It commits up to the first unacked message", []int{0, 1, 3, 4}, nil, 2},
+		{"It commits acked out of order", []int{2, 1, 0}, nil, 3},
+		{"It commits past nacked messages", []int{0, 1, 3, 4}, []int{2}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brokers := newCluster(t, "events")
+			produce(t, brokers, "events", 5)
+
+			c := newConsumer(t, brokers)
+			msgs := consume(t, c, 5)
+
+			committer := kafka.NewKafkaCommitter(c, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+			for _, i := range tt.ack {
+				if _, err := committer.Run(context.TODO(), msgs[i], map[string]interface{}{}, "committer"); err != nil {
+					t.Fatalf("KafkaCommitter.Run() error = %v", err)
+				}
+			}
+
+			for _, i := range tt.nack {
+				if err := c.Nack(msgs[i]); err != nil {
+					t.Fatalf("KafkaConsumer.Nack() error = %v", err)
+				}
+			}
+
+			if err := c.Close(context.TODO()); err != nil {
+				t.Fatalf("KafkaConsumer.Close() error = %v", err)
+			}
+
+			if tt.wantOffset < 0 {
+				produce(t, brokers, "events", 1)
+				tt.wantOffset = 5
+			}
+
+			// a new member of the group restarts from the committed offset
+			restarted := newConsumer(t, brokers)
+			defer restarted.Close(context.TODO())
+
+			if got := consume(t, restarted, 1)[0].Offset; got != tt.wantOffset {
+				t.Errorf("KafkaConsumer restarted from offset %d, want %d", got, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestKafkaConsumer_MaxInFlight(t *testing.T) {
+	brokers := newCluster(t, "events")
+	produce(t, brokers, "events", 5)
+
+	c, err := kafka.NewKafkaConsumer(slog.New(slog.NewTextHandler(os.Stdout, nil)), &kafka.KafkaConsumerOpts{
+		Brokers:     brokers,
+		Group:       "vecna",
+		Topics:      []string{"events"},
+		PollTimeout: 100 * time.Millisecond,
+		MaxInFlight: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewKafkaConsumer() error = %v", err)
+	}
+	defer c.Close(context.TODO())
+
+	msgs := consume(t, c, 2)
+
+	// the partition is full until a message is acknowledged
+	for i := 0; i < 3; i++ {
+		if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "kafka"); !errors.Is(err, task.ErrNoData) {
+			t.Fatalf("KafkaConsumer.Run() error = %v, want ErrNoData at MaxInFlight", err)
+		}
+	}
+
+	if err := c.Nack(msgs[0]); err != nil {
+		t.Fatalf("KafkaConsumer.Nack() error = %v", err)
+	}
+
+	next := consume(t, c, 1)[0]
+	if next.Offset != 2 {
+		t.Fatalf("KafkaConsumer.Run() offset = %d after Nack, want 2", next.Offset)
+	}
+
+	// fetching resumes once acknowledged, also for messages produced while paused
+	produce(t, brokers, "events", 3)
+
+	for _, msg := range []*kafka.KafkaMessage{msgs[1], next} {
+		if err := c.Ack(msg); err != nil {
+			t.Fatalf("KafkaConsumer.Ack() error = %v", err)
+		}
+	}
+
+	for want := int64(3); want < 8; want++ {
+		msg := consume(t, c, 1)[0]
+		if msg.Offset != want {
+			t.Fatalf("KafkaConsumer.Run() offset = %d, want %d", msg.Offset, want)
+		}
+
+		c.Ack(msg)
+	}
+}
+
+func TestKafkaCommitter_RunNotTracked(t *testing.T) {
+	brokers := newCluster(t, "events")
+
+	c := newConsumer(t, brokers)
+	defer c.Close(context.TODO())
+
+	committer := kafka.NewKafkaCommitter(c, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	_, err := committer.Run(context.TODO(), &kafka.KafkaMessage{Topic: "events", Offset: 3}, map[string]interface{}{}, "committer")
+	if !errors.Is(err, kafka.ErrMessageNotTracked) {
+		t.Errorf("KafkaCommitter.Run() error = %v, want ErrMessageNotTracked", err)
+	}
+}
@@ -0,0 +1,120 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type KafkaProducerOpts struct {
+	// Brokers to bootstrap the client
+	Brokers []string
+	// Topic where messages are produced
+	Topic string
+	// TopicMetaKey when given, a string on metadata under it overrides Topic
+	TopicMetaKey string
+	// KeyMetaKey when given, a string or []byte on metadata under it is used as record key (same key, same partition)
+	KeyMetaKey string
+	// HeaderMetaKeys are copied from metadata (string or []byte values) to record headers
+	HeaderMetaKeys []string
+	// Linger waits records to fill batches, records given by concurrent Run calls are batched together. Defaults to no linger
+	Linger time.Duration
+	// BatchMaxBytes caps batches size. Defaults to the client default (~1MB)
+	BatchMaxBytes int32
+	// Idempotent writes avoid duplicates when the client retries, using acks from all in sync replicas
+	Idempotent bool
+	// ClientOpts are given to the client (ex. SASL, TLS or kgo.ProducerBatchCompression)
+	ClientOpts []kgo.Opt
+}
+
+// KafkaProduceInfo is appended on metadata under worker name with where the message was written
+type KafkaProduceInfo struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// KafkaProducer is a task which produces the input as record value, returning after the broker acknowledged it.
+type KafkaProducer[I []byte, O task.Nullable] struct {
+	client *kgo.Client
+	logger *slog.Logger
+	opts   *KafkaProducerOpts
+}
+
+// NewKafkaProducer creates a KafkaProducer and its client
+func NewKafkaProducer[I []byte, O task.Nullable](logger *slog.Logger, opts *KafkaProducerOpts) (*KafkaProducer[I, O], error) {
+	if opts.Topic == "" && opts.TopicMetaKey == "" {
+		return nil, errors.New("kafka producer needs a topic")
+	}
+
+	p := new(KafkaProducer[I, O])
+
+	p.logger = logger
+	p.opts = opts
+
+	clientOpts := []kgo.Opt{kgo.SeedBrokers(opts.Brokers...)}
+
+	if !opts.Idempotent {
+		clientOpts = append(clientOpts, kgo.DisableIdempotentWrite())
+	}
+
+	if opts.Linger > 0 {
+		clientOpts = append(clientOpts, kgo.ProducerLinger(opts.Linger))
+	}
+
+	if opts.BatchMaxBytes > 0 {
+		clientOpts = append(clientOpts, kgo.ProducerBatchMaxBytes(opts.BatchMaxBytes))
+	}
+
+	client, err := kgo.NewClient(append(clientOpts, opts.ClientOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.client = client
+
+	return p, nil
+}
+
+func (p *KafkaProducer[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	record := &kgo.Record{Topic: p.opts.Topic, Value: input}
+
+	if topic, ok := meta[p.opts.TopicMetaKey].(string); ok && p.opts.TopicMetaKey != "" {
+		record.Topic = topic
+	}
+
+	if key, ok := metadata.Bytes(meta[p.opts.KeyMetaKey]); ok && p.opts.KeyMetaKey != "" {
+		record.Key = key
+	}
+
+	for _, k := range p.opts.HeaderMetaKeys {
+		if value, ok := metadata.Bytes(meta[k]); ok {
+			record.Headers = append(record.Headers, kgo.RecordHeader{Key: k, Value: value})
+		}
+	}
+
+	if err := p.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		p.logger.Error("error producing kafka message", "error", err, "topic", record.Topic)
+
+		return O(task.Nullable{}), err
+	}
+
+	meta[name] = &KafkaProduceInfo{Topic: record.Topic, Partition: record.Partition, Offset: record.Offset}
+
+	p.logger.Debug("kafka message produced", "topic", record.Topic, "partition", record.Partition, "offset", record.Offset)
+
+	return O(task.Nullable{}), nil
+}
+
+// Close flushes buffered records and closes the client
+func (p *KafkaProducer[I, O]) Close(ctx context.Context) error {
+	err := p.client.Flush(ctx)
+	p.client.Close()
+
+	return err
+}
@@ -0,0 +1,77 @@
+package kafka_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task/kafka"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestKafkaProducer_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        kafka.KafkaProducerOpts
+		meta        map[string]interface{}
+		wantTopic   string
+		wantKey     []byte
+		wantHeaders []kgo.RecordHeader
+	}{
+		{"It produces to the topic", kafka.KafkaProducerOpts{Topic: "events"}, map[string]interface{}{}, "events", nil, nil},
+		{"It produces idempotently", kafka.KafkaProducerOpts{Topic: "events", Idempotent: true, Linger: time.Millisecond}, map[string]interface{}{}, "events", nil, nil},
+		{"It takes key and headers from metadata", kafka.KafkaProducerOpts{Topic: "events", KeyMetaKey: "user_id", HeaderMetaKeys: []string{"trace_id", "missing"}},
+			map[string]interface{}{"user_id": "42", "trace_id": []byte("abc")}, "events", []byte("42"), []kgo.RecordHeader{{Key: "trace_id", Value: []byte("abc")}}},
+		{"It takes topic from metadata", kafka.KafkaProducerOpts{Topic: "events", TopicMetaKey: "topic"}, map[string]interface{}{"topic": "audit"}, "audit", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brokers := newCluster(t, "events", "audit")
+
+			tt.opts.Brokers = brokers
+			p, err := kafka.NewKafkaProducer(slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+			if err != nil {
+				t.Fatalf("NewKafkaProducer() error = %v", err)
+			}
+			defer p.Close(context.TODO())
+
+			if _, err := p.Run(context.TODO(), []byte("payload"), tt.meta, "producer"); err != nil {
+				t.Fatalf("KafkaProducer.Run() error = %v", err)
+			}
+
+			info, ok := tt.meta["producer"].(*kafka.KafkaProduceInfo)
+			if !ok || info.Topic != tt.wantTopic || info.Offset != 0 {
+				t.Errorf("KafkaProducer.Run() metadata = %v, want topic %s offset 0", tt.meta["producer"], tt.wantTopic)
+			}
+
+			client, err := kgo.NewClient(kgo.SeedBrokers(brokers...), kgo.ConsumeTopics(tt.wantTopic))
+			if err != nil {
+				t.Fatalf("kgo.NewClient() error = %v", err)
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			records := client.PollRecords(ctx, 1).Records()
+			if len(records) != 1 {
+				t.Fatalf("produced %d records, want 1", len(records))
+			}
+
+			record := records[0]
+			if string(record.Value) != "payload" || string(record.Key) != string(tt.wantKey) ||
+				len(record.Headers) != len(tt.wantHeaders) || (len(tt.wantHeaders) > 0 && !reflect.DeepEqual(record.Headers, tt.wantHeaders)) {
+				t.Errorf("produced value %s key %s headers %v, want payload key %s headers %v", record.Value, record.Key, record.Headers, tt.wantKey, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestNewKafkaProducer_NoTopic(t *testing.T) {
+	if _, err := kafka.NewKafkaProducer(slog.Default(), &kafka.KafkaProducerOpts{Brokers: []string{"localhost:9092"}}); err == nil {
+		t.Errorf("NewKafkaProducer() error = nil, want error")
+	}
+}
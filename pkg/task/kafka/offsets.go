@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type pendingOffset struct {
+	offset int64
+	epoch  int32
+	acked  bool
+}
+
+// offsetTracker keeps in flight offsets of every partition. Messages are processed concurrently and may be acknowledged
+// out of order, so a partition is only committed up to its first unacknowledged offset. Not safe for concurrent use.
+type offsetTracker struct {
+	partitions map[topicPartition][]*pendingOffset
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[topicPartition][]*pendingOffset{}}
+}
+
+// track adds an emitted message, offsets of a partition are always emitted in order
+func (t *offsetTracker) track(msg *KafkaMessage) {
+	tp := topicPartition{msg.Topic, msg.Partition}
+
+	t.partitions[tp] = append(t.partitions[tp], &pendingOffset{offset: msg.Offset, epoch: msg.epoch})
+}
+
+// pending returns how many messages of the partition wait acknowledgement
+func (t *offsetTracker) pending(topic string, partition int32) int {
+	return len(t.partitions[topicPartition{topic, partition}])
+}
+
+// ack marks msg done (acknowledged or skipped), returning the offset to be committed when the partition advanced
+func (t *offsetTracker) ack(msg *KafkaMessage) (kgo.EpochOffset, bool, error) {
+	tp := topicPartition{msg.Topic, msg.Partition}
+	pending := t.partitions[tp]
+
+	found := false
+	for _, p := range pending {
+		if p.offset == msg.Offset {
+			p.acked = true
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		return kgo.EpochOffset{}, false, ErrMessageNotTracked
+	}
+
+	var commit *pendingOffset
+	for len(pending) > 0 && pending[0].acked {
+		commit = pending[0]
+		pending = pending[1:]
+	}
+
+	t.partitions[tp] = pending
+
+	if commit == nil {
+		return kgo.EpochOffset{}, false, nil
+	}
+
+	// committed offset is the next one to be consumed
+	return kgo.EpochOffset{Epoch: commit.epoch, Offset: commit.offset + 1}, true, nil
+}
+
+// revoke forgets partitions no longer assigned, their unacknowledged messages will be delivered to the new owner
+func (t *offsetTracker) revoke(partitions map[string][]int32) {
+	for topic, ps := range partitions {
+		for _, p := range ps {
+			delete(t.partitions, topicPartition{topic, p})
+		}
+	}
+}
+
+// inFlight returns how many messages wait acknowledgement
+func (t *offsetTracker) inFlight() int {
+	n := 0
+	for _, pending := range t.partitions {
+		n += len(pending)
+	}
+
+	return n
+}
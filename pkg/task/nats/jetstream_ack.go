@@ -0,0 +1,87 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type JetStreamAckOpts struct {
+	// MessageMetaKey is the name of the worker running JetStreamConsumer, where its *NATSMessage is kept on metadata
+	MessageMetaKey string
+	// NakDelay before a failed message is delivered again. At once when zero
+	NakDelay time.Duration
+	// InProgressInterval between InProgress calls while the task runs, keeping slow messages from being delivered
+	// again (use less than the consumer AckWait). Disabled when zero
+	InProgressInterval time.Duration
+}
+
+// JetStreamAck wraps a task settling the JetStream message kept on metadata by a JetStreamConsumer.
+// A successful run (or one returning task.ErrNoData, dropping the message on purpose) acks it; a failed one naks it, so the server delivers it again after NakDelay until the consumer
+// MaxDeliver is reached. InProgressInterval resets AckWait of slow runs. Core NATS messages (no JetStream reply)
+// only run the task.
+type JetStreamAck[I any, O any] struct {
+	task   task.Task[I, O]
+	logger *slog.Logger
+	opts   *JetStreamAckOpts
+}
+
+func NewJetStreamAck[I any, O any](t task.Task[I, O], logger *slog.Logger, opts *JetStreamAckOpts) *JetStreamAck[I, O] {
+	a := new(JetStreamAck[I, O])
+
+	a.task = t
+	a.logger = logger
+	a.opts = opts
+
+	return a
+}
+
+func (a *JetStreamAck[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	msg, ok := meta[a.opts.MessageMetaKey].(*NATSMessage)
+	if !ok || msg.msg == nil {
+		return a.task.Run(ctx, input, meta, name)
+	}
+
+	if a.opts.InProgressInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+
+		go a.keepInProgress(msg, done)
+	}
+
+	resp, err := a.task.Run(ctx, input, meta, name)
+	if err != nil && !errors.Is(err, task.ErrNoData) {
+		if nakErr := msg.Nak(a.opts.NakDelay); nakErr != nil {
+			a.logger.Error("error naking jetstream message", "error", nakErr, "subject", msg.Subject, "sequence", msg.StreamSequence)
+		}
+
+		return resp, err
+	}
+
+	if ackErr := msg.Ack(); ackErr != nil {
+		a.logger.Error("error acking jetstream message", "error", ackErr, "subject", msg.Subject, "sequence", msg.StreamSequence)
+
+		return resp, ackErr
+	}
+
+	return resp, err
+}
+
+func (a *JetStreamAck[I, O]) keepInProgress(msg *NATSMessage, done chan struct{}) {
+	ticker := time.NewTicker(a.opts.InProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				a.logger.Warn("error marking jetstream message in progress", "error", err, "sequence", msg.StreamSequence)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnanats "github.com/otaviohenrique/vecna/pkg/task/nats"
+)
+
+type mockTask[I []byte, O string] struct {
+	err   error
+	sleep time.Duration
+}
+
+func (m *mockTask[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	time.Sleep(m.sleep)
+
+	return O(input), m.err
+}
+
+func TestJetStreamAck_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		task          *mockTask[[]byte, string]
+		opts          vecnanats.JetStreamAckOpts
+		ackWait       time.Duration
+		wantErr       bool
+		wantRedeliver bool
+	}{
+		{"It acks when the task succeeds", &mockTask[[]byte, string]{}, vecnanats.JetStreamAckOpts{}, time.Minute, false, false},
+		{"It naks when the task fails", &mockTask[[]byte, string]{err: errors.New("boom")}, vecnanats.JetStreamAckOpts{}, time.Minute, true, true},
+		{"It acks when the task returns ErrNoData", &mockTask[[]byte, string]{err: task.ErrNoData}, vecnanats.JetStreamAckOpts{}, time.Minute, true, false},
+		{"It keeps slow messages in progress", &mockTask[[]byte, string]{sleep: 1500 * time.Millisecond},
+			vecnanats.JetStreamAckOpts{InProgressInterval: 200 * time.Millisecond}, time.Second, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newConn(t)
+			newStream(t, conn, 1)
+
+			c := newJetStreamConsumer(t, conn, tt.ackWait)
+			meta := map[string]interface{}{}
+
+			msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "jetstream")
+			if err != nil {
+				t.Fatalf("JetStreamConsumer.Run() error = %v", err)
+			}
+
+			tt.opts.MessageMetaKey = "jetstream"
+			ack := vecnanats.NewJetStreamAck[[]byte, string](tt.task, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+
+			got, err := ack.Run(context.TODO(), msg.Data, meta, "process")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JetStreamAck.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != "order-0" {
+				t.Errorf("JetStreamAck.Run() = %s, want the task response", got)
+			}
+
+			again, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "jetstream")
+			if redelivered := err == nil; redelivered != tt.wantRedeliver {
+				t.Errorf("JetStreamConsumer.Run() after JetStreamAck = %v, %v, want redelivered %v", again, err, tt.wantRedeliver)
+			}
+		})
+	}
+}
+
+func TestJetStreamAck_RunWithoutMessage(t *testing.T) {
+	ack := vecnanats.NewJetStreamAck[[]byte, string](&mockTask[[]byte, string]{}, slog.Default(), &vecnanats.JetStreamAckOpts{MessageMetaKey: "jetstream"})
+
+	got, err := ack.Run(context.TODO(), []byte("data"), map[string]interface{}{}, "process")
+	if err != nil || got != "data" {
+		t.Errorf("JetStreamAck.Run() = %s, %v, want data", got, err)
+	}
+}
@@ -0,0 +1,114 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const DefaultMaxPendingMsgs = 100
+
+type JetStreamConsumerOpts struct {
+	// Stream consumed
+	Stream string
+	// Durable consumer name, its progress is kept by the server and shared by every instance using it
+	Durable string
+	// FilterSubjects consumed from the stream, every subject when empty
+	FilterSubjects []string
+	// AckWait before an unacknowledged message is delivered again. Defaults to the server default (30s)
+	AckWait time.Duration
+	// MaxDeliver attempts of every message. Unlimited when zero
+	MaxDeliver int
+	// PollTimeout is how long Run waits for a message before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+	// MaxPendingMsgs pulled ahead and buffered by the client. Defaults to DefaultMaxPendingMsgs
+	MaxPendingMsgs int
+}
+
+// JetStreamConsumer is a source task (to be used with ProducerWorker) which pulls messages from a durable JetStream consumer.
+// Every Run() returns one message, also appended on metadata under worker name, to be acknowledged with JetStreamAck
+// (or NATSMessage Ack/Nak/InProgress/Term). Unacknowledged messages are delivered again after AckWait.
+// When there is no message to emit it returns task.ErrNoData.
+type JetStreamConsumer[I task.Nullable, O *NATSMessage] struct {
+	iter   jetstream.MessagesContext
+	logger *slog.Logger
+	opts   *JetStreamConsumerOpts
+}
+
+// NewJetStreamConsumer creates (or updates) the durable consumer on the stream and starts pulling messages
+func NewJetStreamConsumer[I task.Nullable, O *NATSMessage](conn *nats.Conn, logger *slog.Logger, opts *JetStreamConsumerOpts) (*JetStreamConsumer[I, O], error) {
+	if opts.Stream == "" || opts.Durable == "" {
+		return nil, errors.New("jetstream consumer needs a stream and a durable name")
+	}
+
+	c := new(JetStreamConsumer[I, O])
+
+	c.logger = logger
+	c.opts = opts
+
+	if c.opts.PollTimeout == 0 {
+		c.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	if c.opts.MaxPendingMsgs == 0 {
+		c.opts.MaxPendingMsgs = DefaultMaxPendingMsgs
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(context.Background(), opts.Stream, jetstream.ConsumerConfig{
+		Durable:        opts.Durable,
+		FilterSubjects: opts.FilterSubjects,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        opts.AckWait,
+		MaxDeliver:     opts.MaxDeliver,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := consumer.Messages(jetstream.PullMaxMessages(c.opts.MaxPendingMsgs))
+	if err != nil {
+		return nil, err
+	}
+
+	c.iter = iter
+
+	return c, nil
+}
+
+func (c *JetStreamConsumer[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	pollCtx, cancel := context.WithTimeout(ctx, c.opts.PollTimeout)
+	defer cancel()
+
+	received, err := c.iter.Next(jetstream.NextContext(pollCtx))
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, task.ErrNoData
+		}
+
+		c.logger.Error("error receiving jetstream message", "error", err, "stream", c.opts.Stream, "consumer", c.opts.Durable)
+
+		return nil, err
+	}
+
+	msg := newJetStreamMessage(received)
+	meta[name] = msg
+
+	c.logger.Debug("jetstream message received", "subject", msg.Subject, "sequence", msg.StreamSequence, "delivered", msg.NumDelivered)
+
+	return msg, nil
+}
+
+// Close stops pulling messages, buffered messages not returned yet are delivered again after AckWait
+func (c *JetStreamConsumer[I, O]) Close() {
+	c.iter.Stop()
+}
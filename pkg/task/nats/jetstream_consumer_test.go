@@ -0,0 +1,137 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnanats "github.com/otaviohenrique/vecna/pkg/task/nats"
+)
+
+// newStream creates the ORDERS stream with n published messages
+func newStream(t *testing.T, conn *nats.Conn, n int) {
+	t.Helper()
+
+	js, _ := jetstream.New(conn)
+	if _, err := js.CreateStream(context.TODO(), jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}}); err != nil {
+		t.Fatalf("CreateStream() error = %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		if _, err := js.Publish(context.TODO(), "orders.created", []byte(fmt.Sprintf("order-%d", i))); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+}
+
+func newJetStreamConsumer(t *testing.T, conn *nats.Conn, ackWait time.Duration) *vecnanats.JetStreamConsumer[task.Nullable, *vecnanats.NATSMessage] {
+	t.Helper()
+
+	c, err := vecnanats.NewJetStreamConsumer(conn, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnanats.JetStreamConsumerOpts{
+		Stream:         "ORDERS",
+		Durable:        "vecna",
+		FilterSubjects: []string{"orders.created"},
+		AckWait:        ackWait,
+		PollTimeout:    100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewJetStreamConsumer() error = %v", err)
+	}
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func TestJetStreamConsumer_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		outcome       func(*vecnanats.NATSMessage) error
+		wantRedeliver bool
+	}{
+		{"It doesn't deliver acked messages again", (*vecnanats.NATSMessage).Ack, false},
+		{"It delivers naked messages again", func(m *vecnanats.NATSMessage) error { return m.Nak(0) }, true},
+		{"It doesn't deliver termed messages again", (*vecnanats.NATSMessage).Term, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newConn(t)
+			newStream(t, conn, 1)
+
+			c := newJetStreamConsumer(t, conn, time.Minute)
+			meta := map[string]interface{}{}
+
+			msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "jetstream")
+			if err != nil {
+				t.Fatalf("JetStreamConsumer.Run() error = %v", err)
+			}
+
+			if string(msg.Data) != "order-0" || msg.StreamSequence != 1 || msg.NumDelivered != 1 || meta["jetstream"] != msg {
+				t.Errorf("JetStreamConsumer.Run() = %+v, want order-0 sequence 1 delivered once", msg)
+			}
+
+			if err := tt.outcome(msg); err != nil {
+				t.Fatalf("outcome error = %v", err)
+			}
+
+			again, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "jetstream")
+			if tt.wantRedeliver {
+				if err != nil || again.StreamSequence != 1 || again.NumDelivered != 2 {
+					t.Errorf("JetStreamConsumer.Run() = %+v, %v, want sequence 1 delivered twice", again, err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, task.ErrNoData) {
+				t.Errorf("JetStreamConsumer.Run() error = %v, want ErrNoData", err)
+			}
+		})
+	}
+}
+
+func TestJetStreamConsumer_RunDurable(t *testing.T) {
+	conn := newConn(t)
+	newStream(t, conn, 2)
+
+	first := newJetStreamConsumer(t, conn, 500*time.Millisecond)
+
+	msg, err := first.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "jetstream")
+	if err != nil {
+		t.Fatalf("JetStreamConsumer.Run() error = %v", err)
+	}
+	msg.Ack()
+	first.Close()
+
+	// the durable consumer keeps its progress, order-1 pulled ahead by first is delivered again after AckWait
+	restarted := newJetStreamConsumer(t, conn, 500*time.Millisecond)
+
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); {
+		msg, err = restarted.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "jetstream")
+		if !errors.Is(err, task.ErrNoData) {
+			break
+		}
+	}
+
+	if err != nil || string(msg.Data) != "order-1" {
+		t.Errorf("JetStreamConsumer.Run() after restart = %v, %v, want order-1", msg, err)
+	}
+}
+
+func TestNewJetStreamConsumer_Invalid(t *testing.T) {
+	conn := newConn(t)
+
+	if _, err := vecnanats.NewJetStreamConsumer(conn, slog.Default(), &vecnanats.JetStreamConsumerOpts{Stream: "ORDERS"}); err == nil {
+		t.Errorf("NewJetStreamConsumer() without durable error = nil, want error")
+	}
+
+	if _, err := vecnanats.NewJetStreamConsumer(conn, slog.Default(), &vecnanats.JetStreamConsumerOpts{Stream: "MISSING", Durable: "vecna"}); err == nil {
+		t.Errorf("NewJetStreamConsumer() on missing stream error = nil, want error")
+	}
+}
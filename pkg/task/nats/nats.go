@@ -0,0 +1,82 @@
+package nats
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrNotJetStream is returned acknowledging a message received from a core NATS subscription
+var ErrNotJetStream = errors.New("nats message not received from jetstream")
+
+// NATSMessage is a received message, also appended on metadata under worker name.
+// Messages consumed from JetStream can be acknowledged (see JetStreamAck).
+type NATSMessage struct {
+	Subject string
+	// Reply subject, when the publisher waits a response (request-reply)
+	Reply  string
+	Data   []byte
+	Header nats.Header
+	// StreamSequence and NumDelivered are only filled for JetStream messages
+	StreamSequence uint64
+	NumDelivered   uint64
+
+	msg jetstream.Msg
+}
+
+func newCoreMessage(msg *nats.Msg) *NATSMessage {
+	return &NATSMessage{Subject: msg.Subject, Reply: msg.Reply, Data: msg.Data, Header: msg.Header}
+}
+
+func newJetStreamMessage(msg jetstream.Msg) *NATSMessage {
+	m := &NATSMessage{Subject: msg.Subject(), Reply: msg.Reply(), Data: msg.Data(), Header: msg.Headers(), msg: msg}
+
+	if md, err := msg.Metadata(); err == nil {
+		m.StreamSequence = md.Sequence.Stream
+		m.NumDelivered = md.NumDelivered
+	}
+
+	return m
+}
+
+// Ack acknowledges the message, it won't be delivered again
+func (m *NATSMessage) Ack() error {
+	if m.msg == nil {
+		return ErrNotJetStream
+	}
+
+	return m.msg.Ack()
+}
+
+// Nak asks the server to deliver the message again after delay (at once when zero)
+func (m *NATSMessage) Nak(delay time.Duration) error {
+	if m.msg == nil {
+		return ErrNotJetStream
+	}
+
+	if delay > 0 {
+		return m.msg.NakWithDelay(delay)
+	}
+
+	return m.msg.Nak()
+}
+
+// InProgress resets the ack wait of the message, telling the server it's still being processed
+func (m *NATSMessage) InProgress() error {
+	if m.msg == nil {
+		return ErrNotJetStream
+	}
+
+	return m.msg.InProgress()
+}
+
+// Term tells the server to never deliver the message again
+func (m *NATSMessage) Term() error {
+	if m.msg == nil {
+		return ErrNotJetStream
+	}
+
+	return m.msg.Term()
+}
@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+)
+
+type NATSPublisherOpts struct {
+	// Subject where messages are published
+	Subject string
+	// SubjectMetaKey when given, a string on metadata under it overrides Subject
+	SubjectMetaKey string
+	// HeaderMetaKeys are copied from metadata (string or []byte values) to message headers
+	HeaderMetaKeys []string
+	// JetStream publishes waiting the stream acknowledgement, the subject must be bound to a stream.
+	// Its *jetstream.PubAck is appended on metadata under worker name
+	JetStream bool
+	// MsgIDMetaKey when given, a string on metadata under it is sent as Nats-Msg-Id, deduplicated by the stream (JetStream only)
+	MsgIDMetaKey string
+}
+
+// NATSPublisher is a task which publishes the input as message data, on core NATS or JetStream.
+type NATSPublisher[I []byte, O task.Nullable] struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	logger *slog.Logger
+	opts   *NATSPublisherOpts
+}
+
+func NewNATSPublisher[I []byte, O task.Nullable](conn *nats.Conn, logger *slog.Logger, opts *NATSPublisherOpts) (*NATSPublisher[I, O], error) {
+	if opts.Subject == "" && opts.SubjectMetaKey == "" {
+		return nil, errors.New("nats publisher needs a subject")
+	}
+
+	p := new(NATSPublisher[I, O])
+
+	p.conn = conn
+	p.logger = logger
+	p.opts = opts
+
+	if opts.JetStream {
+		js, err := jetstream.New(conn)
+		if err != nil {
+			return nil, err
+		}
+
+		p.js = js
+	}
+
+	return p, nil
+}
+
+func (p *NATSPublisher[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	msg := nats.NewMsg(p.opts.Subject)
+	msg.Data = input
+
+	if subject, ok := meta[p.opts.SubjectMetaKey].(string); ok && p.opts.SubjectMetaKey != "" {
+		msg.Subject = subject
+	}
+
+	for _, k := range p.opts.HeaderMetaKeys {
+		if value, ok := metadata.Bytes(meta[k]); ok {
+			msg.Header.Set(k, string(value))
+		}
+	}
+
+	if p.js == nil {
+		if err := p.conn.PublishMsg(msg); err != nil {
+			p.logger.Error("error publishing nats message", "error", err, "subject", msg.Subject)
+
+			return O(task.Nullable{}), err
+		}
+
+		return O(task.Nullable{}), nil
+	}
+
+	var pubOpts []jetstream.PublishOpt
+	if id, ok := meta[p.opts.MsgIDMetaKey].(string); ok && p.opts.MsgIDMetaKey != "" {
+		pubOpts = append(pubOpts, jetstream.WithMsgID(id))
+	}
+
+	ack, err := p.js.PublishMsg(ctx, msg, pubOpts...)
+	if err != nil {
+		p.logger.Error("error publishing jetstream message", "error", err, "subject", msg.Subject)
+
+		return O(task.Nullable{}), err
+	}
+
+	meta[name] = ack
+
+	p.logger.Debug("jetstream message published", "subject", msg.Subject, "stream", ack.Stream, "sequence", ack.Sequence, "duplicate", ack.Duplicate)
+
+	return O(task.Nullable{}), nil
+}
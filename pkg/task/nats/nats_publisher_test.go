@@ -0,0 +1,89 @@
+package nats_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	vecnanats "github.com/otaviohenrique/vecna/pkg/task/nats"
+)
+
+func TestNATSPublisher_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        vecnanats.NATSPublisherOpts
+		meta        map[string]interface{}
+		wantSubject string
+		wantHeader  string
+	}{
+		{"It publishes on the subject", vecnanats.NATSPublisherOpts{Subject: "orders"}, map[string]interface{}{}, "orders", ""},
+		{"It takes subject and headers from metadata", vecnanats.NATSPublisherOpts{Subject: "orders", SubjectMetaKey: "subject", HeaderMetaKeys: []string{"trace_id"}},
+			map[string]interface{}{"subject": "audit", "trace_id": []byte("abc")}, "audit", "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newConn(t)
+
+			sub, err := conn.SubscribeSync(tt.wantSubject)
+			if err != nil {
+				t.Fatalf("SubscribeSync() error = %v", err)
+			}
+
+			p, err := vecnanats.NewNATSPublisher(conn, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+			if err != nil {
+				t.Fatalf("NewNATSPublisher() error = %v", err)
+			}
+
+			if _, err := p.Run(context.TODO(), []byte("payload"), tt.meta, "publisher"); err != nil {
+				t.Fatalf("NATSPublisher.Run() error = %v", err)
+			}
+
+			msg, err := sub.NextMsg(time.Second)
+			if err != nil {
+				t.Fatalf("NextMsg() error = %v", err)
+			}
+
+			if string(msg.Data) != "payload" || msg.Header.Get("trace_id") != tt.wantHeader {
+				t.Errorf("published %s with trace_id %q, want payload with %q", msg.Data, msg.Header.Get("trace_id"), tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestNATSPublisher_RunJetStream(t *testing.T) {
+	conn := newConn(t)
+	js, _ := jetstream.New(conn)
+
+	if _, err := js.CreateStream(context.TODO(), jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}}); err != nil {
+		t.Fatalf("CreateStream() error = %v", err)
+	}
+
+	p, err := vecnanats.NewNATSPublisher(conn, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnanats.NATSPublisherOpts{
+		Subject:      "orders.created",
+		JetStream:    true,
+		MsgIDMetaKey: "order_id",
+	})
+	if err != nil {
+		t.Fatalf("NewNATSPublisher() error = %v", err)
+	}
+
+	for i, wantDuplicate := range []bool{false, true} {
+		meta := map[string]interface{}{"order_id": "order-1"}
+
+		if _, err := p.Run(context.TODO(), []byte("payload"), meta, "publisher"); err != nil {
+			t.Fatalf("NATSPublisher.Run() error = %v", err)
+		}
+
+		ack, ok := meta["publisher"].(*jetstream.PubAck)
+		if !ok || ack.Stream != "ORDERS" || ack.Sequence != 1 || ack.Duplicate != wantDuplicate {
+			t.Errorf("NATSPublisher.Run() %d ack = %+v, want ORDERS sequence 1 duplicate %v", i, meta["publisher"], wantDuplicate)
+		}
+	}
+
+	if _, err := vecnanats.NewNATSPublisher(conn, slog.Default(), &vecnanats.NATSPublisherOpts{}); err == nil {
+		t.Errorf("NewNATSPublisher() without subject error = nil, want error")
+	}
+}
@@ -0,0 +1,85 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type NATSSubscriberOpts struct {
+	// Subject to subscribe, wildcards allowed (ex. "orders.>")
+	Subject string
+	// Queue group, messages are balanced between subscribers of the same queue. Every subscriber receives all messages when empty
+	Queue string
+	// PollTimeout is how long Run waits for a message before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+	// PendingMsgs limits messages buffered by the client, exceeding messages are dropped (slow consumer). Defaults to the client default
+	PendingMsgs int
+}
+
+// NATSSubscriber is a source task (to be used with ProducerWorker) which subscribes a subject on core NATS.
+// Every Run() returns one message, also appended on metadata under worker name.
+// Core NATS delivers at most once, use JetStreamConsumer when messages can't be lost.
+// When there is no message to emit it returns task.ErrNoData.
+type NATSSubscriber[I task.Nullable, O *NATSMessage] struct {
+	sub    *nats.Subscription
+	logger *slog.Logger
+	opts   *NATSSubscriberOpts
+}
+
+// NewNATSSubscriber creates a NATSSubscriber, subscribing the subject at once
+func NewNATSSubscriber[I task.Nullable, O *NATSMessage](conn *nats.Conn, logger *slog.Logger, opts *NATSSubscriberOpts) (*NATSSubscriber[I, O], error) {
+	s := new(NATSSubscriber[I, O])
+
+	s.logger = logger
+	s.opts = opts
+
+	if s.opts.PollTimeout == 0 {
+		s.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	sub, err := conn.QueueSubscribeSync(opts.Subject, opts.Queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PendingMsgs > 0 {
+		if err := sub.SetPendingLimits(opts.PendingMsgs, nats.DefaultSubPendingBytesLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	s.sub = sub
+
+	return s, nil
+}
+
+func (s *NATSSubscriber[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	pollCtx, cancel := context.WithTimeout(ctx, s.opts.PollTimeout)
+	defer cancel()
+
+	received, err := s.sub.NextMsgWithContext(pollCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, task.ErrNoData
+		}
+
+		s.logger.Error("error receiving nats message", "error", err, "subject", s.opts.Subject)
+
+		return nil, err
+	}
+
+	msg := newCoreMessage(received)
+	meta[name] = msg
+
+	return msg, nil
+}
+
+// Close drains the subscription, messages already buffered are still returned by Run
+func (s *NATSSubscriber[I, O]) Close() error {
+	return s.sub.Drain()
+}
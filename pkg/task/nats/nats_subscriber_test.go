@@ -0,0 +1,99 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnanats "github.com/otaviohenrique/vecna/pkg/task/nats"
+)
+
+// newConn starts an embedded server with JetStream enabled and connects to it
+func newConn(t *testing.T) *nats.Conn {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("server.NewServer() error = %v", err)
+	}
+
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("nats server not ready")
+	}
+
+	conn, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("nats.Connect() error = %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	return conn
+}
+
+func TestNATSSubscriber_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		subject     string
+		publishedOn string
+		wantErr     error
+	}{
+		{"It receives messages", "orders.created", "orders.created", nil},
+		{"It receives messages matching wildcards", "orders.>", "orders.eu.created", nil},
+		{"It returns ErrNoData without messages", "orders.created", "users.created", task.ErrNoData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newConn(t)
+
+			s, err := vecnanats.NewNATSSubscriber(conn, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnanats.NATSSubscriberOpts{
+				Subject:     tt.subject,
+				Queue:       "vecna",
+				PollTimeout: 100 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("NewNATSSubscriber() error = %v", err)
+			}
+			defer s.Close()
+
+			msg := nats.NewMsg(tt.publishedOn)
+			msg.Data = []byte("order")
+			msg.Header.Set("source", "test")
+
+			if err := conn.PublishMsg(msg); err != nil {
+				t.Fatalf("PublishMsg() error = %v", err)
+			}
+
+			meta := map[string]interface{}{}
+
+			got, err := s.Run(context.TODO(), task.Nullable{}, meta, "nats")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NATSSubscriber.Run() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Subject != tt.publishedOn || string(got.Data) != "order" || got.Header.Get("source") != "test" {
+				t.Errorf("NATSSubscriber.Run() = %+v, want order on %s", got, tt.publishedOn)
+			}
+
+			if meta["nats"] != got {
+				t.Errorf("NATSSubscriber.Run() metadata = %v, want the returned message", meta["nats"])
+			}
+
+			if err := got.Ack(); !errors.Is(err, vecnanats.ErrNotJetStream) {
+				t.Errorf("NATSMessage.Ack() error = %v, want ErrNotJetStream", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package pubsub
+
+import (
+	"errors"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+)
+
+var (
+	// ErrSubscriberClosed is returned when the subscriber stopped receiving (Close or a non retryable error)
+	ErrSubscriberClosed = errors.New("pubsub subscriber closed")
+)
+
+// PubSubMessage is a message received by PubSubSubscriber, also appended on metadata under worker name
+type PubSubMessage struct {
+	ID          string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+	PublishTime time.Time
+	// DeliveryAttempt counts deliveries of the message, only filled when the subscription has a dead letter policy
+	DeliveryAttempt int
+
+	msg *pubsub.Message
+}
+
+func newPubSubMessage(m *pubsub.Message) *PubSubMessage {
+	msg := &PubSubMessage{
+		ID:          m.ID,
+		Data:        m.Data,
+		Attributes:  m.Attributes,
+		OrderingKey: m.OrderingKey,
+		PublishTime: m.PublishTime,
+		msg:         m,
+	}
+
+	if m.DeliveryAttempt != nil {
+		msg.DeliveryAttempt = *m.DeliveryAttempt
+	}
+
+	return msg
+}
+
+// Ack acknowledges the message, it won't be delivered again
+func (m *PubSubMessage) Ack() {
+	m.msg.Ack()
+}
+
+// Nack refuses the message, which is redelivered (or dead-lettered after the subscription max delivery attempts)
+func (m *PubSubMessage) Nack() {
+	m.msg.Nack()
+}
@@ -0,0 +1,54 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type PubSubAckOpts struct {
+	// MessageMetaKey is the name of the worker running PubSubSubscriber, where its *PubSubMessage is kept on metadata
+	MessageMetaKey string
+}
+
+// PubSubAck wraps a task ending the ack deadline extension the PubSubSubscriber client keeps for the message:
+// success (or task.ErrNoData, dropping the message on purpose) acks it and failure nacks it for immediate
+// redelivery. Retries are left to the subscription, its retry policy spaces them and its dead letter policy moves
+// poison messages after max delivery attempts (without one they are redelivered forever).
+type PubSubAck[I any, O any] struct {
+	task   task.Task[I, O]
+	logger *slog.Logger
+	opts   *PubSubAckOpts
+}
+
+func NewPubSubAck[I any, O any](t task.Task[I, O], logger *slog.Logger, opts *PubSubAckOpts) *PubSubAck[I, O] {
+	a := new(PubSubAck[I, O])
+
+	a.task = t
+	a.logger = logger
+	a.opts = opts
+
+	return a
+}
+
+func (a *PubSubAck[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	resp, err := a.task.Run(ctx, input, meta, name)
+
+	msg, ok := meta[a.opts.MessageMetaKey].(*PubSubMessage)
+	if !ok {
+		return resp, err
+	}
+
+	if err != nil && !errors.Is(err, task.ErrNoData) {
+		a.logger.Warn("nacking pubsub message", "error", err, "id", msg.ID, "delivery_attempt", msg.DeliveryAttempt)
+		msg.Nack()
+
+		return resp, err
+	}
+
+	msg.Ack()
+
+	return resp, err
+}
@@ -0,0 +1,122 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnapubsub "github.com/otaviohenrique/vecna/pkg/task/pubsub"
+)
+
+type mockTask[I []byte, O string] struct {
+	err error
+}
+
+func (m *mockTask[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	return O(input), m.err
+}
+
+// waitReceipt waits the client to extend the ack deadline on receipt, a nack reaching the server first would be overridden by it
+func waitReceipt(srv *pstest.Server, id string) {
+	deadline := time.Now().Add(2 * time.Second)
+
+	for len(srv.Message(id).Modacks) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitAcks waits the server to receive the acks of a message
+func waitAcks(srv *pstest.Server, id string, want int) int {
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		acks := srv.Message(id).Acks
+		if acks >= want || time.Now().After(deadline) {
+			return acks
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPubSubAck_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		opts          vecnapubsub.PubSubAckOpts
+		deadLetter    bool
+		wantAcks      int
+		wantRedeliver bool
+	}{
+		{"It acks when the task succeeds", nil, vecnapubsub.PubSubAckOpts{}, false, 1, false},
+		{"It nacks when the task fails", errors.New("boom"), vecnapubsub.PubSubAckOpts{}, false, 0, true},
+		{"It acks when the task returns ErrNoData", task.ErrNoData, vecnapubsub.PubSubAckOpts{}, false, 1, false},
+		{"It nacks failed messages leaving them to the dead letter policy", errors.New("boom"), vecnapubsub.PubSubAckOpts{}, true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sub *pubsubpb.Subscription
+			if tt.deadLetter {
+				sub = &pubsubpb.Subscription{DeadLetterPolicy: &pubsubpb.DeadLetterPolicy{DeadLetterTopic: topic, MaxDeliveryAttempts: 5}}
+			}
+
+			srv, client := newClient(t, sub)
+			id := srv.Publish(topic, []byte("order"), nil)
+
+			s := newSubscriber(t, client)
+			meta := map[string]interface{}{}
+
+			msg, err := s.Run(context.TODO(), task.Nullable{}, meta, "pubsub")
+			if err != nil {
+				t.Fatalf("PubSubSubscriber.Run() error = %v", err)
+			}
+
+			waitReceipt(srv, id)
+
+			tt.opts.MessageMetaKey = "pubsub"
+			ack := vecnapubsub.NewPubSubAck[[]byte, string](&mockTask[[]byte, string]{err: tt.err}, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+
+			got, err := ack.Run(context.TODO(), msg.Data, meta, "process")
+			if !errors.Is(err, tt.err) || got != "order" {
+				t.Errorf("PubSubAck.Run() = %s, %v, want order, %v", got, err, tt.err)
+			}
+
+			if acks := waitAcks(srv, id, tt.wantAcks); acks != tt.wantAcks {
+				t.Errorf("PubSubAck.Run() acks = %d, want %d", acks, tt.wantAcks)
+			}
+
+			redelivered, err := s.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "pubsub")
+
+			// the emulator redelivers nacked messages asynchronously
+			for i := 0; tt.wantRedeliver && errors.Is(err, task.ErrNoData) && i < 4; i++ {
+				redelivered, err = s.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "pubsub")
+			}
+
+			if tt.wantRedeliver && (err != nil || redelivered.ID != id) {
+				t.Errorf("PubSubSubscriber.Run() after nack = %+v, %v, want message %s", redelivered, err, id)
+			}
+
+			if !tt.wantRedeliver && !errors.Is(err, task.ErrNoData) {
+				t.Errorf("PubSubSubscriber.Run() after ack error = %v, want %v", err, task.ErrNoData)
+			}
+
+			if redelivered != nil {
+				redelivered.Ack()
+			}
+		})
+	}
+}
+
+func TestPubSubAck_RunWithoutMessage(t *testing.T) {
+	ack := vecnapubsub.NewPubSubAck[[]byte, string](&mockTask[[]byte, string]{}, slog.Default(), &vecnapubsub.PubSubAckOpts{MessageMetaKey: "pubsub"})
+
+	if got, err := ack.Run(context.TODO(), []byte("data"), map[string]interface{}{}, "process"); err != nil || got != "data" {
+		t.Errorf("PubSubAck.Run() = %s, %v, want data", got, err)
+	}
+}
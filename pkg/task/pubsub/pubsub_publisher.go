@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+)
+
+type PubSubPublisherOpts struct {
+	// Topic where messages are published, its ID or full name ("projects/<project>/topics/<id>")
+	Topic string
+	// AttributeMetaKeys are copied from metadata (string or []byte values) to message attributes
+	AttributeMetaKeys []string
+	// OrderingKeyMetaKey when given, a string on metadata under it is used as ordering key (messages of the same key
+	// are delivered in order to subscriptions with message ordering enabled)
+	OrderingKeyMetaKey string
+	// DelayThreshold waits messages to fill batches, messages given by concurrent Run calls are batched together.
+	// Defaults to the client default (10ms)
+	DelayThreshold time.Duration
+	// CountThreshold publishes a batch when it has this many messages. Defaults to the client default (100)
+	CountThreshold int
+	// ByteThreshold publishes a batch when it reaches this size. Defaults to the client default (1MB)
+	ByteThreshold int
+}
+
+// PubSubPublisher is a task which publishes the input as message data, returning after the server acknowledged it.
+// The message ID given by the server is appended on metadata under worker name.
+type PubSubPublisher[I []byte, O task.Nullable] struct {
+	publisher *pubsub.Publisher
+	logger    *slog.Logger
+	opts      *PubSubPublisherOpts
+}
+
+func NewPubSubPublisher[I []byte, O task.Nullable](client *pubsub.Client, logger *slog.Logger, opts *PubSubPublisherOpts) (*PubSubPublisher[I, O], error) {
+	if opts.Topic == "" {
+		return nil, errors.New("pubsub publisher needs a topic")
+	}
+
+	p := new(PubSubPublisher[I, O])
+
+	p.logger = logger
+	p.opts = opts
+
+	p.publisher = client.Publisher(opts.Topic)
+	p.publisher.EnableMessageOrdering = opts.OrderingKeyMetaKey != ""
+
+	if opts.DelayThreshold > 0 {
+		p.publisher.PublishSettings.DelayThreshold = opts.DelayThreshold
+	}
+
+	if opts.CountThreshold > 0 {
+		p.publisher.PublishSettings.CountThreshold = opts.CountThreshold
+	}
+
+	if opts.ByteThreshold > 0 {
+		p.publisher.PublishSettings.ByteThreshold = opts.ByteThreshold
+	}
+
+	return p, nil
+}
+
+func (p *PubSubPublisher[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	msg := &pubsub.Message{Data: input}
+
+	if key, ok := metadata.String(meta[p.opts.OrderingKeyMetaKey]); ok && p.opts.OrderingKeyMetaKey != "" {
+		msg.OrderingKey = key
+	}
+
+	for _, k := range p.opts.AttributeMetaKeys {
+		if value, ok := metadata.String(meta[k]); ok {
+			if msg.Attributes == nil {
+				msg.Attributes = map[string]string{}
+			}
+
+			msg.Attributes[k] = value
+		}
+	}
+
+	id, err := p.publisher.Publish(ctx, msg).Get(ctx)
+	if err != nil {
+		p.logger.Error("error publishing pubsub message", "error", err, "topic", p.opts.Topic, "ordering_key", msg.OrderingKey)
+
+		if msg.OrderingKey != "" {
+			// publishing of a key is paused after an error, resume it so a retry can publish it again
+			p.publisher.ResumePublish(msg.OrderingKey)
+		}
+
+		return O(task.Nullable{}), err
+	}
+
+	meta[name] = id
+
+	p.logger.Debug("pubsub message published", "topic", p.opts.Topic, "id", id)
+
+	return O(task.Nullable{}), nil
+}
+
+// Close publishes buffered messages and stops the publisher
+func (p *PubSubPublisher[I, O]) Close() {
+	p.publisher.Stop()
+}
@@ -0,0 +1,77 @@
+package pubsub_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	vecnapubsub "github.com/otaviohenrique/vecna/pkg/task/pubsub"
+)
+
+func TestPubSubPublisher_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            vecnapubsub.PubSubPublisherOpts
+		meta            map[string]interface{}
+		wantAttributes  map[string]string
+		wantOrderingKey string
+	}{
+		{"It publishes messages", vecnapubsub.PubSubPublisherOpts{}, map[string]interface{}{}, nil, ""},
+		{
+			"It copies attributes from metadata",
+			vecnapubsub.PubSubPublisherOpts{AttributeMetaKeys: []string{"tenant", "trace", "missing"}},
+			map[string]interface{}{"tenant": "acme", "trace": []byte("abc")},
+			map[string]string{"tenant": "acme", "trace": "abc"},
+			"",
+		},
+		{
+			"It sets the ordering key from metadata",
+			vecnapubsub.PubSubPublisherOpts{OrderingKeyMetaKey: "customer"},
+			map[string]interface{}{"customer": "customer-1"},
+			nil,
+			"customer-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newClient(t, nil)
+
+			tt.opts.Topic = topic
+			p, err := vecnapubsub.NewPubSubPublisher(client, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+			if err != nil {
+				t.Fatalf("NewPubSubPublisher() error = %v", err)
+			}
+			defer p.Close()
+
+			if _, err := p.Run(context.TODO(), []byte("order"), tt.meta, "publish"); err != nil {
+				t.Fatalf("PubSubPublisher.Run() error = %v", err)
+			}
+
+			id, _ := tt.meta["publish"].(string)
+
+			msg := srv.Message(id)
+			if msg == nil {
+				t.Fatalf("PubSubPublisher.Run() meta id = %v, not published", tt.meta["publish"])
+			}
+
+			if string(msg.Data) != "order" || len(msg.Attributes) != len(tt.wantAttributes) || msg.OrderingKey != tt.wantOrderingKey {
+				t.Errorf("PubSubPublisher.Run() published %+v, want attributes %v ordering key %s", msg, tt.wantAttributes, tt.wantOrderingKey)
+			}
+
+			for k, v := range tt.wantAttributes {
+				if msg.Attributes[k] != v {
+					t.Errorf("PubSubPublisher.Run() attribute %s = %s, want %s", k, msg.Attributes[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPubSubPublisher_WithoutTopic(t *testing.T) {
+	_, client := newClient(t, nil)
+
+	if _, err := vecnapubsub.NewPubSubPublisher(client, slog.Default(), &vecnapubsub.PubSubPublisherOpts{}); err == nil {
+		t.Errorf("NewPubSubPublisher() error = nil, want error")
+	}
+}
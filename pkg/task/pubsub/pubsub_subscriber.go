@@ -0,0 +1,142 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	DefaultMaxOutstandingMessages = 1
+)
+
+type PubSubSubscriberOpts struct {
+	// Subscription consumed, its ID or full name ("projects/<project>/subscriptions/<id>")
+	Subscription string
+	// MaxOutstandingMessages received and not acknowledged yet. Match it to the number of workers (goroutines) of the pool:
+	// lower leaves workers idle, higher keeps messages waiting on the client where other subscribers can't take them.
+	// Defaults to DefaultMaxOutstandingMessages
+	MaxOutstandingMessages int
+	// MaxExtension is how long the ack deadline of outstanding messages is extended. Defaults to the client default (60m)
+	MaxExtension time.Duration
+	// NumGoroutines is the number of streams pulling messages. Defaults to the client default (1)
+	NumGoroutines int
+	// ShutdownOptions configures how Close waits outstanding messages. Defaults to waiting them to be acknowledged
+	ShutdownOptions *pubsub.ShutdownOptions
+	// PollTimeout is how long Run waits for a message before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+}
+
+// PubSubSubscriber is a source task (to be used with ProducerWorker) which receives messages of a Google Pub/Sub subscription.
+// Every Run() returns one message, also appended on metadata under worker name, to be acknowledged with PubSubAck
+// (or PubSubMessage Ack/Nack). Ack deadlines of outstanding messages are extended by the client until they are acknowledged.
+// When there is no message to emit it returns task.ErrNoData.
+type PubSubSubscriber[I task.Nullable, O *PubSubMessage] struct {
+	sub      *pubsub.Subscriber
+	logger   *slog.Logger
+	opts     *PubSubSubscriberOpts
+	messages chan *pubsub.Message
+	cancel   context.CancelFunc
+	done     chan struct{}
+	err      error
+}
+
+// NewPubSubSubscriber creates a PubSubSubscriber and starts receiving the subscription
+func NewPubSubSubscriber[I task.Nullable, O *PubSubMessage](client *pubsub.Client, logger *slog.Logger, opts *PubSubSubscriberOpts) (*PubSubSubscriber[I, O], error) {
+	if opts.Subscription == "" {
+		return nil, errors.New("pubsub subscriber needs a subscription")
+	}
+
+	s := new(PubSubSubscriber[I, O])
+
+	s.logger = logger
+	s.opts = opts
+	s.messages = make(chan *pubsub.Message)
+	s.done = make(chan struct{})
+
+	if s.opts.MaxOutstandingMessages == 0 {
+		s.opts.MaxOutstandingMessages = DefaultMaxOutstandingMessages
+	}
+
+	if s.opts.PollTimeout == 0 {
+		s.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	s.sub = client.Subscriber(opts.Subscription)
+	s.sub.ReceiveSettings.MaxOutstandingMessages = s.opts.MaxOutstandingMessages
+	s.sub.ReceiveSettings.ShutdownOptions = s.opts.ShutdownOptions
+
+	if s.opts.MaxExtension > 0 {
+		s.sub.ReceiveSettings.MaxExtension = s.opts.MaxExtension
+	}
+
+	if s.opts.NumGoroutines > 0 {
+		s.sub.ReceiveSettings.NumGoroutines = s.opts.NumGoroutines
+	}
+
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+
+	go s.receive(ctx)
+
+	return s, nil
+}
+
+// receive hands every message to a Run call, until Close or a non retryable error
+func (s *PubSubSubscriber[I, O]) receive(ctx context.Context) {
+	defer close(s.done)
+
+	s.err = s.sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		select {
+		case s.messages <- msg:
+		case <-ctx.Done():
+			msg.Nack()
+		}
+	})
+
+	if s.err != nil {
+		s.logger.Error("pubsub subscriber stopped", "error", s.err, "subscription", s.opts.Subscription)
+	}
+}
+
+func (s *PubSubSubscriber[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	timer := time.NewTimer(s.opts.PollTimeout)
+	defer timer.Stop()
+
+	select {
+	case m := <-s.messages:
+		msg := newPubSubMessage(m)
+		meta[name] = msg
+
+		s.logger.Debug("pubsub message received", "subscription", s.opts.Subscription, "id", msg.ID)
+
+		return msg, nil
+	case <-s.done:
+		if s.err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSubscriberClosed, s.err)
+		}
+
+		return nil, ErrSubscriberClosed
+	case <-timer.C:
+		return nil, task.ErrNoData
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops receiving, waiting outstanding messages as configured by ShutdownOptions (or ctx to be done)
+func (s *PubSubSubscriber[I, O]) Close(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,149 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnapubsub "github.com/otaviohenrique/vecna/pkg/task/pubsub"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	topic        = "projects/vecna/topics/orders"
+	subscription = "projects/vecna/subscriptions/orders"
+)
+
+// newClient starts a fake Pub/Sub server with the orders topic and subscription, connecting a client to it
+func newClient(t *testing.T, sub *pubsubpb.Subscription) (*pstest.Server, *pubsub.Client) {
+	t.Helper()
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+
+	client, err := pubsub.NewClient(context.TODO(), "vecna", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("pubsub.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if _, err := client.TopicAdminClient.CreateTopic(context.TODO(), &pubsubpb.Topic{Name: topic}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	if sub == nil {
+		sub = &pubsubpb.Subscription{}
+	}
+
+	sub.Name = subscription
+	sub.Topic = topic
+	sub.AckDeadlineSeconds = 10
+
+	if _, err := client.SubscriptionAdminClient.CreateSubscription(context.TODO(), sub); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+
+	return srv, client
+}
+
+func newSubscriber(t *testing.T, client *pubsub.Client) *vecnapubsub.PubSubSubscriber[task.Nullable, *vecnapubsub.PubSubMessage] {
+	t.Helper()
+
+	s, err := vecnapubsub.NewPubSubSubscriber(client, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnapubsub.PubSubSubscriberOpts{
+		Subscription: subscription,
+		PollTimeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewPubSubSubscriber() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+		defer cancel()
+
+		s.Close(ctx)
+	})
+
+	return s
+}
+
+func TestPubSubSubscriber_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		attributes  map[string]string
+		orderingKey string
+	}{
+		{"It receives messages", []byte("order"), nil, ""},
+		{"It receives attributes", []byte("order"), map[string]string{"tenant": "acme"}, ""},
+		{"It receives ordering keys", []byte("order"), nil, "customer-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newClient(t, &pubsubpb.Subscription{EnableMessageOrdering: tt.orderingKey != ""})
+			id := srv.PublishOrdered(topic, tt.data, tt.attributes, tt.orderingKey)
+
+			s := newSubscriber(t, client)
+			meta := map[string]interface{}{}
+
+			msg, err := s.Run(context.TODO(), task.Nullable{}, meta, "pubsub")
+			if err != nil {
+				t.Fatalf("PubSubSubscriber.Run() error = %v", err)
+			}
+
+			if msg.ID != id || string(msg.Data) != string(tt.data) || msg.Attributes["tenant"] != tt.attributes["tenant"] || msg.OrderingKey != tt.orderingKey {
+				t.Errorf("PubSubSubscriber.Run() = %+v, want id %s data %s attributes %v ordering key %s", msg, id, tt.data, tt.attributes, tt.orderingKey)
+			}
+
+			if meta["pubsub"] != msg {
+				t.Errorf("PubSubSubscriber.Run() meta = %v, want %v", meta["pubsub"], msg)
+			}
+
+			msg.Ack()
+		})
+	}
+}
+
+func TestPubSubSubscriber_RunWithoutMessages(t *testing.T) {
+	_, client := newClient(t, nil)
+	s := newSubscriber(t, client)
+
+	if _, err := s.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "pubsub"); !errors.Is(err, task.ErrNoData) {
+		t.Errorf("PubSubSubscriber.Run() error = %v, want %v", err, task.ErrNoData)
+	}
+}
+
+func TestPubSubSubscriber_RunAfterClose(t *testing.T) {
+	_, client := newClient(t, nil)
+	s := newSubscriber(t, client)
+
+	if err := s.Close(context.TODO()); err != nil {
+		t.Fatalf("PubSubSubscriber.Close() error = %v", err)
+	}
+
+	if _, err := s.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "pubsub"); !errors.Is(err, vecnapubsub.ErrSubscriberClosed) {
+		t.Errorf("PubSubSubscriber.Run() error = %v, want %v", err, vecnapubsub.ErrSubscriberClosed)
+	}
+}
+
+func TestNewPubSubSubscriber_WithoutSubscription(t *testing.T) {
+	_, client := newClient(t, nil)
+
+	if _, err := vecnapubsub.NewPubSubSubscriber(client, slog.Default(), &vecnapubsub.PubSubSubscriberOpts{}); err == nil {
+		t.Errorf("NewPubSubSubscriber() error = nil, want error")
+	}
+}
@@ -0,0 +1,20 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamConsumerAPI is the subset of the Redis client used by RedisStreamConsumer. Satisfied by *redis.Client and *redis.ClusterClient
+type StreamConsumerAPI interface {
+	XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redis.StatusCmd
+	XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) *redis.XStreamSliceCmd
+	XAutoClaim(ctx context.Context, a *redis.XAutoClaimArgs) *redis.XAutoClaimCmd
+	XAck(ctx context.Context, stream, group string, ids ...string) *redis.IntCmd
+}
+
+// StreamProducerAPI is the subset of the Redis client used by RedisStreamProducer. Satisfied by *redis.Client and *redis.ClusterClient
+type StreamProducerAPI interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+}
@@ -0,0 +1,16 @@
+package redis
+
+// DefaultDataField is the stream entry field holding message data
+const DefaultDataField = "data"
+
+// RedisStreamMessage is a stream entry read by RedisStreamConsumer, also appended on metadata under worker name
+type RedisStreamMessage struct {
+	Stream string
+	ID     string
+	// Data is the value of the data field (see RedisStreamConsumerOpts.DataField)
+	Data []byte
+	// Values are every field of the entry
+	Values map[string]interface{}
+	// Reclaimed is true when the entry was claimed from a consumer which didn't acknowledge it in time
+	Reclaimed bool
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+// Acker acknowledges stream entries, implemented by RedisStreamConsumer
+type Acker interface {
+	Ack(context.Context, *RedisStreamMessage) error
+}
+
+type RedisStreamAckOpts struct {
+	// MessageMetaKey is the name of the worker running RedisStreamConsumer, where its *RedisStreamMessage is kept on metadata
+	MessageMetaKey string
+}
+
+// RedisStreamAck wraps a task removing the processed entry from the consumer group pending list (XACK) once the
+// task succeeds (or returns task.ErrNoData, dropping the entry on purpose). Streams have no negative ack, a failed entry just stays pending until a RedisStreamConsumer
+// claims it after ClaimMinIdle. Without a *RedisStreamMessage on metadata nothing is acked.
+type RedisStreamAck[I any, O any] struct {
+	task   task.Task[I, O]
+	acker  Acker
+	logger *slog.Logger
+	opts   *RedisStreamAckOpts
+}
+
+func NewRedisStreamAck[I any, O any](t task.Task[I, O], acker Acker, logger *slog.Logger, opts *RedisStreamAckOpts) *RedisStreamAck[I, O] {
+	a := new(RedisStreamAck[I, O])
+
+	a.task = t
+	a.acker = acker
+	a.logger = logger
+	a.opts = opts
+
+	return a
+}
+
+func (a *RedisStreamAck[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	resp, err := a.task.Run(ctx, input, meta, name)
+
+	msg, ok := meta[a.opts.MessageMetaKey].(*RedisStreamMessage)
+	if !ok || (err != nil && !errors.Is(err, task.ErrNoData)) {
+		return resp, err
+	}
+
+	if ackErr := a.acker.Ack(ctx, msg); ackErr != nil {
+		a.logger.Error("error acking redis stream entry", "error", ackErr, "stream", msg.Stream, "id", msg.ID)
+
+		return resp, ackErr
+	}
+
+	return resp, err
+}
@@ -0,0 +1,58 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnaredis "github.com/otaviohenrique/vecna/pkg/task/redis"
+)
+
+type mockTask[I []byte, O string] struct {
+	err error
+}
+
+func (m *mockTask[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	return O(input), m.err
+}
+
+func TestRedisStreamAck_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantPending int64
+	}{
+		{"It acks when the task succeeds", nil, 0},
+		{"It keeps the entry pending when the task fails", errors.New("boom"), 1},
+		{"It acks when the task returns ErrNoData", task.ErrNoData, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t)
+			addEntries(t, client, 1)
+
+			c := newConsumer(t, client, "consumer-1", 0)
+			meta := map[string]interface{}{}
+
+			msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "redis")
+			if err != nil {
+				t.Fatalf("RedisStreamConsumer.Run() error = %v", err)
+			}
+
+			ack := vecnaredis.NewRedisStreamAck[[]byte, string](&mockTask[[]byte, string]{err: tt.err}, c,
+				slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaredis.RedisStreamAckOpts{MessageMetaKey: "redis"})
+
+			got, err := ack.Run(context.TODO(), msg.Data, meta, "process")
+			if !errors.Is(err, tt.err) || got != "event-0" {
+				t.Errorf("RedisStreamAck.Run() = %s, %v, want event-0, %v", got, err, tt.err)
+			}
+
+			if got := pending(t, client); got != tt.wantPending {
+				t.Errorf("pending entries = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultBlock = time.Second
+	DefaultCount = 10
+)
+
+type RedisStreamConsumerOpts struct {
+	// Stream consumed
+	Stream string
+	// Group of consumers, entries are balanced between its consumers
+	Group string
+	// Consumer name, unique inside the group. Defaults to hostname-pid
+	Consumer string
+	// StartID where a new group starts reading: "$" only new entries, "0" the whole stream. Defaults to "$".
+	// The group (and stream) are created when they don't exist
+	StartID string
+	// Block is how long Run waits for entries before returning task.ErrNoData. Defaults to DefaultBlock
+	Block time.Duration
+	// Count of entries read at once, they are buffered and emitted one per Run. Defaults to DefaultCount
+	Count int64
+	// ClaimMinIdle reclaims entries read by any consumer of the group and not acknowledged for this long
+	// (ex. a crashed consumer). Disabled when zero
+	ClaimMinIdle time.Duration
+	// ClaimInterval between reclaims. Defaults to ClaimMinIdle
+	ClaimInterval time.Duration
+	// DataField holding message data. Defaults to DefaultDataField
+	DataField string
+}
+
+// RedisStreamConsumer is a source task (to be used with ProducerWorker) which reads a stream as a member of a consumer group (XREADGROUP).
+// Every Run() returns one entry, also appended on metadata under worker name, to be acknowledged with RedisStreamAck (XACK).
+// Entries not acknowledged stay pending and, when ClaimMinIdle is given, are reclaimed (XAUTOCLAIM) and returned again.
+// When there is no entry to emit it returns task.ErrNoData.
+type RedisStreamConsumer[I task.Nullable, O *RedisStreamMessage] struct {
+	client StreamConsumerAPI
+	logger *slog.Logger
+	opts   *RedisStreamConsumerOpts
+
+	mu         sync.Mutex
+	buffer     []*RedisStreamMessage
+	groupReady bool
+	claimStart string
+	claimedAt  time.Time
+}
+
+func NewRedisStreamConsumer[I task.Nullable, O *RedisStreamMessage](client StreamConsumerAPI, logger *slog.Logger, opts *RedisStreamConsumerOpts) (*RedisStreamConsumer[I, O], error) {
+	if opts.Stream == "" || opts.Group == "" {
+		return nil, errors.New("redis stream consumer needs a stream and a group")
+	}
+
+	c := new(RedisStreamConsumer[I, O])
+
+	c.client = client
+	c.logger = logger
+	c.opts = opts
+	c.claimStart = "0-0"
+
+	if c.opts.Consumer == "" {
+		hostname, _ := os.Hostname()
+		c.opts.Consumer = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	if c.opts.StartID == "" {
+		c.opts.StartID = "$"
+	}
+
+	if c.opts.Block == 0 {
+		c.opts.Block = DefaultBlock
+	}
+
+	if c.opts.Count == 0 {
+		c.opts.Count = DefaultCount
+	}
+
+	if c.opts.ClaimInterval == 0 {
+		c.opts.ClaimInterval = c.opts.ClaimMinIdle
+	}
+
+	if c.opts.DataField == "" {
+		c.opts.DataField = DefaultDataField
+	}
+
+	return c, nil
+}
+
+func (c *RedisStreamConsumer[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.buffer) == 0 {
+		if err := c.fill(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.buffer) == 0 {
+		return nil, task.ErrNoData
+	}
+
+	msg := c.buffer[0]
+	c.buffer = c.buffer[1:]
+
+	meta[name] = msg
+
+	return msg, nil
+}
+
+// fill buffers reclaimed entries, or new ones when there is nothing to reclaim
+func (c *RedisStreamConsumer[I, O]) fill(ctx context.Context) error {
+	if err := c.createGroup(ctx); err != nil {
+		return err
+	}
+
+	if c.opts.ClaimMinIdle > 0 && time.Since(c.claimedAt) >= c.opts.ClaimInterval {
+		if err := c.reclaim(ctx); err != nil {
+			return err
+		}
+
+		if len(c.buffer) > 0 {
+			return nil
+		}
+	}
+
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.opts.Group,
+		Consumer: c.opts.Consumer,
+		Streams:  []string{c.opts.Stream, ">"},
+		Count:    c.opts.Count,
+		Block:    c.opts.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	if err != nil {
+		c.logger.Error("error reading redis stream", "error", err, "stream", c.opts.Stream, "group", c.opts.Group)
+
+		return err
+	}
+
+	for _, stream := range streams {
+		c.buffer = append(c.buffer, c.newMessages(stream.Messages, false)...)
+	}
+
+	return nil
+}
+
+// reclaim claims entries idle for more than ClaimMinIdle, continuing from the last cursor until the whole pending list is scanned
+func (c *RedisStreamConsumer[I, O]) reclaim(ctx context.Context) error {
+	msgs, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   c.opts.Stream,
+		Group:    c.opts.Group,
+		Consumer: c.opts.Consumer,
+		MinIdle:  c.opts.ClaimMinIdle,
+		Start:    c.claimStart,
+		Count:    c.opts.Count,
+	}).Result()
+	if err != nil {
+		c.logger.Error("error reclaiming redis stream entries", "error", err, "stream", c.opts.Stream, "group", c.opts.Group)
+
+		return err
+	}
+
+	if next == "0-0" {
+		c.claimedAt = time.Now()
+	}
+
+	c.claimStart = next
+
+	if len(msgs) > 0 {
+		c.logger.Info("redis stream entries reclaimed", "stream", c.opts.Stream, "group", c.opts.Group, "count", len(msgs))
+	}
+
+	c.buffer = append(c.buffer, c.newMessages(msgs, true)...)
+
+	return nil
+}
+
+func (c *RedisStreamConsumer[I, O]) createGroup(ctx context.Context) error {
+	if c.groupReady {
+		return nil
+	}
+
+	err := c.client.XGroupCreateMkStream(ctx, c.opts.Stream, c.opts.Group, c.opts.StartID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		c.logger.Error("error creating redis stream group", "error", err, "stream", c.opts.Stream, "group", c.opts.Group)
+
+		return err
+	}
+
+	c.groupReady = true
+
+	return nil
+}
+
+func (c *RedisStreamConsumer[I, O]) newMessages(entries []redis.XMessage, reclaimed bool) []*RedisStreamMessage {
+	msgs := make([]*RedisStreamMessage, 0, len(entries))
+
+	for _, entry := range entries {
+		data, _ := metadata.Bytes(entry.Values[c.opts.DataField])
+
+		msgs = append(msgs, &RedisStreamMessage{
+			Stream:    c.opts.Stream,
+			ID:        entry.ID,
+			Data:      data,
+			Values:    entry.Values,
+			Reclaimed: reclaimed,
+		})
+	}
+
+	return msgs
+}
+
+// Ack acknowledges an entry returned by Run (XACK), removing it from the group pending list
+func (c *RedisStreamConsumer[I, O]) Ack(ctx context.Context, msg *RedisStreamMessage) error {
+	return c.client.XAck(ctx, msg.Stream, c.opts.Group, msg.ID).Err()
+}
@@ -0,0 +1,134 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	vecnaredis "github.com/otaviohenrique/vecna/pkg/task/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func addEntries(t *testing.T, client *redis.Client, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		if err := client.XAdd(context.TODO(), &redis.XAddArgs{Stream: "events", Values: []interface{}{"data", fmt.Sprintf("event-%d", i), "source", "test"}}).Err(); err != nil {
+			t.Fatalf("XAdd() error = %v", err)
+		}
+	}
+}
+
+func newConsumer(t *testing.T, client *redis.Client, consumer string, claimMinIdle time.Duration) *vecnaredis.RedisStreamConsumer[task.Nullable, *vecnaredis.RedisStreamMessage] {
+	t.Helper()
+
+	c, err := vecnaredis.NewRedisStreamConsumer(client, slog.New(slog.NewTextHandler(os.Stdout, nil)), &vecnaredis.RedisStreamConsumerOpts{
+		Stream:       "events",
+		Group:        "vecna",
+		Consumer:     consumer,
+		StartID:      "0",
+		Block:        50 * time.Millisecond,
+		ClaimMinIdle: claimMinIdle,
+	})
+	if err != nil {
+		t.Fatalf("NewRedisStreamConsumer() error = %v", err)
+	}
+
+	return c
+}
+
+func pending(t *testing.T, client *redis.Client) int64 {
+	t.Helper()
+
+	p, err := client.XPending(context.TODO(), "events", "vecna").Result()
+	if err != nil {
+		t.Fatalf("XPending() error = %v", err)
+	}
+
+	return p.Count
+}
+
+func TestRedisStreamConsumer_Run(t *testing.T) {
+	client := newClient(t)
+	addEntries(t, client, 3)
+
+	c := newConsumer(t, client, "consumer-1", 0)
+
+	for i := 0; i < 3; i++ {
+		meta := map[string]interface{}{}
+
+		msg, err := c.Run(context.TODO(), task.Nullable{}, meta, "redis")
+		if err != nil {
+			t.Fatalf("RedisStreamConsumer.Run() error = %v", err)
+		}
+
+		if string(msg.Data) != fmt.Sprintf("event-%d", i) || msg.Values["source"] != "test" || msg.Reclaimed || meta["redis"] != msg {
+			t.Errorf("RedisStreamConsumer.Run() = %+v, want event-%d", msg, i)
+		}
+
+		if err := c.Ack(context.TODO(), msg); err != nil {
+			t.Errorf("RedisStreamConsumer.Ack() error = %v", err)
+		}
+	}
+
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "redis"); !errors.Is(err, task.ErrNoData) {
+		t.Errorf("RedisStreamConsumer.Run() error = %v, want ErrNoData", err)
+	}
+
+	if got := pending(t, client); got != 0 {
+		t.Errorf("pending entries = %d, want 0", got)
+	}
+}
+
+func TestRedisStreamConsumer_RunReclaim(t *testing.T) {
+	client := newClient(t)
+	addEntries(t, client, 1)
+
+	// crashed reads an entry and never acknowledges it
+	crashed := newConsumer(t, client, "crashed", 0)
+	if _, err := crashed.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "redis"); err != nil {
+		t.Fatalf("RedisStreamConsumer.Run() error = %v", err)
+	}
+
+	c := newConsumer(t, client, "consumer-1", 50*time.Millisecond)
+
+	if _, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "redis"); !errors.Is(err, task.ErrNoData) {
+		t.Errorf("RedisStreamConsumer.Run() before min idle error = %v, want ErrNoData", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	msg, err := c.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "redis")
+	if err != nil || string(msg.Data) != "event-0" || !msg.Reclaimed {
+		t.Fatalf("RedisStreamConsumer.Run() = %+v, %v, want reclaimed event-0", msg, err)
+	}
+
+	if err := c.Ack(context.TODO(), msg); err != nil {
+		t.Errorf("RedisStreamConsumer.Ack() error = %v", err)
+	}
+
+	if got := pending(t, client); got != 0 {
+		t.Errorf("pending entries = %d, want 0", got)
+	}
+}
+
+func TestNewRedisStreamConsumer_Invalid(t *testing.T) {
+	if _, err := vecnaredis.NewRedisStreamConsumer(newClient(t), slog.Default(), &vecnaredis.RedisStreamConsumerOpts{Stream: "events"}); err == nil {
+		t.Errorf("NewRedisStreamConsumer() without group error = nil, want error")
+	}
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/metadata"
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisStreamProducerOpts struct {
+	// Stream where entries are added
+	Stream string
+	// StreamMetaKey when given, a string on metadata under it overrides Stream
+	StreamMetaKey string
+	// DataField holding the input. Defaults to DefaultDataField
+	DataField string
+	// FieldMetaKeys are copied from metadata (string or []byte values) to entry fields
+	FieldMetaKeys []string
+	// MaxLen trims the stream to about (see Approx) this many entries on every add. No trimming when zero
+	MaxLen int64
+	// Approx trims with "~", letting Redis keep a few more entries than MaxLen, which is much cheaper
+	Approx bool
+}
+
+// RedisStreamProducer is a task which adds the input to a stream (XADD), appending the entry ID on metadata under worker name.
+type RedisStreamProducer[I []byte, O task.Nullable] struct {
+	client StreamProducerAPI
+	logger *slog.Logger
+	opts   *RedisStreamProducerOpts
+}
+
+func NewRedisStreamProducer[I []byte, O task.Nullable](client StreamProducerAPI, logger *slog.Logger, opts *RedisStreamProducerOpts) (*RedisStreamProducer[I, O], error) {
+	if opts.Stream == "" && opts.StreamMetaKey == "" {
+		return nil, errors.New("redis stream producer needs a stream")
+	}
+
+	p := new(RedisStreamProducer[I, O])
+
+	p.client = client
+	p.logger = logger
+	p.opts = opts
+
+	if p.opts.DataField == "" {
+		p.opts.DataField = DefaultDataField
+	}
+
+	return p, nil
+}
+
+func (p *RedisStreamProducer[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	stream := p.opts.Stream
+	if s, ok := meta[p.opts.StreamMetaKey].(string); ok && p.opts.StreamMetaKey != "" {
+		stream = s
+	}
+
+	values := []interface{}{p.opts.DataField, []byte(input)}
+	for _, k := range p.opts.FieldMetaKeys {
+		if value, ok := metadata.Bytes(meta[k]); ok {
+			values = append(values, k, value)
+		}
+	}
+
+	id, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: p.opts.MaxLen,
+		Approx: p.opts.Approx,
+		Values: values,
+	}).Result()
+	if err != nil {
+		p.logger.Error("error adding redis stream entry", "error", err, "stream", stream)
+
+		return O(task.Nullable{}), err
+	}
+
+	meta[name] = id
+
+	p.logger.Debug("redis stream entry added", "stream", stream, "id", id)
+
+	return O(task.Nullable{}), nil
+}
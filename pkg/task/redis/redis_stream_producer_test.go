@@ -0,0 +1,74 @@
+package redis_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	vecnaredis "github.com/otaviohenrique/vecna/pkg/task/redis"
+)
+
+func TestRedisStreamProducer_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       vecnaredis.RedisStreamProducerOpts
+		meta       map[string]interface{}
+		runs       int
+		wantStream string
+		wantLen    int64
+		wantFields map[string]interface{}
+	}{
+		{"It adds entries", vecnaredis.RedisStreamProducerOpts{Stream: "events"}, map[string]interface{}{}, 3, "events", 3,
+			map[string]interface{}{"data": "payload"}},
+		{"It trims the stream", vecnaredis.RedisStreamProducerOpts{Stream: "events", MaxLen: 2}, map[string]interface{}{}, 5, "events", 2,
+			map[string]interface{}{"data": "payload"}},
+		{"It takes stream and fields from metadata", vecnaredis.RedisStreamProducerOpts{Stream: "events", StreamMetaKey: "stream", DataField: "body", FieldMetaKeys: []string{"trace_id", "missing"}},
+			map[string]interface{}{"stream": "audit", "trace_id": []byte("abc")}, 1, "audit", 1, map[string]interface{}{"body": "payload", "trace_id": "abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t)
+
+			p, err := vecnaredis.NewRedisStreamProducer(client, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+			if err != nil {
+				t.Fatalf("NewRedisStreamProducer() error = %v", err)
+			}
+
+			for i := 0; i < tt.runs; i++ {
+				if _, err := p.Run(context.TODO(), []byte("payload"), tt.meta, "producer"); err != nil {
+					t.Fatalf("RedisStreamProducer.Run() error = %v", err)
+				}
+			}
+
+			if got, _ := client.XLen(context.TODO(), tt.wantStream).Result(); got != tt.wantLen {
+				t.Errorf("stream length = %d, want %d", got, tt.wantLen)
+			}
+
+			entries, err := client.XRevRangeN(context.TODO(), tt.wantStream, "+", "-", 1).Result()
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("XRevRangeN() = %v, %v", entries, err)
+			}
+
+			if tt.meta["producer"] != entries[0].ID {
+				t.Errorf("RedisStreamProducer.Run() metadata = %v, want last id %s", tt.meta["producer"], entries[0].ID)
+			}
+
+			for k, want := range tt.wantFields {
+				if entries[0].Values[k] != want {
+					t.Errorf("entry field %s = %v, want %v", k, entries[0].Values[k], want)
+				}
+			}
+
+			if len(entries[0].Values) != len(tt.wantFields) {
+				t.Errorf("entry fields = %v, want %v", entries[0].Values, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestNewRedisStreamProducer_NoStream(t *testing.T) {
+	if _, err := vecnaredis.NewRedisStreamProducer(newClient(t), slog.Default(), &vecnaredis.RedisStreamProducerOpts{}); err == nil {
+		t.Errorf("NewRedisStreamProducer() error = nil, want error")
+	}
+}
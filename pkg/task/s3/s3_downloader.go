@@ -2,12 +2,23 @@ package s3
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/compression"
+)
+
+var (
+	// ErrNotModified is returned when a conditional GET matches (object not modified).
+	// It wraps task.ErrNoData, so workers skip the message without reporting a task error
+	ErrNotModified = fmt.Errorf("object not modified: %w", task.ErrNoData)
 )
 
 // S3Downloader is a generic task capable of download a object from AWS S3 based on a given path and bucket name
@@ -15,35 +26,72 @@ import (
 // adaptFn will be called with input on method Run()
 type S3Downloader[I string, O *S3DownloaderOutput] struct {
 	// S3 AWS client to be used
-	client s3iface.S3API
+	client GetObjectAPI
 	// Bucket name where all objects will be downloaded
 	bucketName string
 	logger     *slog.Logger
+	opts       *S3DownloaderOpts
+}
+
+type S3DownloaderOpts struct {
+	// Range downloads only part of the object (HTTP Range header, ex. "bytes=0-1023")
+	Range string
+	// RangeMetaKey when given, a string on metadata under it overrides Range (usually the name of the worker computing it)
+	RangeMetaKey string
+	// IfNoneMatch downloads the object only if its ETag differs, ErrNotModified is returned otherwise
+	IfNoneMatch string
+	// IfNoneMatchMetaKey when given, a string on metadata under it overrides IfNoneMatch
+	IfNoneMatchMetaKey string
+	// IfModifiedSince downloads the object only if modified after it, ErrNotModified is returned otherwise
+	IfModifiedSince time.Time
+	// IfModifiedSinceMetaKey when given, a time.Time on metadata under it overrides IfModifiedSince
+	IfModifiedSinceMetaKey string
+	// DetectCompression fills CompressionType from ContentEncoding/ContentType, also appending it on metadata under worker name
+	// to be read by a Decompressor with compression.METADATA_TYPE (see DecompressorOpts.CompressionTypeMetaKey)
+	DetectCompression bool
 }
 
 type S3DownloaderOutput struct {
-	Data []byte
+	Data            []byte
+	ContentType     string
+	ContentEncoding string
+	ContentLength   int64
+	ContentRange    string
+	ETag            string
+	LastModified    time.Time
+	// Metadata is the user defined metadata of the object (x-amz-meta-*)
+	Metadata map[string]string
+	// CompressionType detected when DetectCompression is enabled ("" when not compressed or unknown)
+	CompressionType string
 }
 
-func NewS3Downloader[I string, O *S3DownloaderOutput](client s3iface.S3API, bucketName string, logger *slog.Logger) *S3Downloader[I, O] {
+// NewS3Downloader creates a S3Downloader. opts is optional (nil)
+func NewS3Downloader[I string, O *S3DownloaderOutput](client GetObjectAPI, bucketName string, logger *slog.Logger, opts *S3DownloaderOpts) *S3Downloader[I, O] {
 	s := new(S3Downloader[I, O])
 
 	s.client = client
 	s.bucketName = bucketName
 	s.logger = logger
+	s.opts = opts
+
+	if s.opts == nil {
+		s.opts = &S3DownloaderOpts{}
+	}
 
 	return s
 }
 
-// The return from Run() will be a S3DownloaderOutput (containing object as []data) and Metadata
-// No metadata will be added.
-func (s *S3Downloader[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
-	result, err := s.client.GetObject(&s3.GetObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(string(input)),
-	})
+// The return from Run() will be a S3DownloaderOutput (containing object as []data and its attributes)
+// When DetectCompression is enabled, the detected compression type is added to metadata under worker name.
+func (s *S3Downloader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	result, err := s.client.GetObject(ctx, s.getObjectInput(string(input), meta))
 
 	if err != nil {
+		if isNotModified(err) {
+			s.logger.Debug("object not modified", "path", input)
+			return nil, fmt.Errorf("%w: %s", ErrNotModified, input)
+		}
+
 		s.logger.Error("error downloading object", "error", err, "path", input)
 		return nil, err
 	}
@@ -59,5 +107,66 @@ func (s *S3Downloader[I, O]) Run(_ context.Context, input I, meta map[string]int
 
 	s.logger.Debug("object downloaded successfully", "path", input)
 
-	return &S3DownloaderOutput{Data: body}, nil
+	out := &S3DownloaderOutput{
+		Data:            body,
+		ContentType:     aws.ToString(result.ContentType),
+		ContentEncoding: aws.ToString(result.ContentEncoding),
+		ContentLength:   aws.ToInt64(result.ContentLength),
+		ContentRange:    aws.ToString(result.ContentRange),
+		ETag:            aws.ToString(result.ETag),
+		LastModified:    aws.ToTime(result.LastModified),
+		Metadata:        result.Metadata,
+	}
+
+	if s.opts.DetectCompression {
+		out.CompressionType = compression.DetectHeaders(out.ContentEncoding, out.ContentType)
+
+		if out.CompressionType != "" {
+			meta[name] = out.CompressionType
+		}
+	}
+
+	return out, nil
+}
+
+func (s *S3Downloader[I, O]) getObjectInput(key string, meta map[string]interface{}) *s3.GetObjectInput {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}
+
+	rng := s.opts.Range
+	if v, ok := meta[s.opts.RangeMetaKey].(string); ok && s.opts.RangeMetaKey != "" {
+		rng = v
+	}
+
+	ifNoneMatch := s.opts.IfNoneMatch
+	if v, ok := meta[s.opts.IfNoneMatchMetaKey].(string); ok && s.opts.IfNoneMatchMetaKey != "" {
+		ifNoneMatch = v
+	}
+
+	ifModifiedSince := s.opts.IfModifiedSince
+	if v, ok := meta[s.opts.IfModifiedSinceMetaKey].(time.Time); ok && s.opts.IfModifiedSinceMetaKey != "" {
+		ifModifiedSince = v
+	}
+
+	if rng != "" {
+		input.Range = aws.String(rng)
+	}
+
+	if ifNoneMatch != "" {
+		input.IfNoneMatch = aws.String(ifNoneMatch)
+	}
+
+	if !ifModifiedSince.IsZero() {
+		input.IfModifiedSince = aws.Time(ifModifiedSince)
+	}
+
+	return input
+}
+
+func isNotModified(err error) bool {
+	var respErr interface{ HTTPStatusCode() int }
+
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotModified
 }
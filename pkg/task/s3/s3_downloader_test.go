@@ -6,12 +6,16 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
-	awsS3 "github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/otaviohenrique/vecna/pkg/task"
 	"github.com/otaviohenrique/vecna/pkg/task/s3"
 )
 
@@ -24,19 +28,29 @@ type S3TaskInput struct {
 }
 
 type mockS3Client struct {
-	s3iface.S3API
 	calledWith       []awsS3.GetObjectInput
 	ExpectedResponse string
 	BucketName       string
 	WantErr          bool
+	// Output is used as base of GetObject response when given
+	Output *awsS3.GetObjectOutput
+	// Err is returned by GetObject when given
+	Err error
 }
 
-func (m *mockS3Client) GetObject(input *awsS3.GetObjectInput) (*awsS3.GetObjectOutput, error) {
+func (m *mockS3Client) GetObject(_ context.Context, input *awsS3.GetObjectInput, _ ...func(*awsS3.Options)) (*awsS3.GetObjectOutput, error) {
 	if m.WantErr == true {
 		return nil, errors.New("test-error-download-s3")
 	}
 
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
 	resp := new(awsS3.GetObjectOutput)
+	if m.Output != nil {
+		*resp = *m.Output
+	}
 
 	m.calledWith = append(m.calledWith, *input)
 	reader := bytes.NewReader([]byte(m.ExpectedResponse))
@@ -47,7 +61,7 @@ func (m *mockS3Client) GetObject(input *awsS3.GetObjectInput) (*awsS3.GetObjectO
 
 func TestS3Downloader_Run(t *testing.T) {
 	type fields struct {
-		client     s3iface.S3API
+		client     s3.GetObjectAPI
 		bucketName string
 		logger     *slog.Logger
 	}
@@ -88,6 +102,7 @@ func TestS3Downloader_Run(t *testing.T) {
 				tt.fields.client,
 				tt.fields.bucketName,
 				tt.fields.logger,
+				nil,
 			)
 			got, err := s.Run(tt.args.in0, tt.args.input, tt.args.meta, tt.name)
 
@@ -102,3 +117,76 @@ func TestS3Downloader_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestS3Downloader_RunWithOpts(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notModifiedErr := &smithyhttp.ResponseError{Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusNotModified}}, Err: errors.New("not modified")}
+
+	tests := []struct {
+		name            string
+		client          *mockS3Client
+		opts            *s3.S3DownloaderOpts
+		meta            map[string]interface{}
+		wantInput       awsS3.GetObjectInput
+		want            *s3.S3DownloaderOutput
+		wantMetaCompr   interface{}
+		wantNotModified bool
+	}{
+		{"It surfaces object attributes and detects compression", &mockS3Client{ExpectedResponse: "data", Output: &awsS3.GetObjectOutput{
+			ContentType:     aws.String("application/json"),
+			ContentEncoding: aws.String("gzip"),
+			ContentLength:   aws.Int64(4),
+			ETag:            aws.String(`"etag"`),
+			LastModified:    aws.Time(modified),
+			Metadata:        map[string]string{"source": "vecna"},
+		}}, &s3.S3DownloaderOpts{DetectCompression: true}, map[string]interface{}{},
+			awsS3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")},
+			&s3.S3DownloaderOutput{Data: []byte("data"), ContentType: "application/json", ContentEncoding: "gzip", ContentLength: 4,
+				ETag: `"etag"`, LastModified: modified, Metadata: map[string]string{"source": "vecna"}, CompressionType: "gzip"},
+			"gzip", false},
+		{"It sends range and conditional headers from opts", &mockS3Client{ExpectedResponse: "da"},
+			&s3.S3DownloaderOpts{Range: "bytes=0-1", IfNoneMatch: `"old"`, IfModifiedSince: modified}, map[string]interface{}{},
+			awsS3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), Range: aws.String("bytes=0-1"), IfNoneMatch: aws.String(`"old"`), IfModifiedSince: aws.Time(modified)},
+			&s3.S3DownloaderOutput{Data: []byte("da")}, nil, false},
+		{"It lets metadata override range and conditions", &mockS3Client{ExpectedResponse: "a"},
+			&s3.S3DownloaderOpts{Range: "bytes=0-1", RangeMetaKey: "range", IfNoneMatchMetaKey: "etag"}, map[string]interface{}{"range": "bytes=1-1", "etag": `"meta"`},
+			awsS3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), Range: aws.String("bytes=1-1"), IfNoneMatch: aws.String(`"meta"`)},
+			&s3.S3DownloaderOutput{Data: []byte("a")}, nil, false},
+		{"It ignores metadata without meta keys", &mockS3Client{ExpectedResponse: "da"},
+			&s3.S3DownloaderOpts{Range: "bytes=0-1"}, map[string]interface{}{"range": "bytes=1-1", "": "bytes=1-1"},
+			awsS3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), Range: aws.String("bytes=0-1")},
+			&s3.S3DownloaderOutput{Data: []byte("da")}, nil, false},
+		{"It returns ErrNotModified on 304", &mockS3Client{Err: notModifiedErr},
+			&s3.S3DownloaderOpts{IfNoneMatch: `"etag"`}, map[string]interface{}{}, awsS3.GetObjectInput{}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s3.NewS3Downloader(tt.client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			got, err := s.Run(context.TODO(), "key", tt.meta, tt.name)
+			if errors.Is(err, s3.ErrNotModified) != tt.wantNotModified || errors.Is(err, task.ErrNoData) != tt.wantNotModified {
+				t.Fatalf("S3Downloader.Run() error = %v, wantNotModified %v", err, tt.wantNotModified)
+			}
+
+			if tt.wantNotModified {
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("S3Downloader.Run() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(tt.client.calledWith[0], tt.wantInput) {
+				t.Errorf("S3Downloader.Run() input = %+v, want %+v", tt.client.calledWith[0], tt.wantInput)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("S3Downloader.Run() = %+v, want %+v", got, tt.want)
+			}
+
+			if tt.meta[tt.name] != tt.wantMetaCompr {
+				t.Errorf("S3Downloader.Run() metadata compression = %v, want %v", tt.meta[tt.name], tt.wantMetaCompr)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+)
+
+type S3ListerOpts struct {
+	// Prefix to be listed
+	Prefix string
+	// StartAfter is a checkpoint, only keys after it will be listed. Use Checkpoint() to get the last emitted key
+	StartAfter string
+	// Suffix filters keys ending with it (e.g. ".json.gz")
+	Suffix string
+	// KeyPattern filters keys matching it
+	KeyPattern *regexp.Regexp
+	// ModifiedSince filters objects modified at or after it
+	ModifiedSince time.Time
+	// ModifiedBefore filters objects modified before it
+	ModifiedBefore time.Time
+	// MaxKeys per ListObjectsV2 call (page size). Defaults to blob.DefaultMaxKeys
+	MaxKeys int32
+	// Poll keeps listing after the prefix is exhausted, looking for keys after the last emitted one.
+	// Works best when keys grow lexicographically (e.g. date based keys)
+	Poll bool
+	// PollInterval is the minimum interval between listings once the prefix is exhausted
+	PollInterval time.Duration
+}
+
+// S3ObjectInfo contains the information about the listed object, appended on metadata under worker name
+type S3ObjectInfo struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// S3Lister is a source task (to be used with ProducerWorker) which pages through all objects under a prefix.
+// Every Run() returns one key, ready to be given to S3Downloader, and appends its S3ObjectInfo to metadata.
+// When there is no object to emit it returns task.ErrNoData.
+// It is a blob.BlobLister over a blob.S3BucketLister, keeping the S3 specific client and metadata.
+type S3Lister[I task.Nullable, O string] struct {
+	lister *blob.BlobLister[I, string]
+}
+
+func NewS3Lister[I task.Nullable, O string](client ListObjectsAPI, bucketName string, logger *slog.Logger, opts *S3ListerOpts) *S3Lister[I, O] {
+	l := new(S3Lister[I, O])
+
+	if opts == nil {
+		opts = &S3ListerOpts{}
+	}
+
+	l.lister = blob.NewBlobLister[I, string](blob.NewS3BucketLister(client, bucketName), logger.With("bucket", bucketName), &blob.BlobListerOpts{
+		Prefix:         opts.Prefix,
+		StartAfter:     opts.StartAfter,
+		Suffix:         opts.Suffix,
+		KeyPattern:     opts.KeyPattern,
+		ModifiedSince:  opts.ModifiedSince,
+		ModifiedBefore: opts.ModifiedBefore,
+		MaxKeys:        int(opts.MaxKeys),
+		Poll:           opts.Poll,
+		PollInterval:   opts.PollInterval,
+	})
+
+	return l
+}
+
+// Checkpoint returns the last emitted key. Give it as StartAfter to resume the listing later.
+func (l *S3Lister[I, O]) Checkpoint() string {
+	return l.lister.Checkpoint()
+}
+
+// Run() returns the next listed key, fetching a new page when needed
+func (l *S3Lister[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	key, err := l.lister.Run(ctx, input, meta, name)
+	if err != nil {
+		return "", err
+	}
+
+	attrs := meta[name].(*blob.Attrs)
+
+	meta[name] = &S3ObjectInfo{
+		Key:          attrs.Key,
+		Size:         attrs.Size,
+		ETag:         attrs.ETag,
+		LastModified: attrs.LastModified,
+	}
+
+	return O(key), nil
+}
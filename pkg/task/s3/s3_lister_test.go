@@ -0,0 +1,184 @@
+package s3_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/blob"
+	"github.com/otaviohenrique/vecna/pkg/task/s3"
+)
+
+// S3ListerMock pages through Objects (sorted by key) PageSize objects at a time
+type S3ListerMock struct {
+	Objects    []types.Object
+	PageSize   int
+	WantErr    bool
+	CalledWith []awsS3.ListObjectsV2Input
+}
+
+func (m *S3ListerMock) ListObjectsV2(_ context.Context, input *awsS3.ListObjectsV2Input, _ ...func(*awsS3.Options)) (*awsS3.ListObjectsV2Output, error) {
+	if m.WantErr {
+		return nil, errors.New("list-error")
+	}
+
+	m.CalledWith = append(m.CalledWith, *input)
+
+	sort.Slice(m.Objects, func(i, j int) bool { return *m.Objects[i].Key < *m.Objects[j].Key })
+
+	start := 0
+	if input.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*input.ContinuationToken)
+	} else if input.StartAfter != nil {
+		for start < len(m.Objects) && *m.Objects[start].Key <= *input.StartAfter {
+			start++
+		}
+	}
+
+	end := min(start+m.PageSize, len(m.Objects))
+	out := &awsS3.ListObjectsV2Output{Contents: m.Objects[start:end], IsTruncated: aws.Bool(end < len(m.Objects))}
+
+	if end < len(m.Objects) {
+		out.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+
+	return out, nil
+}
+
+func object(key string, modified time.Time) types.Object {
+	return types.Object{Key: aws.String(key), Size: aws.Int64(int64(len(key))), ETag: aws.String("etag-" + key), LastModified: aws.Time(modified)}
+}
+
+func drainLister(l *s3.S3Lister[task.Nullable, string]) ([]string, error) {
+	var keys []string
+
+	for {
+		key, err := l.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "lister")
+		if errors.Is(err, task.ErrNoData) {
+			return keys, nil
+		}
+
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, key)
+	}
+}
+
+func TestS3Lister_Run(t *testing.T) {
+	now := time.Now()
+	objects := []types.Object{
+		object("prefix/a.json", now.Add(-3*time.Hour)),
+		object("prefix/b.csv", now.Add(-2*time.Hour)),
+		object("prefix/c.json", now.Add(-1*time.Hour)),
+		object("prefix/d.json", now),
+	}
+
+	tests := []struct {
+		name    string
+		client  *S3ListerMock
+		opts    *s3.S3ListerOpts
+		want    []string
+		wantErr bool
+	}{
+		{"It lists every object through pages", &S3ListerMock{Objects: objects, PageSize: 2},
+			&s3.S3ListerOpts{Prefix: "prefix/"}, []string{"prefix/a.json", "prefix/b.csv", "prefix/c.json", "prefix/d.json"}, false},
+		{"It starts after checkpoint", &S3ListerMock{Objects: objects, PageSize: 2},
+			&s3.S3ListerOpts{StartAfter: "prefix/b.csv"}, []string{"prefix/c.json", "prefix/d.json"}, false},
+		{"It filters by suffix", &S3ListerMock{Objects: objects, PageSize: 3},
+			&s3.S3ListerOpts{Suffix: ".csv"}, []string{"prefix/b.csv"}, false},
+		{"It filters by pattern", &S3ListerMock{Objects: objects, PageSize: 3},
+			&s3.S3ListerOpts{KeyPattern: regexp.MustCompile(`/[ad]\.json$`)}, []string{"prefix/a.json", "prefix/d.json"}, false},
+		{"It filters by modification window", &S3ListerMock{Objects: objects, PageSize: 10},
+			&s3.S3ListerOpts{ModifiedSince: now.Add(-150 * time.Minute), ModifiedBefore: now}, []string{"prefix/b.csv", "prefix/c.json"}, false},
+		{"It returns list errors", &S3ListerMock{WantErr: true},
+			nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := s3.NewS3Lister(tt.client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			got, err := drainLister(l)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("S3Lister.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("S3Lister.Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3Lister_RunMetadataAndPolling(t *testing.T) {
+	now := time.Now()
+	client := &S3ListerMock{Objects: []types.Object{object("2024/01", now)}, PageSize: 10}
+
+	l := s3.NewS3Lister(client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), &s3.S3ListerOpts{Poll: true})
+
+	meta := map[string]interface{}{}
+	key, err := l.Run(context.TODO(), task.Nullable{}, meta, "lister")
+	if err != nil {
+		t.Fatalf("S3Lister.Run() error = %v", err)
+	}
+
+	info := meta["lister"].(*s3.S3ObjectInfo)
+	if key != "2024/01" || info.Key != key || info.Size != int64(len(key)) || info.ETag != "etag-2024/01" || !info.LastModified.Equal(now) {
+		t.Errorf("S3Lister.Run() metadata = %+v", info)
+	}
+
+	if _, err := l.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "lister"); !errors.Is(err, task.ErrNoData) {
+		t.Fatalf("S3Lister.Run() error = %v, want ErrNoData", err)
+	}
+
+	client.Objects = append(client.Objects, object("2024/02", now))
+
+	key, err = l.Run(context.TODO(), task.Nullable{}, map[string]interface{}{}, "lister")
+	if err != nil || key != "2024/02" {
+		t.Fatalf("S3Lister.Run() = %v, %v, want new polled object", key, err)
+	}
+
+	if l.Checkpoint() != "2024/02" {
+		t.Errorf("S3Lister.Checkpoint() = %v, want 2024/02", l.Checkpoint())
+	}
+
+	if last := client.CalledWith[len(client.CalledWith)-1]; aws.ToString(last.StartAfter) != "2024/01" {
+		t.Errorf("S3Lister polled with StartAfter = %v, want 2024/01", aws.ToString(last.StartAfter))
+	}
+}
+
+func TestS3Lister_RunMaxKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *s3.S3ListerOpts
+		want int32
+	}{
+		{"It lists blob.DefaultMaxKeys by default", nil, blob.DefaultMaxKeys},
+		{"It lists MaxKeys per page", &s3.S3ListerOpts{MaxKeys: 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &S3ListerMock{Objects: []types.Object{object("a.json", time.Now())}, PageSize: 10}
+
+			if _, err := drainLister(s3.NewS3Lister(client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)); err != nil {
+				t.Fatalf("S3Lister.Run() error = %v", err)
+			}
+
+			if got := aws.ToInt32(client.CalledWith[0].MaxKeys); got != tt.want {
+				t.Errorf("S3Lister.Run() MaxKeys = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+// S3StreamDownloader is a task that opens an object from AWS S3 and returns its body as a stream, without
+// loading it into memory. Use it instead of S3Downloader for objects that don't fit in memory.
+// Close() the returned io.ReadCloser is next worker responsability.
+type S3StreamDownloader[I string, O io.ReadCloser] struct {
+	// S3 AWS client to be used
+	client GetObjectAPI
+	// Bucket name where all objects will be downloaded
+	bucketName string
+	logger     *slog.Logger
+}
+
+func NewS3StreamDownloader[I string, O io.ReadCloser](client GetObjectAPI, bucketName string, logger *slog.Logger) *S3StreamDownloader[I, O] {
+	s := new(S3StreamDownloader[I, O])
+
+	s.client = client
+	s.bucketName = bucketName
+	s.logger = logger
+
+	return s
+}
+
+// Run() receives the object key and returns the object body
+func (s *S3StreamDownloader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	var body O
+
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(string(input)),
+	})
+
+	if err != nil {
+		s.logger.Error("error opening object", "error", err, "path", input)
+		return body, err
+	}
+
+	s.logger.Debug("object opened successfully", "path", input)
+
+	body, ok := result.Body.(O)
+	if !ok {
+		result.Body.Close()
+
+		return body, task.ErrStreamOutputType
+	}
+
+	return body, nil
+}
@@ -0,0 +1,61 @@
+package s3_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/s3"
+)
+
+func TestS3StreamDownloader_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *mockS3Client
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"It correctly opens the object as a stream", &mockS3Client{ExpectedResponse: "streamed-response"}, "path/to/file", "streamed-response", false},
+		{"It correctly handles download error", &mockS3Client{WantErr: true}, "path/to/file", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := s3.NewS3StreamDownloader[string, io.ReadCloser](tt.client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+			got, err := s.Run(context.TODO(), tt.input, map[string]interface{}{}, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("S3StreamDownloader.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			defer got.Close()
+
+			body, _ := io.ReadAll(got)
+			if string(body) != tt.want {
+				t.Errorf("S3StreamDownloader.Run() = %v, want %v", string(body), tt.want)
+			}
+
+			if *tt.client.calledWith[0].Key != tt.input {
+				t.Errorf("S3StreamDownloader.Run() key = %v, want %v", *tt.client.calledWith[0].Key, tt.input)
+			}
+		})
+	}
+}
+
+func TestS3StreamDownloader_RunOutputType(t *testing.T) {
+	s := s3.NewS3StreamDownloader[string, *os.File](&mockS3Client{ExpectedResponse: "streamed-response"}, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	_, err := s.Run(context.TODO(), "path/to/file", map[string]interface{}{}, "downloader")
+	if !errors.Is(err, task.ErrStreamOutputType) {
+		t.Errorf("S3StreamDownloader.Run() error = %v, want %v", err, task.ErrStreamOutputType)
+	}
+}
@@ -0,0 +1,276 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	// MinPartSize is the minimum part size accepted by S3 multipart upload (5 MiB)
+	MinPartSize = 5 * 1024 * 1024
+	// DefaultPartSize used by S3StreamUploader when not given (8 MiB)
+	DefaultPartSize = 8 * 1024 * 1024
+	// DefaultUploadConcurrency used by S3StreamUploader when not given
+	DefaultUploadConcurrency = 4
+	// MaxUploadParts is the maximum number of parts of a S3 multipart upload
+	MaxUploadParts = 10000
+)
+
+var (
+	ErrTooManyParts = errors.New("object exceeds the maximum number of parts, increase PartSize")
+)
+
+type S3StreamUploaderOpts struct {
+	// PartSize in bytes of each uploaded part. Minimum MinPartSize, defaults to DefaultPartSize
+	PartSize int64
+	// Concurrency is the number of parts uploaded in parallel. Defaults to DefaultUploadConcurrency
+	Concurrency int
+}
+
+// S3StreamUploaderInput is a envelope containing all the information needed to stream a object to S3
+type S3StreamUploaderInput struct {
+	// Path (Key) to upload the object
+	Path string
+	// Body to be uploaded. If it is an io.Closer it will be closed after upload
+	Body io.Reader
+}
+
+// S3StreamUploader is a task which uploads a stream to the given path (key) of one bucket using multipart upload.
+// Memory usage is bounded by PartSize * Concurrency, regardless of object size.
+// Bodies smaller than PartSize are uploaded with a single PutObject.
+type S3StreamUploader[I *S3StreamUploaderInput, O task.Nullable] struct {
+	// S3 AWS client to be used
+	client MultipartUploadAPI
+	// Bucket name where all objects will be stored
+	bucketName string
+	logger     *slog.Logger
+	opts       *S3StreamUploaderOpts
+}
+
+func NewS3StreamUploader[I *S3StreamUploaderInput, O task.Nullable](client MultipartUploadAPI, bucketName string, logger *slog.Logger, opts *S3StreamUploaderOpts) *S3StreamUploader[I, O] {
+	u := new(S3StreamUploader[I, O])
+
+	u.client = client
+	u.bucketName = bucketName
+	u.logger = logger
+	u.opts = opts
+
+	if u.opts == nil {
+		u.opts = &S3StreamUploaderOpts{}
+	}
+
+	return u
+}
+
+func (s *S3StreamUploader[I, O]) partSize() int64 {
+	if s.opts.PartSize == 0 {
+		return DefaultPartSize
+	}
+
+	if s.opts.PartSize < MinPartSize {
+		return MinPartSize
+	}
+
+	return s.opts.PartSize
+}
+
+func (s *S3StreamUploader[I, O]) concurrency() int {
+	if s.opts.Concurrency < 1 {
+		return DefaultUploadConcurrency
+	}
+
+	return s.opts.Concurrency
+}
+
+// Run() uploads the given Body on Path. It only returns errors
+func (s *S3StreamUploader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	in := (*S3StreamUploaderInput)(input)
+
+	if closer, ok := in.Body.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	first := make([]byte, s.partSize())
+
+	n, err := io.ReadFull(in.Body, first)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		err = s.putObject(ctx, in.Path, first[:n])
+	case err != nil:
+		s.logger.Error("error reading object to upload", "error", err, "path", in.Path)
+	default:
+		err = s.multipartUpload(ctx, in, first)
+	}
+
+	if err != nil {
+		return O(task.Nullable{}), err
+	}
+
+	s.logger.Debug("object uploaded successfully", "path", in.Path)
+
+	return O(task.Nullable{}), nil
+}
+
+func (s *S3StreamUploader[I, O]) putObject(ctx context.Context, path string, content []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(path),
+		Body:   bytes.NewReader(content),
+	})
+
+	if err != nil {
+		s.logger.Error("error uploading object", "error", err, "path", path)
+	}
+
+	return err
+}
+
+// multipartUpload uploads first and the rest of in.Body as parts, aborting the upload on any error
+func (s *S3StreamUploader[I, O]) multipartUpload(ctx context.Context, in *S3StreamUploaderInput, first []byte) error {
+	created, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(in.Path),
+	})
+
+	if err != nil {
+		s.logger.Error("error creating multipart upload", "error", err, "path", in.Path)
+		return err
+	}
+
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		parts     []types.CompletedPart
+		uploadErr error
+	)
+
+	setErr := func(err error) {
+		mu.Lock()
+		if uploadErr == nil {
+			uploadErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	// buffers bounds memory usage, a part is only read when a buffer is available
+	buffers := make(chan []byte, s.concurrency())
+	for i := 1; i < s.concurrency(); i++ {
+		buffers <- make([]byte, s.partSize())
+	}
+
+	uploadPart := func(number int32, buf []byte, size int) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { buffers <- buf }()
+
+			out, err := s.client.UploadPart(uploadCtx, &s3.UploadPartInput{
+				Bucket:     aws.String(s.bucketName),
+				Key:        aws.String(in.Path),
+				UploadId:   created.UploadId,
+				PartNumber: aws.Int32(number),
+				Body:       bytes.NewReader(buf[:size]),
+			})
+
+			if err != nil {
+				setErr(err)
+				return
+			}
+
+			mu.Lock()
+			parts = append(parts, types.CompletedPart{ETag: out.ETag, PartNumber: aws.Int32(number)})
+			mu.Unlock()
+		}()
+	}
+
+	uploadPart(1, first, len(first))
+
+readLoop:
+	for number := int32(2); ; number++ {
+		var buf []byte
+
+		select {
+		case buf = <-buffers:
+		case <-uploadCtx.Done():
+			break readLoop
+		}
+
+		n, err := io.ReadFull(in.Body, buf)
+
+		if n > 0 {
+			if number > MaxUploadParts {
+				setErr(ErrTooManyParts)
+				break
+			}
+
+			uploadPart(number, buf, n)
+		} else {
+			buffers <- buf
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if err != nil {
+			setErr(err)
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if uploadErr == nil && ctx.Err() != nil {
+		uploadErr = ctx.Err()
+	}
+
+	if uploadErr != nil {
+		s.logger.Error("error on multipart upload, aborting", "error", uploadErr, "path", in.Path)
+		s.abort(context.WithoutCancel(ctx), in.Path, created.UploadId)
+
+		return uploadErr
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return *parts[i].PartNumber < *parts[j].PartNumber })
+
+	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucketName),
+		Key:             aws.String(in.Path),
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+
+	if err != nil {
+		s.logger.Error("error completing multipart upload", "error", err, "path", in.Path)
+		s.abort(context.WithoutCancel(ctx), in.Path, created.UploadId)
+	}
+
+	return err
+}
+
+func (s *S3StreamUploader[I, O]) abort(ctx context.Context, path string, uploadID *string) {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucketName),
+		Key:      aws.String(path),
+		UploadId: uploadID,
+	})
+
+	if err != nil {
+		s.logger.Error("error aborting multipart upload", "error", err, "path", path)
+	}
+}
@@ -0,0 +1,140 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/otaviohenrique/vecna/pkg/task/s3"
+)
+
+type S3MultipartMock struct {
+	mu             sync.Mutex
+	Objects        map[string][]byte
+	parts          map[int32][]byte
+	UploadPartErr  bool
+	PutCalls       int
+	UploadedParts  int
+	Completed      bool
+	Aborted        bool
+	CompletedOrder []int32
+}
+
+func (m *S3MultipartMock) PutObject(_ context.Context, input *awsS3.PutObjectInput, _ ...func(*awsS3.Options)) (*awsS3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(input.Body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.PutCalls++
+	m.Objects[*input.Key] = body
+
+	return &awsS3.PutObjectOutput{}, nil
+}
+
+func (m *S3MultipartMock) CreateMultipartUpload(_ context.Context, input *awsS3.CreateMultipartUploadInput, _ ...func(*awsS3.Options)) (*awsS3.CreateMultipartUploadOutput, error) {
+	m.parts = map[int32][]byte{}
+
+	return &awsS3.CreateMultipartUploadOutput{UploadId: aws.String("upload-id")}, nil
+}
+
+func (m *S3MultipartMock) UploadPart(_ context.Context, input *awsS3.UploadPartInput, _ ...func(*awsS3.Options)) (*awsS3.UploadPartOutput, error) {
+	if m.UploadPartErr {
+		return nil, errors.New("upload-part-error")
+	}
+
+	body, _ := io.ReadAll(input.Body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.UploadedParts++
+	m.parts[*input.PartNumber] = body
+
+	return &awsS3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag-%d", *input.PartNumber))}, nil
+}
+
+func (m *S3MultipartMock) CompleteMultipartUpload(_ context.Context, input *awsS3.CompleteMultipartUploadInput, _ ...func(*awsS3.Options)) (*awsS3.CompleteMultipartUploadOutput, error) {
+	var obj []byte
+
+	for _, p := range input.MultipartUpload.Parts {
+		m.CompletedOrder = append(m.CompletedOrder, *p.PartNumber)
+		obj = append(obj, m.parts[*p.PartNumber]...)
+	}
+
+	m.Objects[*input.Key] = obj
+	m.Completed = true
+
+	return &awsS3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *S3MultipartMock) AbortMultipartUpload(_ context.Context, input *awsS3.AbortMultipartUploadInput, _ ...func(*awsS3.Options)) (*awsS3.AbortMultipartUploadOutput, error) {
+	m.Aborted = true
+
+	return &awsS3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestS3StreamUploader_Run(t *testing.T) {
+	bigContent := bytes.Repeat([]byte("0123456789"), (2*s3.MinPartSize+100)/10)
+
+	tests := []struct {
+		name         string
+		client       *S3MultipartMock
+		content      []byte
+		wantPutCalls int
+		wantParts    int
+		wantAborted  bool
+		wantErr      bool
+	}{
+		{"It uploads small objects with a single put", &S3MultipartMock{Objects: map[string][]byte{}},
+			[]byte("small-object"), 1, 0, false, false},
+		{"It uploads big objects with multipart upload", &S3MultipartMock{Objects: map[string][]byte{}},
+			bigContent, 0, 3, false, false},
+		{"It aborts multipart upload on part error", &S3MultipartMock{Objects: map[string][]byte{}, UploadPartErr: true},
+			bigContent, 0, 0, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := s3.NewS3StreamUploader(tt.client, "bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), &s3.S3StreamUploaderOpts{
+				PartSize:    s3.MinPartSize,
+				Concurrency: 2,
+			})
+
+			_, err := u.Run(context.TODO(), &s3.S3StreamUploaderInput{Path: "path/to/obj", Body: bytes.NewReader(tt.content)}, map[string]interface{}{}, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("S3StreamUploader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.client.PutCalls != tt.wantPutCalls {
+				t.Errorf("S3StreamUploader.Run() put calls = %d, want %d", tt.client.PutCalls, tt.wantPutCalls)
+			}
+
+			if tt.client.UploadedParts != tt.wantParts {
+				t.Errorf("S3StreamUploader.Run() uploaded parts = %d, want %d", tt.client.UploadedParts, tt.wantParts)
+			}
+
+			if tt.client.Aborted != tt.wantAborted {
+				t.Errorf("S3StreamUploader.Run() aborted = %v, want %v", tt.client.Aborted, tt.wantAborted)
+			}
+
+			if !tt.wantErr && !bytes.Equal(tt.client.Objects["path/to/obj"], tt.content) {
+				t.Errorf("S3StreamUploader.Run() uploaded object differs from input")
+			}
+
+			for i, n := range tt.client.CompletedOrder {
+				if n != int32(i+1) {
+					t.Errorf("S3StreamUploader.Run() parts out of order = %v", tt.client.CompletedOrder)
+					break
+				}
+			}
+		})
+	}
+}
@@ -4,62 +4,165 @@ import (
 	"bytes"
 	"context"
 	"log/slog"
+	"net/url"
+	"text/template"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/internal/upload"
+)
+
+var (
+	ErrMissingKey = upload.ErrMissingKey
+	ErrEmptyKey   = upload.ErrEmptyKey
 )
 
 // S3Uploader is a task which will upload a given data on the given path (key) of one bucket
 type S3Uploader[I *S3UploaderInput, O task.Nullable] struct {
 	// S3 AWS client to be used
-	client s3iface.S3API
-	// Bucket name where all objects will be stored
-	bucketName string
-	logger     *slog.Logger
+	client PutObjectAPI
+	// Bucket name where all objects will be stored (unless overridden by input)
+	bucketName  string
+	logger      *slog.Logger
+	opts        *S3UploaderOpts
+	keyTemplate *upload.KeyTemplate
+}
+
+// S3UploaderOpts are the defaults applied to every uploaded object. Fields set on S3UploaderInput override them.
+type S3UploaderOpts struct {
+	// KeyTemplate renders the key from WorkerData metadata when input Path is empty.
+	// Ex. template.Must(template.New("key").Parse("dt={{.date}}/{{.uuid}}.json.gz"))
+	// Missing metadata keys return an error.
+	KeyTemplate *template.Template
+	// ContentType of uploaded objects (ex. application/json)
+	ContentType string
+	// ContentEncoding of uploaded objects (ex. gzip)
+	ContentEncoding string
+	// Metadata is user defined metadata (x-amz-meta-*)
+	Metadata map[string]string
+	// Tags applied to the uploaded objects
+	Tags map[string]string
+	// StorageClass of uploaded objects (ex. types.StorageClassStandardIa)
+	StorageClass types.StorageClass
+	// ServerSideEncryption (types.ServerSideEncryptionAes256 for SSE-S3 or types.ServerSideEncryptionAwsKms for SSE-KMS)
+	ServerSideEncryption types.ServerSideEncryption
+	// SSEKMSKeyID is the KMS key used when ServerSideEncryption is SSE-KMS
+	SSEKMSKeyID string
+	// BucketKeyEnabled uses a S3 Bucket Key for SSE-KMS
+	BucketKeyEnabled bool
 }
 
 // S3UploaderInput is a envelope containing all the information needed to upload object to S3
 // Should be returned by adaptFn
 type S3UploaderInput struct {
-	// Path (Key) to upload the object
+	// Path (Key) to upload the object. When empty it is rendered from KeyTemplate
 	Path string
 	// Bytes to be uploaded
 	Content []byte
+	// Bucket overrides the bucket given on constructor
+	Bucket string
+	// ContentType overrides S3UploaderOpts.ContentType
+	ContentType string
+	// ContentEncoding overrides S3UploaderOpts.ContentEncoding
+	ContentEncoding string
+	// Metadata is merged with S3UploaderOpts.Metadata (input wins)
+	Metadata map[string]string
+	// Tags is merged with S3UploaderOpts.Tags (input wins)
+	Tags map[string]string
+	// StorageClass overrides S3UploaderOpts.StorageClass
+	StorageClass types.StorageClass
+	// ServerSideEncryption overrides S3UploaderOpts.ServerSideEncryption
+	ServerSideEncryption types.ServerSideEncryption
+	// SSEKMSKeyID overrides S3UploaderOpts.SSEKMSKeyID
+	SSEKMSKeyID string
 }
 
-func NewS3Uploader[I *S3UploaderInput, O task.Nullable](client s3iface.S3API, bucketName string, logger *slog.Logger) *S3Uploader[I, O] {
+// NewS3Uploader creates a S3Uploader. opts is optional (nil)
+func NewS3Uploader[I *S3UploaderInput, O task.Nullable](client PutObjectAPI, bucketName string, logger *slog.Logger, opts *S3UploaderOpts) *S3Uploader[I, O] {
 	u := new(S3Uploader[I, O])
 
 	u.client = client
 	u.bucketName = bucketName
 	u.logger = logger
+	u.opts = opts
+
+	if u.opts == nil {
+		u.opts = &S3UploaderOpts{}
+	}
+
+	u.keyTemplate = upload.NewKeyTemplate(u.opts.KeyTemplate)
 
 	return u
 }
 
 // Run() will be called by worker and should return a pointer to TaskData.
 // It doesn't merge nothing on metadata given and only return errors if any
-func (s *S3Uploader[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
-	err := s.uploadObject(input)
+func (s *S3Uploader[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	err := s.uploadObject(ctx, input, meta)
 
 	return O(task.Nullable{}), err
 }
 
-func (s *S3Uploader[T, K]) uploadObject(input *S3UploaderInput) error {
-	_, err := s.client.PutObject(&s3.PutObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(input.Path),
-		Body:   aws.ReadSeekCloser(bytes.NewReader(input.Content)),
-	})
+func (s *S3Uploader[T, K]) uploadObject(ctx context.Context, input *S3UploaderInput, meta map[string]interface{}) error {
+	putInput, err := s.putObjectInput(input, meta)
+	if err != nil {
+		s.logger.Error("error building object upload", "error", err, "path", input.Path)
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, putInput)
 
 	if err != nil {
-		s.logger.Error("error uploading object", "error", err, "path", input.Path)
+		s.logger.Error("error uploading object", "error", err, "path", *putInput.Key)
 		return err
 	}
 
-	s.logger.Debug("object uploaded successfully", "path", input.Path)
+	s.logger.Debug("object uploaded successfully", "path", *putInput.Key)
 
 	return nil
 }
+
+func (s *S3Uploader[T, K]) putObjectInput(input *S3UploaderInput, meta map[string]interface{}) (*s3.PutObjectInput, error) {
+	key, err := s.keyTemplate.Key(input.Path, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	putInput := &s3.PutObjectInput{
+		Bucket:               aws.String(upload.Override(input.Bucket, s.bucketName)),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(input.Content),
+		StorageClass:         types.StorageClass(upload.Override(string(input.StorageClass), string(s.opts.StorageClass))),
+		ServerSideEncryption: types.ServerSideEncryption(upload.Override(string(input.ServerSideEncryption), string(s.opts.ServerSideEncryption))),
+		Metadata:             upload.Merge(s.opts.Metadata, input.Metadata),
+	}
+
+	if v := upload.Override(input.ContentType, s.opts.ContentType); v != "" {
+		putInput.ContentType = aws.String(v)
+	}
+
+	if v := upload.Override(input.ContentEncoding, s.opts.ContentEncoding); v != "" {
+		putInput.ContentEncoding = aws.String(v)
+	}
+
+	if v := upload.Override(input.SSEKMSKeyID, s.opts.SSEKMSKeyID); v != "" {
+		putInput.SSEKMSKeyId = aws.String(v)
+	}
+
+	if s.opts.BucketKeyEnabled {
+		putInput.BucketKeyEnabled = aws.Bool(true)
+	}
+
+	if tags := upload.Merge(s.opts.Tags, input.Tags); len(tags) > 0 {
+		values := url.Values{}
+		for k, v := range tags {
+			values.Set(k, v)
+		}
+
+		putInput.Tagging = aws.String(values.Encode())
+	}
+
+	return putInput, nil
+}
@@ -6,10 +6,13 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"reflect"
 	"testing"
+	"text/template"
 
-	awsS3 "github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/otaviohenrique/vecna/pkg/task/s3"
 )
 
@@ -31,12 +34,11 @@ func readToString(rs io.ReadSeeker) (string, error) {
 }
 
 type S3UploaderMock struct {
-	s3iface.S3API
 	CalledWith []awsS3.PutObjectInput
 	WantErr    bool
 }
 
-func (u *S3UploaderMock) PutObject(input *awsS3.PutObjectInput) (*awsS3.PutObjectOutput, error) {
+func (u *S3UploaderMock) PutObject(_ context.Context, input *awsS3.PutObjectInput, _ ...func(*awsS3.Options)) (*awsS3.PutObjectOutput, error) {
 	if u.WantErr == true {
 		return nil, errors.New("error-on-put")
 	}
@@ -49,7 +51,7 @@ func (u *S3UploaderMock) PutObject(input *awsS3.PutObjectInput) (*awsS3.PutObjec
 
 func TestS3Uploader_Run(t *testing.T) {
 	type fields struct {
-		client     s3iface.S3API
+		client     s3.PutObjectAPI
 		bucketName string
 		logger     *slog.Logger
 	}
@@ -90,6 +92,7 @@ func TestS3Uploader_Run(t *testing.T) {
 				tt.fields.client,
 				tt.fields.bucketName,
 				tt.fields.logger,
+				nil,
 			)
 
 			_, err := s.Run(tt.args.in0, &tt.args.input, tt.args.meta, tt.name)
@@ -107,10 +110,110 @@ func TestS3Uploader_Run(t *testing.T) {
 					t.Errorf("S3Uploader.Run() want to call upload with right key, got := %s want = %s", *mck.CalledWith[0].Key, input.Path)
 				}
 
-				if str, _ := readToString(mck.CalledWith[0].Body); str != string(input.Content) {
+				if str, _ := readToString(mck.CalledWith[0].Body.(io.ReadSeeker)); str != string(input.Content) {
 					t.Errorf("S3Uploader.Run() want to call upload with right content, got := %s want = %s", str, string(input.Content))
 				}
 			}
 		})
 	}
 }
+
+func TestS3Uploader_RunWithOpts(t *testing.T) {
+	opts := &s3.S3UploaderOpts{
+		KeyTemplate:          template.Must(template.New("key").Parse("dt={{.date}}/{{.uuid}}.json.gz")),
+		ContentType:          "application/json",
+		ContentEncoding:      "gzip",
+		Metadata:             map[string]string{"source": "vecna", "team": "data"},
+		Tags:                 map[string]string{"env": "prod"},
+		StorageClass:         types.StorageClassStandardIa,
+		ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+		SSEKMSKeyID:          "kms-key",
+	}
+
+	tests := []struct {
+		name    string
+		input   s3.S3UploaderInput
+		meta    map[string]interface{}
+		want    awsS3.PutObjectInput
+		wantErr bool
+	}{
+		{"It renders key from metadata and applies defaults", s3.S3UploaderInput{Content: []byte("data")},
+			map[string]interface{}{"date": "2024-01-01", "uuid": "abc"},
+			awsS3.PutObjectInput{
+				Bucket:               aws.String("default-bucket"),
+				Key:                  aws.String("dt=2024-01-01/abc.json.gz"),
+				ContentType:          aws.String("application/json"),
+				ContentEncoding:      aws.String("gzip"),
+				Metadata:             map[string]string{"source": "vecna", "team": "data"},
+				Tagging:              aws.String("env=prod"),
+				StorageClass:         types.StorageClassStandardIa,
+				ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+				SSEKMSKeyId:          aws.String("kms-key"),
+			}, false},
+		{"It applies per message overrides", s3.S3UploaderInput{
+			Path:                 "given/path",
+			Content:              []byte("data"),
+			Bucket:               "other-bucket",
+			ContentType:          "text/csv",
+			Metadata:             map[string]string{"team": "infra"},
+			Tags:                 map[string]string{"env": "dev", "owner": "me"},
+			StorageClass:         types.StorageClassGlacier,
+			ServerSideEncryption: types.ServerSideEncryptionAes256,
+		}, map[string]interface{}{},
+			awsS3.PutObjectInput{
+				Bucket:               aws.String("other-bucket"),
+				Key:                  aws.String("given/path"),
+				ContentType:          aws.String("text/csv"),
+				ContentEncoding:      aws.String("gzip"),
+				Metadata:             map[string]string{"source": "vecna", "team": "infra"},
+				Tagging:              aws.String("env=dev&owner=me"),
+				StorageClass:         types.StorageClassGlacier,
+				ServerSideEncryption: types.ServerSideEncryptionAes256,
+				SSEKMSKeyId:          aws.String("kms-key"),
+			}, false},
+		{"It returns error when template metadata is missing", s3.S3UploaderInput{Content: []byte("data")},
+			map[string]interface{}{"date": "2024-01-01"}, awsS3.PutObjectInput{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mck := &S3UploaderMock{}
+			u := s3.NewS3Uploader(mck, "default-bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), opts)
+
+			_, err := u.Run(context.TODO(), &tt.input, tt.meta, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("S3Uploader.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got := mck.CalledWith[0]
+			got.Body = nil
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("S3Uploader.Run() put = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3Uploader_RunKeyTemplate(t *testing.T) {
+	keyTemplate := template.Must(template.New("key").Parse("{{.prefix}}"))
+
+	mck := &S3UploaderMock{}
+	u := s3.NewS3Uploader(mck, "default-bucket", slog.New(slog.NewTextHandler(os.Stdout, nil)), &s3.S3UploaderOpts{KeyTemplate: keyTemplate})
+
+	if _, err := u.Run(context.TODO(), &s3.S3UploaderInput{Content: []byte("data")}, map[string]interface{}{"prefix": ""}, "upload"); !errors.Is(err, s3.ErrEmptyKey) {
+		t.Errorf("S3Uploader.Run() error = %v, want %v", err, s3.ErrEmptyKey)
+	}
+
+	if len(mck.CalledWith) > 0 {
+		t.Errorf("S3Uploader.Run() uploaded %d objects with an empty key", len(mck.CalledWith))
+	}
+
+	// the given template isn't changed to fail on missing keys
+	if err := keyTemplate.Execute(io.Discard, map[string]interface{}{}); err != nil {
+		t.Errorf("KeyTemplate.Execute() after NewS3Uploader error = %v", err)
+	}
+}
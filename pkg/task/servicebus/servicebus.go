@@ -0,0 +1,81 @@
+package servicebus
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+)
+
+// ServiceBusMessage is a message received by ServiceBusReceiver, also appended on metadata under worker name.
+// It stays locked (peek-lock) until it's settled with Complete, Abandon or DeadLetter, or its lock expires.
+type ServiceBusMessage struct {
+	MessageID             string
+	Body                  []byte
+	ApplicationProperties map[string]any
+	ContentType           string
+	CorrelationID         string
+	Subject               string
+	SessionID             string
+	EnqueuedTime          time.Time
+	// LockedUntil is when the lock taken on receive expires (renewals aren't reflected)
+	LockedUntil time.Time
+	// DeliveryCount is how many times the message was delivered, including this one
+	DeliveryCount uint32
+
+	msg      *azservicebus.ReceivedMessage
+	receiver ReceiverAPI
+}
+
+func newServiceBusMessage(m *azservicebus.ReceivedMessage, receiver ReceiverAPI) *ServiceBusMessage {
+	msg := &ServiceBusMessage{
+		MessageID:             m.MessageID,
+		Body:                  m.Body,
+		ApplicationProperties: m.ApplicationProperties,
+		ContentType:           deref(m.ContentType),
+		CorrelationID:         deref(m.CorrelationID),
+		Subject:               deref(m.Subject),
+		SessionID:             deref(m.SessionID),
+		DeliveryCount:         m.DeliveryCount,
+		msg:                   m,
+		receiver:              receiver,
+	}
+
+	if m.EnqueuedTime != nil {
+		msg.EnqueuedTime = *m.EnqueuedTime
+	}
+
+	if m.LockedUntil != nil {
+		msg.LockedUntil = *m.LockedUntil
+	}
+
+	return msg
+}
+
+// Complete removes the message from the queue (or subscription)
+func (m *ServiceBusMessage) Complete(ctx context.Context) error {
+	return m.receiver.CompleteMessage(ctx, m.msg, nil)
+}
+
+// Abandon releases the lock, the message is delivered again (or dead-lettered by the entity after its max delivery count)
+func (m *ServiceBusMessage) Abandon(ctx context.Context) error {
+	return m.receiver.AbandonMessage(ctx, m.msg, nil)
+}
+
+// DeadLetter moves the message to the dead letter queue
+func (m *ServiceBusMessage) DeadLetter(ctx context.Context, reason string, description string) error {
+	return m.receiver.DeadLetterMessage(ctx, m.msg, &azservicebus.DeadLetterOptions{Reason: &reason, ErrorDescription: &description})
+}
+
+// RenewLock extends the lock of the message, keeping it from being delivered again while processed
+func (m *ServiceBusMessage) RenewLock(ctx context.Context) error {
+	return m.receiver.RenewMessageLock(ctx, m.msg, nil)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,100 @@
+package servicebus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+type ServiceBusAckOpts struct {
+	// MessageMetaKey is the name of the worker running ServiceBusReceiver, where its *ServiceBusMessage is kept on metadata
+	MessageMetaKey string
+	// MaxDeliveryCount dead-letters failed messages delivered this many times instead of abandoning them again, with the
+	// task error as reason. Disabled when zero, leaving it to the entity max delivery count
+	MaxDeliveryCount uint32
+	// RenewLockInterval between lock renewals while the task runs, keeping slow messages from being delivered again
+	// (use less than the entity lock duration). Disabled when zero
+	RenewLockInterval time.Duration
+}
+
+// ServiceBusAck wraps a task settling the peek-locked message a ServiceBusReceiver kept on metadata.
+// Success (or task.ErrNoData, dropping the message on purpose) completes it. Failure abandons it, releasing the lock
+// for another delivery, until MaxDeliveryCount where it is dead-lettered with the task error as description (the entity
+// moves it on its own max delivery count otherwise). RenewLockInterval keeps the lock of slow runs.
+type ServiceBusAck[I any, O any] struct {
+	task   task.Task[I, O]
+	logger *slog.Logger
+	opts   *ServiceBusAckOpts
+}
+
+func NewServiceBusAck[I any, O any](t task.Task[I, O], logger *slog.Logger, opts *ServiceBusAckOpts) *ServiceBusAck[I, O] {
+	a := new(ServiceBusAck[I, O])
+
+	a.task = t
+	a.logger = logger
+	a.opts = opts
+
+	return a
+}
+
+func (a *ServiceBusAck[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, name string) (O, error) {
+	msg, ok := meta[a.opts.MessageMetaKey].(*ServiceBusMessage)
+	if !ok || msg.msg == nil {
+		return a.task.Run(ctx, input, meta, name)
+	}
+
+	if a.opts.RenewLockInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+
+		go a.renewLock(ctx, msg, done)
+	}
+
+	resp, err := a.task.Run(ctx, input, meta, name)
+	if err != nil && !errors.Is(err, task.ErrNoData) {
+		a.settleFailed(ctx, msg, err)
+
+		return resp, err
+	}
+
+	if completeErr := msg.Complete(ctx); completeErr != nil {
+		a.logger.Error("error completing service bus message", "error", completeErr, "message_id", msg.MessageID)
+
+		return resp, completeErr
+	}
+
+	return resp, err
+}
+
+func (a *ServiceBusAck[I, O]) settleFailed(ctx context.Context, msg *ServiceBusMessage, taskErr error) {
+	if a.opts.MaxDeliveryCount > 0 && msg.DeliveryCount >= a.opts.MaxDeliveryCount {
+		if err := msg.DeadLetter(ctx, "task failed", taskErr.Error()); err != nil {
+			a.logger.Error("error dead-lettering service bus message", "error", err, "message_id", msg.MessageID)
+		}
+
+		return
+	}
+
+	if err := msg.Abandon(ctx); err != nil {
+		a.logger.Error("error abandoning service bus message", "error", err, "message_id", msg.MessageID)
+	}
+}
+
+func (a *ServiceBusAck[I, O]) renewLock(ctx context.Context, msg *ServiceBusMessage, done chan struct{}) {
+	ticker := time.NewTicker(a.opts.RenewLockInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := msg.RenewLock(ctx); err != nil {
+				a.logger.Warn("error renewing service bus message lock", "error", err, "message_id", msg.MessageID)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package servicebus_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/servicebus"
+)
+
+type mockTask[I []byte, O string] struct {
+	err   error
+	delay time.Duration
+}
+
+func (m *mockTask[I, O]) Run(_ context.Context, input I, _ map[string]interface{}, _ string) (O, error) {
+	time.Sleep(m.delay)
+
+	return O(input), m.err
+}
+
+func TestServiceBusAck_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		opts            servicebus.ServiceBusAckOpts
+		deliveryCount   uint32
+		wantCompleted   []string
+		wantAbandoned   []string
+		wantDeadLetters []string
+	}{
+		{"It completes when the task succeeds", nil, servicebus.ServiceBusAckOpts{}, 1, []string{"1"}, nil, nil},
+		{"It abandons when the task fails", errors.New("boom"), servicebus.ServiceBusAckOpts{}, 1, nil, []string{"1"}, nil},
+		{"It completes when the task returns ErrNoData", task.ErrNoData, servicebus.ServiceBusAckOpts{}, 1, []string{"1"}, nil, nil},
+		{"It abandons before max delivery count", errors.New("boom"), servicebus.ServiceBusAckOpts{MaxDeliveryCount: 3}, 2, nil, []string{"1"}, nil},
+		{"It dead-letters on max delivery count", errors.New("boom"), servicebus.ServiceBusAckOpts{MaxDeliveryCount: 3}, 3, nil, nil, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := received("1")
+			msgs[0].DeliveryCount = tt.deliveryCount
+
+			receiver := &mockReceiver{queue: msgs}
+			r := servicebus.NewServiceBusReceiver(receiver, slog.New(slog.NewTextHandler(os.Stdout, nil)), nil)
+			meta := map[string]interface{}{}
+
+			msg, err := r.Run(context.TODO(), task.Nullable{}, meta, "servicebus")
+			if err != nil {
+				t.Fatalf("ServiceBusReceiver.Run() error = %v", err)
+			}
+
+			tt.opts.MessageMetaKey = "servicebus"
+			ack := servicebus.NewServiceBusAck[[]byte, string](&mockTask[[]byte, string]{err: tt.err}, slog.New(slog.NewTextHandler(os.Stdout, nil)), &tt.opts)
+
+			got, err := ack.Run(context.TODO(), msg.Body, meta, "process")
+			if !errors.Is(err, tt.err) || got != "body-1" {
+				t.Errorf("ServiceBusAck.Run() = %s, %v, want body-1, %v", got, err, tt.err)
+			}
+
+			if !reflect.DeepEqual(receiver.completed, tt.wantCompleted) || !reflect.DeepEqual(receiver.abandoned, tt.wantAbandoned) || !reflect.DeepEqual(receiver.deadLetters, tt.wantDeadLetters) {
+				t.Errorf("ServiceBusAck.Run() completed %v abandoned %v dead-lettered %v, want %v %v %v",
+					receiver.completed, receiver.abandoned, receiver.deadLetters, tt.wantCompleted, tt.wantAbandoned, tt.wantDeadLetters)
+			}
+
+			if len(tt.wantDeadLetters) > 0 && receiver.reasons[0] != "boom" {
+				t.Errorf("ServiceBusAck.Run() dead letter description = %s, want boom", receiver.reasons[0])
+			}
+		})
+	}
+}
+
+func TestServiceBusAck_RunRenewsLock(t *testing.T) {
+	receiver := &mockReceiver{queue: received("1")}
+	r := servicebus.NewServiceBusReceiver(receiver, slog.Default(), nil)
+	meta := map[string]interface{}{}
+
+	msg, err := r.Run(context.TODO(), task.Nullable{}, meta, "servicebus")
+	if err != nil {
+		t.Fatalf("ServiceBusReceiver.Run() error = %v", err)
+	}
+
+	ack := servicebus.NewServiceBusAck[[]byte, string](&mockTask[[]byte, string]{delay: 100 * time.Millisecond}, slog.Default(), &servicebus.ServiceBusAckOpts{
+		MessageMetaKey:    "servicebus",
+		RenewLockInterval: 20 * time.Millisecond,
+	})
+
+	if _, err := ack.Run(context.TODO(), msg.Body, meta, "process"); err != nil {
+		t.Fatalf("ServiceBusAck.Run() error = %v", err)
+	}
+
+	if receiver.renewed() == 0 {
+		t.Errorf("ServiceBusAck.Run() didn't renew the message lock")
+	}
+}
+
+func TestServiceBusAck_RunWithoutMessage(t *testing.T) {
+	ack := servicebus.NewServiceBusAck[[]byte, string](&mockTask[[]byte, string]{}, slog.Default(), &servicebus.ServiceBusAckOpts{MessageMetaKey: "servicebus"})
+
+	if got, err := ack.Run(context.TODO(), []byte("data"), map[string]interface{}{}, "process"); err != nil || got != "data" {
+		t.Errorf("ServiceBusAck.Run() = %s, %v, want data", got, err)
+	}
+}
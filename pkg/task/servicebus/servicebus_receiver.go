@@ -0,0 +1,108 @@
+package servicebus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	DefaultMaxMessages = 1
+)
+
+type ServiceBusReceiverOpts struct {
+	// MaxMessages received at once, they are buffered and emitted one per Run. Locks of buffered messages are already
+	// ticking, so keep it close to the number of workers (goroutines) of the pool. Defaults to DefaultMaxMessages
+	MaxMessages int
+	// PollTimeout is how long Run waits for messages before returning task.ErrNoData. Defaults to task.DefaultPollTimeout
+	PollTimeout time.Duration
+}
+
+// ServiceBusReceiver is a source task (to be used with ProducerWorker) which receives messages of an Azure Service Bus
+// queue or subscription in peek-lock mode. Every Run() returns one message, also appended on metadata under worker name,
+// to be settled with ServiceBusAck (or ServiceBusMessage Complete/Abandon/DeadLetter). Messages not settled before their
+// lock expires are delivered again.
+// When there is no message to emit it returns task.ErrNoData.
+type ServiceBusReceiver[I task.Nullable, O *ServiceBusMessage] struct {
+	receiver ReceiverAPI
+	logger   *slog.Logger
+	opts     *ServiceBusReceiverOpts
+
+	// mu serializes receiving, the receiver doesn't allow concurrent ReceiveMessages calls
+	mu     sync.Mutex
+	buffer []*ServiceBusMessage
+}
+
+// NewServiceBusReceiver creates a ServiceBusReceiver. opts is optional (nil)
+func NewServiceBusReceiver[I task.Nullable, O *ServiceBusMessage](receiver ReceiverAPI, logger *slog.Logger, opts *ServiceBusReceiverOpts) *ServiceBusReceiver[I, O] {
+	r := new(ServiceBusReceiver[I, O])
+
+	r.receiver = receiver
+	r.logger = logger
+	r.opts = opts
+
+	if r.opts == nil {
+		r.opts = &ServiceBusReceiverOpts{}
+	}
+
+	if r.opts.MaxMessages == 0 {
+		r.opts.MaxMessages = DefaultMaxMessages
+	}
+
+	if r.opts.PollTimeout == 0 {
+		r.opts.PollTimeout = task.DefaultPollTimeout
+	}
+
+	return r
+}
+
+func (r *ServiceBusReceiver[I, O]) Run(ctx context.Context, _ I, meta map[string]interface{}, name string) (O, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buffer) == 0 {
+		if err := r.receive(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(r.buffer) == 0 {
+		return nil, task.ErrNoData
+	}
+
+	msg := r.buffer[0]
+	r.buffer = r.buffer[1:]
+
+	meta[name] = msg
+
+	r.logger.Debug("service bus message received", "message_id", msg.MessageID, "delivery_count", msg.DeliveryCount)
+
+	return msg, nil
+}
+
+// receive buffers messages, waiting up to PollTimeout for the first one
+func (r *ServiceBusReceiver[I, O]) receive(ctx context.Context) error {
+	receiveCtx, cancel := context.WithTimeout(ctx, r.opts.PollTimeout)
+	defer cancel()
+
+	msgs, err := r.receiver.ReceiveMessages(receiveCtx, r.opts.MaxMessages, nil)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+
+	if err != nil {
+		r.logger.Error("error receiving service bus messages", "error", err)
+
+		return err
+	}
+
+	for _, m := range msgs {
+		r.buffer = append(r.buffer, newServiceBusMessage(m, r.receiver))
+	}
+
+	return nil
+}
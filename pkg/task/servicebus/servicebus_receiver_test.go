@@ -0,0 +1,167 @@
+package servicebus_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/servicebus"
+)
+
+// mockReceiver hands out queued messages and records how they were settled
+type mockReceiver struct {
+	mu          sync.Mutex
+	queue       []*azservicebus.ReceivedMessage
+	receiveErr  error
+	completed   []string
+	abandoned   []string
+	deadLetters []string
+	reasons     []string
+	renewals    int
+}
+
+func (m *mockReceiver) ReceiveMessages(ctx context.Context, maxMessages int, _ *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error) {
+	m.mu.Lock()
+
+	if m.receiveErr != nil {
+		defer m.mu.Unlock()
+		return nil, m.receiveErr
+	}
+
+	if len(m.queue) == 0 {
+		m.mu.Unlock()
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	defer m.mu.Unlock()
+
+	n := min(maxMessages, len(m.queue))
+	msgs := m.queue[:n]
+	m.queue = m.queue[n:]
+
+	return msgs, nil
+}
+
+func (m *mockReceiver) CompleteMessage(_ context.Context, message *azservicebus.ReceivedMessage, _ *azservicebus.CompleteMessageOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.completed = append(m.completed, message.MessageID)
+
+	return nil
+}
+
+func (m *mockReceiver) AbandonMessage(_ context.Context, message *azservicebus.ReceivedMessage, _ *azservicebus.AbandonMessageOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.abandoned = append(m.abandoned, message.MessageID)
+
+	return nil
+}
+
+func (m *mockReceiver) DeadLetterMessage(_ context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.DeadLetterOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deadLetters = append(m.deadLetters, message.MessageID)
+	m.reasons = append(m.reasons, *options.ErrorDescription)
+
+	return nil
+}
+
+func (m *mockReceiver) RenewMessageLock(_ context.Context, _ *azservicebus.ReceivedMessage, _ *azservicebus.RenewMessageLockOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.renewals++
+
+	return nil
+}
+
+func (m *mockReceiver) renewed() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.renewals
+}
+
+func received(ids ...string) []*azservicebus.ReceivedMessage {
+	subject := "orders"
+	msgs := make([]*azservicebus.ReceivedMessage, 0, len(ids))
+
+	for _, id := range ids {
+		msgs = append(msgs, &azservicebus.ReceivedMessage{MessageID: id, Body: []byte("body-" + id), Subject: &subject, DeliveryCount: 1})
+	}
+
+	return msgs
+}
+
+func TestServiceBusReceiver_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver *mockReceiver
+		opts     *servicebus.ServiceBusReceiverOpts
+		runs     int
+		wantIDs  []string
+		wantErr  error
+	}{
+		{"It receives messages", &mockReceiver{queue: received("1")}, nil, 1, []string{"1"}, nil},
+		{"It emits buffered messages one per run", &mockReceiver{queue: received("1", "2", "3")}, &servicebus.ServiceBusReceiverOpts{MaxMessages: 2}, 3, []string{"1", "2", "3"}, nil},
+		{"It returns ErrNoData without messages", &mockReceiver{}, nil, 1, nil, task.ErrNoData},
+		{"It returns receive errors", &mockReceiver{receiveErr: errors.New("connection lost")}, nil, 1, nil, errors.New("connection lost")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts == nil {
+				tt.opts = &servicebus.ServiceBusReceiverOpts{}
+			}
+
+			tt.opts.PollTimeout = 50 * time.Millisecond
+			r := servicebus.NewServiceBusReceiver(tt.receiver, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+
+			var ids []string
+
+			for i := 0; i < tt.runs; i++ {
+				meta := map[string]interface{}{}
+
+				msg, err := r.Run(context.TODO(), task.Nullable{}, meta, "servicebus")
+				if tt.wantErr != nil {
+					if err == nil || (errors.Is(tt.wantErr, task.ErrNoData) && !errors.Is(err, task.ErrNoData)) {
+						t.Errorf("ServiceBusReceiver.Run() error = %v, want %v", err, tt.wantErr)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("ServiceBusReceiver.Run() error = %v", err)
+				}
+
+				if meta["servicebus"] != msg || string(msg.Body) != "body-"+msg.MessageID || msg.Subject != "orders" {
+					t.Errorf("ServiceBusReceiver.Run() = %+v, meta %v", msg, meta)
+				}
+
+				ids = append(ids, msg.MessageID)
+			}
+
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ServiceBusReceiver.Run() ids = %v, want %v", ids, tt.wantIDs)
+			}
+
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("ServiceBusReceiver.Run() ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// GetQueueURLAPI is the subset of the SQS client needed to resolve queue URLs
+type GetQueueURLAPI interface {
+	GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
+}
+
+// ReceiveMessageAPI is the subset of the SQS client used by SQSConsumer. Satisfied by *sqs.Client
+type ReceiveMessageAPI interface {
+	GetQueueURLAPI
+	ReceiveMessage(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+}
+
+// SendMessageAPI is the subset of the SQS client used by SQSProducer. Satisfied by *sqs.Client
+type SendMessageAPI interface {
+	GetQueueURLAPI
+	SendMessage(context.Context, *sqs.SendMessageInput, ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// DeleteMessageAPI is the subset of the SQS client used by SQSDeleter. Satisfied by *sqs.Client
+type DeleteMessageAPI interface {
+	GetQueueURLAPI
+	DeleteMessage(context.Context, *sqs.DeleteMessageInput, ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
@@ -0,0 +1,199 @@
+package sqs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/s3"
+)
+
+const (
+	// DefaultPayloadSizeThreshold is the SQS maximum message size (256 KB)
+	DefaultPayloadSizeThreshold = 262144
+	// PayloadS3PointerClass is the class name used by AWS extended clients to mark a pointer message
+	PayloadS3PointerClass = "software.amazon.payloadoffloading.PayloadS3Pointer"
+	// LegacyPayloadS3PointerClass is the class name used by older versions (1.x) of the extended clients
+	LegacyPayloadS3PointerClass = "com.amazon.sqs.javamessaging.MessageS3Pointer"
+	// ExtendedPayloadSizeAttribute is the message attribute carrying the original payload size
+	ExtendedPayloadSizeAttribute = "ExtendedPayloadSize"
+	// LegacyPayloadSizeAttribute is the attribute used by older versions (1.x) of the extended clients
+	LegacyPayloadSizeAttribute = "SQSLargePayloadSize"
+)
+
+var (
+	ErrPayloadBucketMismatch = errors.New("payload pointer bucket differs from configured bucket")
+	// ErrInvalidPayloadPointer is logged (skipping the message) when it has a payload size attribute but its body isn't a pointer
+	ErrInvalidPayloadPointer = errors.New("large payload message without a valid s3 pointer")
+)
+
+// PayloadS3Pointer is the reference sent on SQS when the real body is stored on S3.
+// It follows the format used by the AWS SQS Extended Client libraries.
+type PayloadS3Pointer struct {
+	BucketName string `json:"s3BucketName"`
+	Key        string `json:"s3Key"`
+}
+
+// SQSLargePayloadProducerOpts configures the offloading of large bodies to S3 on SQSProducer
+type SQSLargePayloadProducerOpts struct {
+	// Uploader used to store the body on S3, usually a *s3.S3Uploader
+	Uploader task.Task[*s3.S3UploaderInput, task.Nullable]
+	// BucketName must be the same bucket configured on Uploader, it is sent on the pointer message
+	BucketName string
+	// Threshold in bytes (body + attributes) after which the body is offloaded. Defaults to DefaultPayloadSizeThreshold
+	Threshold int
+	// AlwaysThroughS3 offloads every message regardless of its size
+	AlwaysThroughS3 bool
+	// KeyPrefix prepended to the generated object key
+	KeyPrefix string
+}
+
+// SQSLargePayloadConsumerOpts configures the resolution of pointer messages on SQSConsumer
+type SQSLargePayloadConsumerOpts struct {
+	// Downloader used to fetch the body from S3, usually a *s3.S3Downloader
+	Downloader task.Task[string, *s3.S3DownloaderOutput]
+	// BucketName configured on Downloader, messages pointing to other buckets are skipped with ErrPayloadBucketMismatch
+	BucketName string
+}
+
+func (o *SQSLargePayloadProducerOpts) threshold() int {
+	if o.Threshold <= 0 {
+		return DefaultPayloadSizeThreshold
+	}
+
+	return o.Threshold
+}
+
+func (o *SQSLargePayloadProducerOpts) shouldOffload(body string, attrs map[string]types.MessageAttributeValue) bool {
+	if o.AlwaysThroughS3 {
+		return true
+	}
+
+	return messageSize(body, attrs) > o.threshold()
+}
+
+// offload uploads body to S3 and returns the pointer body and attributes to be sent instead
+func (o *SQSLargePayloadProducerOpts) offload(ctx context.Context, body string, attrs map[string]types.MessageAttributeValue, meta map[string]interface{}, name string) (string, map[string]types.MessageAttributeValue, error) {
+	key, err := newPayloadKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key = o.KeyPrefix + key
+
+	_, err = o.Uploader.Run(ctx, &s3.S3UploaderInput{Path: key, Content: []byte(body)}, meta, name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pointer, err := encodePointer(&PayloadS3Pointer{BucketName: o.BucketName, Key: key})
+	if err != nil {
+		return "", nil, err
+	}
+
+	newAttrs := make(map[string]types.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		newAttrs[k] = v
+	}
+
+	newAttrs[ExtendedPayloadSizeAttribute] = types.MessageAttributeValue{
+		DataType:    aws.String("Number"),
+		StringValue: aws.String(strconv.Itoa(len(body))),
+	}
+
+	return pointer, newAttrs, nil
+}
+
+// resolve downloads the body referenced by pointer
+func (o *SQSLargePayloadConsumerOpts) resolve(ctx context.Context, pointer *PayloadS3Pointer, meta map[string]interface{}, name string) (string, error) {
+	if o.BucketName != "" && pointer.BucketName != o.BucketName {
+		return "", fmt.Errorf("%w: %s", ErrPayloadBucketMismatch, pointer.BucketName)
+	}
+
+	out, err := o.Downloader.Run(ctx, pointer.Key, meta, name)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out.Data), nil
+}
+
+// messageSize calculates message size the same way AWS extended clients does (body + attributes)
+func messageSize(body string, attrs map[string]types.MessageAttributeValue) int {
+	size := len(body)
+
+	for name, attr := range attrs {
+		size += len(name)
+
+		if attr.DataType != nil {
+			size += len(*attr.DataType)
+		}
+
+		if attr.StringValue != nil {
+			size += len(*attr.StringValue)
+		}
+
+		size += len(attr.BinaryValue)
+	}
+
+	return size
+}
+
+// encodePointer encodes the pointer as ["<class>", {"s3BucketName": "...", "s3Key": "..."}]
+func encodePointer(p *PayloadS3Pointer) (string, error) {
+	b, err := json.Marshal([]interface{}{PayloadS3PointerClass, p})
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// isLargePayload checks the payload size attribute extended clients (current or legacy) add to pointer messages
+func isLargePayload(attrs map[string]types.MessageAttributeValue) bool {
+	_, extended := attrs[ExtendedPayloadSizeAttribute]
+	_, legacy := attrs[LegacyPayloadSizeAttribute]
+
+	return extended || legacy
+}
+
+// decodePointer returns the pointer contained on body (current or legacy class) or nil if body isn't a pointer message
+func decodePointer(body string) *PayloadS3Pointer {
+	var parts []json.RawMessage
+
+	if err := json.Unmarshal([]byte(body), &parts); err != nil || len(parts) != 2 {
+		return nil
+	}
+
+	var class string
+	if err := json.Unmarshal(parts[0], &class); err != nil || (class != PayloadS3PointerClass && class != LegacyPayloadS3PointerClass) {
+		return nil
+	}
+
+	pointer := new(PayloadS3Pointer)
+	if err := json.Unmarshal(parts[1], pointer); err != nil || pointer.Key == "" {
+		return nil
+	}
+
+	return pointer
+}
+
+// newPayloadKey generates a random UUID (v4) to be used as object key
+func newPayloadKey() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,265 @@
+package sqs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"github.com/otaviohenrique/vecna/pkg/task/s3"
+	"github.com/otaviohenrique/vecna/pkg/task/sqs"
+)
+
+type mockPayloadStore struct {
+	objects map[string][]byte
+	WantErr bool
+}
+
+func (m *mockPayloadStore) Run(_ context.Context, input *s3.S3UploaderInput, _ map[string]interface{}, _ string) (task.Nullable, error) {
+	if m.WantErr {
+		return task.Nullable{}, errors.New("upload-error")
+	}
+
+	m.objects[input.Path] = input.Content
+
+	return task.Nullable{}, nil
+}
+
+type mockPayloadDownloader struct {
+	store *mockPayloadStore
+}
+
+func (m *mockPayloadDownloader) Run(_ context.Context, key string, _ map[string]interface{}, _ string) (*s3.S3DownloaderOutput, error) {
+	data, ok := m.store.objects[key]
+	if !ok {
+		return nil, errors.New("not-found")
+	}
+
+	return &s3.S3DownloaderOutput{Data: data}, nil
+}
+
+func TestSQSProducer_RunLargePayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		threshold   int
+		always      bool
+		uploadErr   bool
+		wantPointer bool
+		wantErr     bool
+	}{
+		{"It sends small messages directly", "small", 10, false, false, false, false},
+		{"It offloads messages bigger than threshold", strings.Repeat("a", 11), 10, false, false, true, false},
+		{"It offloads every message when AlwaysThroughS3", "small", 10, true, false, true, false},
+		{"It returns error when upload fails", strings.Repeat("a", 11), 10, false, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockPayloadStore{objects: map[string][]byte{}, WantErr: tt.uploadErr}
+			client := &MockSQSProducer{}
+
+			p, err := sqs.NewSQSProducer(client, slog.New(slog.NewTextHandler(os.Stdout, nil)), &sqs.SQSProducerOpts{
+				QueueName: "queue-name",
+				LargePayload: &sqs.SQSLargePayloadProducerOpts{
+					Uploader:        store,
+					BucketName:      "bucket",
+					Threshold:       tt.threshold,
+					AlwaysThroughS3: tt.always,
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewSQSProducer() error = %v", err)
+			}
+
+			_, err = p.Run(context.TODO(), sqs.SQSProducerInput{Body: tt.body}, map[string]interface{}{}, "worker")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SQSProducer.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			sent := client.CalledWith[0]
+			_, hasSize := sent.MessageAttributes[sqs.ExtendedPayloadSizeAttribute]
+
+			if !tt.wantPointer {
+				if *sent.MessageBody != tt.body || hasSize {
+					t.Errorf("SQSProducer.Run() sent = %v, want original body %v", *sent.MessageBody, tt.body)
+				}
+				return
+			}
+
+			if !strings.Contains(*sent.MessageBody, sqs.PayloadS3PointerClass) || !hasSize {
+				t.Errorf("SQSProducer.Run() sent = %v, want pointer message", *sent.MessageBody)
+			}
+
+			if len(store.objects) != 1 {
+				t.Errorf("SQSProducer.Run() stored %d objects, want 1", len(store.objects))
+			}
+		})
+	}
+}
+
+func TestSQSConsumer_RunLargePayload(t *testing.T) {
+	store := &mockPayloadStore{objects: map[string][]byte{"obj-key": []byte("large-body")}}
+
+	sizeAttr := func(name string) map[string]types.MessageAttributeValue {
+		return map[string]types.MessageAttributeValue{name: {DataType: aws.String("Number"), StringValue: aws.String("10")}}
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		attrs       map[string]types.MessageAttributeValue
+		bucket      string
+		want        string
+		wantPointer bool
+		wantSkip    bool
+	}{
+		{"It keeps regular messages", "regular-body", nil, "bucket", "regular-body", false, false},
+		{"It resolves pointer messages", `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"obj-key"}]`, sizeAttr(sqs.ExtendedPayloadSizeAttribute), "bucket", "large-body", true, false},
+		{"It resolves pointer messages without size attribute", `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"obj-key"}]`, nil, "bucket", "large-body", true, false},
+		{"It resolves legacy pointer messages", `["com.amazon.sqs.javamessaging.MessageS3Pointer",{"s3BucketName":"bucket","s3Key":"obj-key"}]`, sizeAttr(sqs.LegacyPayloadSizeAttribute), "bucket", "large-body", true, false},
+		{"It skips message when a size attribute comes without pointer", "not-a-pointer", sizeAttr(sqs.LegacyPayloadSizeAttribute), "bucket", "", false, true},
+		{"It skips message when pointer bucket differs", `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"other","s3Key":"obj-key"}]`, nil, "bucket", "", false, true},
+		{"It skips message when object doesn't exist", `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"missing"}]`, nil, "bucket", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := sqs.NewSQSConsumer(
+				&MockSQS{ExpectedResponse: tt.body, ReceiptHandle: "receipt", Attributes: tt.attrs},
+				slog.New(slog.NewTextHandler(os.Stdout, nil)),
+				&sqs.SQSConsumerOpts{
+					QueueName:           "queue",
+					MaxNumberOfMessages: 1,
+					LargePayload: &sqs.SQSLargePayloadConsumerOpts{
+						Downloader: &mockPayloadDownloader{store: store},
+						BucketName: tt.bucket,
+					},
+				},
+			)
+			if err != nil {
+				t.Fatalf("NewSQSConsumer() error = %v", err)
+			}
+
+			got, err := c.Run(context.TODO(), nil, map[string]interface{}{}, "worker")
+			if err != nil {
+				t.Errorf("SQSConsumer.Run() error = %v", err)
+				return
+			}
+
+			if tt.wantSkip {
+				if len(got) != 0 {
+					t.Errorf("SQSConsumer.Run() returned %d messages, want the message skipped", len(got))
+				}
+				return
+			}
+
+			if *got[0].Content != tt.want {
+				t.Errorf("SQSConsumer.Run() = %v, want %v", *got[0].Content, tt.want)
+			}
+
+			if (got[0].PayloadPointer != nil) != tt.wantPointer {
+				t.Errorf("SQSConsumer.Run() pointer = %v, wantPointer %v", got[0].PayloadPointer, tt.wantPointer)
+			}
+		})
+	}
+}
+
+func TestSQSConsumer_RunSkipsOnlyInvalidPayload(t *testing.T) {
+	store := &mockPayloadStore{objects: map[string][]byte{"obj-key": []byte("large-body")}}
+
+	c, err := sqs.NewSQSConsumer(
+		&MockSQS{Messages: []types.Message{
+			{Body: aws.String(`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"missing"}]`), ReceiptHandle: aws.String("bad")},
+			{Body: aws.String(`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"obj-key"}]`), ReceiptHandle: aws.String("good")},
+			{Body: aws.String("regular-body"), ReceiptHandle: aws.String("regular")},
+		}},
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		&sqs.SQSConsumerOpts{
+			QueueName: "queue",
+			LargePayload: &sqs.SQSLargePayloadConsumerOpts{
+				Downloader: &mockPayloadDownloader{store: store},
+				BucketName: "bucket",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewSQSConsumer() error = %v", err)
+	}
+
+	meta := map[string]interface{}{}
+
+	got, err := c.Run(context.TODO(), nil, meta, "worker")
+	if err != nil {
+		t.Fatalf("SQSConsumer.Run() error = %v", err)
+	}
+
+	if len(got) != 2 || *got[0].Content != "large-body" || *got[1].Content != "regular-body" {
+		t.Fatalf("SQSConsumer.Run() = %v, want the resolved and the regular messages", got)
+	}
+
+	receipts := meta["worker"].(map[string][]string)["receiptHandlers"]
+	if !reflect.DeepEqual(receipts, []string{"good", "regular"}) {
+		t.Errorf("SQSConsumer.Run() receipts = %v, want the skipped message left on the queue", receipts)
+	}
+}
+
+func TestSQSLargePayload_RoundTrip(t *testing.T) {
+	store := &mockPayloadStore{objects: map[string][]byte{}}
+	producerClient := &MockSQSProducer{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	p, err := sqs.NewSQSProducer(producerClient, logger, &sqs.SQSProducerOpts{
+		QueueName: "queue",
+		LargePayload: &sqs.SQSLargePayloadProducerOpts{
+			Uploader:        store,
+			BucketName:      "bucket",
+			AlwaysThroughS3: true,
+			KeyPrefix:       "payloads/",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSQSProducer() error = %v", err)
+	}
+
+	msgAtt := map[string]types.MessageAttributeValue{"Meaning": {DataType: aws.String("String"), StringValue: aws.String("42")}}
+
+	if _, err := p.Run(context.TODO(), sqs.SQSProducerInput{Body: "round-trip", MsgAtt: msgAtt}, map[string]interface{}{}, "producer"); err != nil {
+		t.Fatalf("SQSProducer.Run() error = %v", err)
+	}
+
+	sent := producerClient.CalledWith[0]
+	if _, ok := sent.MessageAttributes["Meaning"]; !ok {
+		t.Errorf("SQSProducer.Run() dropped user message attributes")
+	}
+
+	c, err := sqs.NewSQSConsumer(&MockSQS{ExpectedResponse: *sent.MessageBody, ReceiptHandle: "receipt"}, logger, &sqs.SQSConsumerOpts{
+		QueueName:    "queue",
+		LargePayload: &sqs.SQSLargePayloadConsumerOpts{Downloader: &mockPayloadDownloader{store: store}, BucketName: "bucket"},
+	})
+	if err != nil {
+		t.Fatalf("NewSQSConsumer() error = %v", err)
+	}
+
+	got, err := c.Run(context.TODO(), nil, map[string]interface{}{}, "consumer")
+	if err != nil {
+		t.Fatalf("SQSConsumer.Run() error = %v", err)
+	}
+
+	if *got[0].Content != "round-trip" {
+		t.Errorf("SQSConsumer.Run() = %v, want %v", *got[0].Content, "round-trip")
+	}
+
+	if !strings.HasPrefix(got[0].PayloadPointer.Key, "payloads/") {
+		t.Errorf("SQSConsumer.Run() pointer key = %v, want prefix payloads/", got[0].PayloadPointer.Key)
+	}
+}
@@ -0,0 +1,102 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+var (
+	ErrMissingQueue = errors.New("either QueueName or QueueURL must be given")
+)
+
+// QueueURLRetryOpts configures how many times and how often queue URL resolution will be retried
+type QueueURLRetryOpts struct {
+	// Attempts to call GetQueueUrl before giving up. Defaults to 1
+	Attempts int
+	// Interval between attempts
+	Interval time.Duration
+}
+
+// queueURLResolver resolves (and caches) a queue URL based on its name
+type queueURLResolver struct {
+	client    GetQueueURLAPI
+	queueName string
+	retry     *QueueURLRetryOpts
+	queueURL  *string
+	mu        sync.Mutex
+}
+
+func newQueueURLResolver(client GetQueueURLAPI, queueName string, queueURL string, retry *QueueURLRetryOpts) (*queueURLResolver, error) {
+	if queueName == "" && queueURL == "" {
+		return nil, ErrMissingQueue
+	}
+
+	r := new(queueURLResolver)
+
+	r.client = client
+	r.queueName = queueName
+	r.retry = retry
+
+	if queueURL != "" {
+		r.queueURL = aws.String(queueURL)
+	}
+
+	return r, nil
+}
+
+func (r *queueURLResolver) attempts() int {
+	if r.retry == nil || r.retry.Attempts < 1 {
+		return 1
+	}
+
+	return r.retry.Attempts
+}
+
+func (r *queueURLResolver) interval() time.Duration {
+	if r.retry == nil {
+		return 0
+	}
+
+	return r.retry.Interval
+}
+
+// get returns the cached queue URL or resolves it calling GetQueueUrl, retrying as configured
+func (r *queueURLResolver) get(ctx context.Context) (*string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.queueURL != nil {
+		return r.queueURL, nil
+	}
+
+	var err error
+
+	for i := 0; i < r.attempts(); i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(r.interval()):
+			}
+		}
+
+		var urlResult *sqs.GetQueueUrlOutput
+
+		urlResult, err = r.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+			QueueName: aws.String(r.queueName),
+		})
+
+		if err == nil {
+			r.queueURL = urlResult.QueueUrl
+
+			return r.queueURL, nil
+		}
+	}
+
+	return nil, err
+}
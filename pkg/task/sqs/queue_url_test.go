@@ -0,0 +1,85 @@
+package sqs_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/otaviohenrique/vecna/pkg/task/sqs"
+)
+
+type MockSQSQueueURL struct {
+	// FailCount is how many GetQueueUrl calls will fail before succeeding
+	FailCount     int
+	GetURLCalls   int
+	DeletedOnURLs []string
+}
+
+func (s *MockSQSQueueURL) GetQueueUrl(_ context.Context, input *awsSqs.GetQueueUrlInput, _ ...func(*awsSqs.Options)) (*awsSqs.GetQueueUrlOutput, error) {
+	s.GetURLCalls++
+
+	if s.GetURLCalls <= s.FailCount {
+		return nil, errors.New("queue-does-not-exist")
+	}
+
+	return &awsSqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs/" + *input.QueueName)}, nil
+}
+
+func (s *MockSQSQueueURL) DeleteMessage(_ context.Context, input *awsSqs.DeleteMessageInput, _ ...func(*awsSqs.Options)) (*awsSqs.DeleteMessageOutput, error) {
+	s.DeletedOnURLs = append(s.DeletedOnURLs, *input.QueueUrl)
+
+	return &awsSqs.DeleteMessageOutput{}, nil
+}
+
+func TestSQSQueueURLResolution(t *testing.T) {
+	tests := []struct {
+		name            string
+		client          *MockSQSQueueURL
+		opts            *sqs.SQSDeleterOpts
+		wantCtorErr     bool
+		wantRunErr      bool
+		wantURL         string
+		wantGetURLCalls int
+	}{
+		{"It returns error on constructor when queue url can't be resolved", &MockSQSQueueURL{FailCount: 1},
+			&sqs.SQSDeleterOpts{QueueName: "queue"}, true, false, "", 1},
+		{"It returns error when neither name or url is given", &MockSQSQueueURL{},
+			&sqs.SQSDeleterOpts{}, true, false, "", 0},
+		{"It uses QueueURL directly without calling GetQueueUrl", &MockSQSQueueURL{},
+			&sqs.SQSDeleterOpts{QueueURL: "https://sqs/direct"}, false, false, "https://sqs/direct", 0},
+		{"It retries queue url resolution on constructor", &MockSQSQueueURL{FailCount: 2},
+			&sqs.SQSDeleterOpts{QueueName: "queue", QueueURLRetry: &sqs.QueueURLRetryOpts{Attempts: 3, Interval: time.Millisecond}}, false, false, "https://sqs/queue", 3},
+		{"It resolves lazily on Run", &MockSQSQueueURL{FailCount: 1},
+			&sqs.SQSDeleterOpts{QueueName: "queue", LazyQueueURL: true, QueueURLRetry: &sqs.QueueURLRetryOpts{Attempts: 2}}, false, false, "https://sqs/queue", 2},
+		{"It returns error on Run when lazy resolution fails", &MockSQSQueueURL{FailCount: 5},
+			&sqs.SQSDeleterOpts{QueueName: "queue", LazyQueueURL: true}, false, true, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := sqs.NewSQSDeleter(tt.client, slog.New(slog.NewTextHandler(os.Stdout, nil)), tt.opts)
+			if (err != nil) != tt.wantCtorErr {
+				t.Fatalf("NewSQSDeleter() error = %v, wantErr %v", err, tt.wantCtorErr)
+			}
+
+			if !tt.wantCtorErr {
+				_, err = d.Run(context.TODO(), "receipt", map[string]interface{}{}, "worker")
+				if (err != nil) != tt.wantRunErr {
+					t.Fatalf("SQSDeleter.Run() error = %v, wantErr %v", err, tt.wantRunErr)
+				}
+
+				if !tt.wantRunErr && tt.client.DeletedOnURLs[0] != tt.wantURL {
+					t.Errorf("SQSDeleter.Run() used url = %v, want %v", tt.client.DeletedOnURLs[0], tt.wantURL)
+				}
+			}
+
+			if tt.client.GetURLCalls != tt.wantGetURLCalls {
+				t.Errorf("GetQueueUrl called %d times, want %d", tt.client.GetURLCalls, tt.wantGetURLCalls)
+			}
+		})
+	}
+}
@@ -3,11 +3,9 @@ package sqs
 import (
 	"context"
 	"log/slog"
-	"os"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 type SQSConsumerOutput struct {
@@ -15,15 +13,25 @@ type SQSConsumerOutput struct {
 	Content *string
 	// It receipt handler
 	ReceiptHandle string
+	// PayloadPointer is filled when Content was resolved from S3 (large payload), nil otherwise
+	PayloadPointer *PayloadS3Pointer
 }
 
 type SQSConsumerOpts struct {
 	// QueueName which Consumer will use to get QueueURL
 	QueueName string
+	// QueueURL of the queue. When given QueueName isn't used to resolve it
+	QueueURL string
+	// LazyQueueURL postpones queue URL resolution to the first Run instead of the constructor
+	LazyQueueURL bool
+	// QueueURLRetry configures retries of queue URL resolution (optional)
+	QueueURLRetry *QueueURLRetryOpts
 	// VisibilityTimeout to be used
-	VisibilityTimeout int64
+	VisibilityTimeout int32
 	// MaxNumberOfMessages to Get when called. Max 10 on normal queues (SQS API max)
-	MaxNumberOfMessages int64
+	MaxNumberOfMessages int32
+	// LargePayload when given resolves pointer messages (produced by SQSProducer or AWS extended clients) downloading its body from S3
+	LargePayload *SQSLargePayloadConsumerOpts
 }
 
 // SQS Consumer is a Task that when called, get messages from a SQS queue and return them.
@@ -31,49 +39,67 @@ type SQSConsumerOpts struct {
 // Exclude messages based on receipt handler is user responsability
 type SQSConsumer[I []byte, O []*SQSConsumerOutput] struct {
 	// SQS AWS client to be used
-	client   sqsiface.SQSAPI
+	client   ReceiveMessageAPI
 	logger   *slog.Logger
 	opts     *SQSConsumerOpts
-	queueURL *string
+	queueURL *queueURLResolver
 }
 
-func NewSQSConsumer[I []byte, O []*SQSConsumerOutput](client sqsiface.SQSAPI, logger *slog.Logger, opts *SQSConsumerOpts) *SQSConsumer[I, O] {
+// NewSQSConsumer creates a SQSConsumer. Unless QueueURL or LazyQueueURL is given, queue URL is resolved here
+// and an error is returned if it can't be resolved.
+func NewSQSConsumer[I []byte, O []*SQSConsumerOutput](client ReceiveMessageAPI, logger *slog.Logger, opts *SQSConsumerOpts) (*SQSConsumer[I, O], error) {
 	c := new(SQSConsumer[I, O])
 
 	c.client = client
 	c.logger = logger
 	c.opts = opts
-	c.queueURL = c.GetQueueURL()
 
-	return c
-}
+	resolver, err := newQueueURLResolver(client, opts.QueueName, opts.QueueURL, opts.QueueURLRetry)
+	if err != nil {
+		return nil, err
+	}
 
-func (c *SQSConsumer[I, O]) GetQueueURL() *string {
-	urlResult, err := c.client.GetQueueUrl(&sqs.GetQueueUrlInput{
-		QueueName: aws.String(c.opts.QueueName),
-	})
+	c.queueURL = resolver
+
+	if !opts.LazyQueueURL {
+		if _, err := c.GetQueueURL(context.Background()); err != nil {
+			return nil, err
+		}
+	}
 
+	return c, nil
+}
+
+// GetQueueURL returns the queue URL, resolving it when not resolved yet
+func (c *SQSConsumer[I, O]) GetQueueURL(ctx context.Context) (*string, error) {
+	url, err := c.queueURL.get(ctx)
 	if err != nil {
-		c.logger.Error("can't get queue name", "error", err)
+		c.logger.Error("can't get queue url", "error", err, "queue", c.opts.QueueName)
 
-		os.Exit(1)
+		return nil, err
 	}
 
-	return urlResult.QueueUrl
+	return url, nil
 }
 
-func (c *SQSConsumer[I, O]) maxNumberOfMessages() int64 {
+func (c *SQSConsumer[I, O]) maxNumberOfMessages() int32 {
 	return c.opts.MaxNumberOfMessages
 }
 
-func (c *SQSConsumer[I, O]) visibilityTimeout() int64 {
+func (c *SQSConsumer[I, O]) visibilityTimeout() int32 {
 	return c.opts.VisibilityTimeout
 }
 
 // Run when called consume messages from SQS and return TaskData with Data containing an array of *SQSConsumerOutput
-// It appends receipt handlers to metadata to be excluded later by user
-func (c *SQSConsumer[I, O]) Run(_ context.Context, _ interface{}, meta map[string]interface{}, name string) (O, error) {
-	msgs, err := c.receiveMessages(c.queueURL)
+// It appends receipt handlers to metadata to be excluded later by user.
+// Large payload messages which can't be resolved are skipped (logged and not returned), the others are returned
+func (c *SQSConsumer[I, O]) Run(ctx context.Context, _ interface{}, meta map[string]interface{}, name string) (O, error) {
+	queueURL, err := c.GetQueueURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := c.receiveMessages(ctx, queueURL)
 
 	if err != nil {
 		c.logger.Error("error receiving messages", "error", err)
@@ -86,6 +112,12 @@ func (c *SQSConsumer[I, O]) Run(_ context.Context, _ interface{}, meta map[strin
 	for i := 0; i < len(msgs); i++ {
 		resp := SQSConsumerOutput{Content: msgs[i].Body, ReceiptHandle: *msgs[i].ReceiptHandle}
 
+		// a message which can't be resolved is left on the queue, to be redelivered (or moved by the redrive policy)
+		if err := c.resolvePayload(ctx, &resp, msgs[i].MessageAttributes, meta, name); err != nil {
+			c.logger.Error("error resolving large payload, skipping message", "error", err, "receiptHandle", resp.ReceiptHandle)
+			continue
+		}
+
 		receiptsHandler = append(receiptsHandler, *msgs[i].ReceiptHandle)
 		messagesOutput = append(messagesOutput, &resp)
 	}
@@ -94,17 +126,43 @@ func (c *SQSConsumer[I, O]) Run(_ context.Context, _ interface{}, meta map[strin
 	return messagesOutput, nil
 }
 
-func (c *SQSConsumer[I, O]) receiveMessages(queueUrl *string) ([]*sqs.Message, error) {
-	msgResult, err := c.client.ReceiveMessage(&sqs.ReceiveMessageInput{
-		AttributeNames: []*string{
-			aws.String(sqs.MessageSystemAttributeNameSentTimestamp),
+func (c *SQSConsumer[I, O]) resolvePayload(ctx context.Context, out *SQSConsumerOutput, attrs map[string]types.MessageAttributeValue, meta map[string]interface{}, name string) error {
+	if c.opts.LargePayload == nil || out.Content == nil {
+		return nil
+	}
+
+	pointer := decodePointer(*out.Content)
+	if pointer == nil {
+		// a size attribute without pointer would hand the S3 reference (or garbage) over as content
+		if isLargePayload(attrs) {
+			return ErrInvalidPayloadPointer
+		}
+
+		return nil
+	}
+
+	body, err := c.opts.LargePayload.resolve(ctx, pointer, meta, name)
+	if err != nil {
+		return err
+	}
+
+	out.Content = &body
+	out.PayloadPointer = pointer
+
+	return nil
+}
+
+func (c *SQSConsumer[I, O]) receiveMessages(ctx context.Context, queueUrl *string) ([]types.Message, error) {
+	msgResult, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameSentTimestamp,
 		},
-		MessageAttributeNames: []*string{
-			aws.String(sqs.QueueAttributeNameAll),
+		MessageAttributeNames: []string{
+			string(types.QueueAttributeNameAll),
 		},
 		QueueUrl:            queueUrl,
-		MaxNumberOfMessages: aws.Int64(c.maxNumberOfMessages()),
-		VisibilityTimeout:   aws.Int64(c.visibilityTimeout()),
+		MaxNumberOfMessages: c.maxNumberOfMessages(),
+		VisibilityTimeout:   c.visibilityTimeout(),
 	})
 
 	if err != nil {
@@ -8,30 +8,32 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/aws/aws-sdk-go/aws"
-	awsSqs "github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/otaviohenrique/vecna/pkg/task/sqs"
 )
 
 type MockSQS struct {
-	sqsiface.SQSAPI
 	QueueURL         string
 	calledWith       []awsSqs.ReceiveMessageInput
 	ExpectedResponse string
 	ReceiptHandle    string
+	Attributes       map[string]types.MessageAttributeValue
 	receiveCount     int
 	WantErr          bool
+	// Messages when given are received instead of a single ExpectedResponse message
+	Messages []types.Message
 }
 
-func (s *MockSQS) GetQueueUrl(input *awsSqs.GetQueueUrlInput) (*awsSqs.GetQueueUrlOutput, error) {
+func (s *MockSQS) GetQueueUrl(_ context.Context, input *awsSqs.GetQueueUrlInput, _ ...func(*awsSqs.Options)) (*awsSqs.GetQueueUrlOutput, error) {
 	output := new(awsSqs.GetQueueUrlOutput)
 	output.QueueUrl = aws.String(*input.QueueName)
 
 	return output, nil
 }
 
-func (s *MockSQS) ReceiveMessage(input *awsSqs.ReceiveMessageInput) (*awsSqs.ReceiveMessageOutput, error) {
+func (s *MockSQS) ReceiveMessage(_ context.Context, input *awsSqs.ReceiveMessageInput, _ ...func(*awsSqs.Options)) (*awsSqs.ReceiveMessageOutput, error) {
 	if s.WantErr == true {
 		return nil, errors.New("test-err")
 	}
@@ -41,15 +43,19 @@ func (s *MockSQS) ReceiveMessage(input *awsSqs.ReceiveMessageInput) (*awsSqs.Rec
 	s.receiveCount++
 
 	output := new(awsSqs.ReceiveMessageOutput)
-	msg := awsSqs.Message{Body: aws.String(s.ExpectedResponse), ReceiptHandle: aws.String(s.ReceiptHandle)}
+	msg := types.Message{Body: aws.String(s.ExpectedResponse), ReceiptHandle: aws.String(s.ReceiptHandle), MessageAttributes: s.Attributes}
+
+	output.Messages = []types.Message{msg}
+	if s.Messages != nil {
+		output.Messages = s.Messages
+	}
 
-	output.Messages = []*awsSqs.Message{&msg}
 	return output, nil
 }
 
 func TestSQSConsumer_Run(t *testing.T) {
 	type fields struct {
-		client sqsiface.SQSAPI
+		client sqs.ReceiveMessageAPI
 		logger *slog.Logger
 		opts   *sqs.SQSConsumerOpts
 	}
@@ -88,11 +94,14 @@ func TestSQSConsumer_Run(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := sqs.NewSQSConsumer(
+			c, err := sqs.NewSQSConsumer(
 				tt.fields.client,
 				tt.fields.logger,
 				tt.fields.opts,
 			)
+			if err != nil {
+				t.Fatalf("NewSQSConsumer() error = %v", err)
+			}
 
 			got, err := c.Run(tt.args.in0, tt.args.in1, tt.args.meta, "worker")
 			if (err != nil) != tt.wantErr {
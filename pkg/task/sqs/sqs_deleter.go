@@ -3,56 +3,77 @@ package sqs
 import (
 	"context"
 	"log/slog"
-	"os"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/otaviohenrique/vecna/pkg/task"
 )
 
 type SQSDeleterOpts struct {
 	// SQS queue name which Deleter will use to get queue url
 	QueueName string
+	// QueueURL of the queue. When given QueueName isn't used to resolve it
+	QueueURL string
+	// LazyQueueURL postpones queue URL resolution to the first Run instead of the constructor
+	LazyQueueURL bool
+	// QueueURLRetry configures retries of queue URL resolution (optional)
+	QueueURLRetry *QueueURLRetryOpts
 }
 
 // SQSDeleter will delete a message on SQS based on a given receiptHandle
 type SQSDeleter[I string, O task.Nullable] struct {
 	// SQS AWS Client to be used
-	client   sqsiface.SQSAPI
+	client   DeleteMessageAPI
 	logger   *slog.Logger
 	opts     *SQSDeleterOpts
-	queueURL *string
+	queueURL *queueURLResolver
 }
 
-func NewSQSDeleter[I string, O task.Nullable](client sqsiface.SQSAPI, logger *slog.Logger, opts *SQSDeleterOpts) *SQSDeleter[I, O] {
+// NewSQSDeleter creates a SQSDeleter. Unless QueueURL or LazyQueueURL is given, queue URL is resolved here
+// and an error is returned if it can't be resolved.
+func NewSQSDeleter[I string, O task.Nullable](client DeleteMessageAPI, logger *slog.Logger, opts *SQSDeleterOpts) (*SQSDeleter[I, O], error) {
 	c := new(SQSDeleter[I, O])
 
 	c.client = client
 	c.logger = logger
 	c.opts = opts
-	c.queueURL = c.GetQueueURL()
 
-	return c
-}
+	resolver, err := newQueueURLResolver(client, opts.QueueName, opts.QueueURL, opts.QueueURLRetry)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *SQSDeleter[I, O]) GetQueueURL() *string {
-	urlResult, err := s.client.GetQueueUrl(&sqs.GetQueueUrlInput{
-		QueueName: aws.String(s.opts.QueueName),
-	})
+	c.queueURL = resolver
+
+	if !opts.LazyQueueURL {
+		if _, err := c.GetQueueURL(context.Background()); err != nil {
+			return nil, err
+		}
+	}
 
+	return c, nil
+}
+
+// GetQueueURL returns the queue URL, resolving it when not resolved yet
+func (s *SQSDeleter[I, O]) GetQueueURL(ctx context.Context) (*string, error) {
+	url, err := s.queueURL.get(ctx)
 	if err != nil {
-		s.logger.Error("can't get queue name", "error", err)
+		s.logger.Error("can't get queue url", "error", err, "queue", s.opts.QueueName)
 
-		os.Exit(1)
+		return nil, err
 	}
 
-	return urlResult.QueueUrl
+	return url, nil
 }
 
 // Run() delete a message on SQS based on the return of adaptFn. It only returns errors
-func (s *SQSDeleter[I, O]) Run(_ context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
-	_, err := s.deleteMessage(string(input))
+func (s *SQSDeleter[I, O]) Run(ctx context.Context, input I, meta map[string]interface{}, _ string) (O, error) {
+	queueURL, err := s.GetQueueURL(ctx)
+	if err != nil {
+		return O(task.Nullable{}), err
+	}
+
+	_, err = s.deleteMessage(ctx, queueURL, string(input))
 
 	if err != nil {
 		return O(task.Nullable{}), err
@@ -63,9 +84,9 @@ func (s *SQSDeleter[I, O]) Run(_ context.Context, input I, meta map[string]inter
 	return O(task.Nullable{}), nil
 }
 
-func (s *SQSDeleter[I, O]) deleteMessage(receipt string) (*sqs.DeleteMessageOutput, error) {
-	resp, err := s.client.DeleteMessage(&sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(*s.queueURL),
+func (s *SQSDeleter[I, O]) deleteMessage(ctx context.Context, queueURL *string, receipt string) (*sqs.DeleteMessageOutput, error) {
+	resp, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      queueURL,
 		ReceiptHandle: aws.String(receipt),
 	})
 
@@ -7,27 +7,25 @@ import (
 	"os"
 	"testing"
 
-	"github.com/aws/aws-sdk-go/aws"
-	awsSqs "github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/otaviohenrique/vecna/pkg/task"
 	"github.com/otaviohenrique/vecna/pkg/task/sqs"
 )
 
 type MockSQSDeleter struct {
-	sqsiface.SQSAPI
 	CalledWith []awsSqs.DeleteMessageInput
 	WantErr    bool
 }
 
-func (s *MockSQSDeleter) GetQueueUrl(input *awsSqs.GetQueueUrlInput) (*awsSqs.GetQueueUrlOutput, error) {
+func (s *MockSQSDeleter) GetQueueUrl(_ context.Context, input *awsSqs.GetQueueUrlInput, _ ...func(*awsSqs.Options)) (*awsSqs.GetQueueUrlOutput, error) {
 	output := new(awsSqs.GetQueueUrlOutput)
 	output.QueueUrl = aws.String(*input.QueueName)
 
 	return output, nil
 }
 
-func (s *MockSQSDeleter) DeleteMessage(input *awsSqs.DeleteMessageInput) (*awsSqs.DeleteMessageOutput, error) {
+func (s *MockSQSDeleter) DeleteMessage(_ context.Context, input *awsSqs.DeleteMessageInput, _ ...func(*awsSqs.Options)) (*awsSqs.DeleteMessageOutput, error) {
 	if s.WantErr == true {
 		return nil, errors.New("delete-message-error")
 	}
@@ -39,7 +37,7 @@ func (s *MockSQSDeleter) DeleteMessage(input *awsSqs.DeleteMessageInput) (*awsSq
 
 func TestSQSDeleter_Run(t *testing.T) {
 	type fields struct {
-		client sqsiface.SQSAPI
+		client sqs.DeleteMessageAPI
 		logger *slog.Logger
 		opts   *sqs.SQSDeleterOpts
 	}
@@ -87,11 +85,14 @@ func TestSQSDeleter_Run(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := sqs.NewSQSDeleter(
+			s, err := sqs.NewSQSDeleter(
 				tt.fields.client,
 				tt.fields.logger,
 				tt.fields.opts,
 			)
+			if err != nil {
+				t.Fatalf("NewSQSDeleter() error = %v", err)
+			}
 			got, err := s.Run(tt.args.in0, tt.args.input, tt.args.meta, tt.name)
 
 			if (err != nil) != tt.wantErr {
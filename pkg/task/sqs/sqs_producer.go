@@ -3,70 +3,108 @@ package sqs
 import (
 	"context"
 	"log/slog"
-	"os"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/otaviohenrique/vecna/pkg/task"
 )
 
 // SQS Producer  options
 type SQSProducerOpts struct {
 	// Delay which message will be delivered (if not given, will use default from queue)
-	DelaySeconds *int64
+	DelaySeconds *int32
 	// QueueName
 	QueueName string
+	// QueueURL of the queue. When given QueueName isn't used to resolve it
+	QueueURL string
+	// LazyQueueURL postpones queue URL resolution to the first Run instead of the constructor
+	LazyQueueURL bool
+	// QueueURLRetry configures retries of queue URL resolution (optional)
+	QueueURLRetry *QueueURLRetryOpts
+	// LargePayload when given offloads bodies bigger than threshold to S3, sending only a pointer to SQS
+	LargePayload *SQSLargePayloadProducerOpts
 }
 
 type SQSProducerInput struct {
 	Body   string
-	MsgAtt map[string]*sqs.MessageAttributeValue
+	MsgAtt map[string]types.MessageAttributeValue
 }
 
 // Simple generic task to produce messages to SQS
 type SQSProducer[I SQSProducerInput, O task.Nullable] struct {
 	// SQS AWS client to be used
-	client   sqsiface.SQSAPI
+	client   SendMessageAPI
 	logger   *slog.Logger
 	opts     *SQSProducerOpts
-	queueURL *string
+	queueURL *queueURLResolver
 }
 
-func NewSQSProducer[I SQSProducerInput, O task.Nullable](client sqsiface.SQSAPI, logger *slog.Logger, opts *SQSProducerOpts) *SQSProducer[I, O] {
+// NewSQSProducer creates a SQSProducer. Unless QueueURL or LazyQueueURL is given, queue URL is resolved here
+// and an error is returned if it can't be resolved.
+func NewSQSProducer[I SQSProducerInput, O task.Nullable](client SendMessageAPI, logger *slog.Logger, opts *SQSProducerOpts) (*SQSProducer[I, O], error) {
 	p := new(SQSProducer[I, O])
 
 	p.client = client
 	p.logger = logger
 	p.opts = opts
-	p.queueURL = p.GetQueueURL()
 
-	return p
-}
+	resolver, err := newQueueURLResolver(client, opts.QueueName, opts.QueueURL, opts.QueueURLRetry)
+	if err != nil {
+		return nil, err
+	}
 
-func (p *SQSProducer[I, O]) GetQueueURL() *string {
-	urlResult, err := p.client.GetQueueUrl(&sqs.GetQueueUrlInput{
-		QueueName: aws.String(p.opts.QueueName),
-	})
+	p.queueURL = resolver
+
+	if !opts.LazyQueueURL {
+		if _, err := p.GetQueueURL(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
 
+// GetQueueURL returns the queue URL, resolving it when not resolved yet
+func (p *SQSProducer[I, O]) GetQueueURL(ctx context.Context) (*string, error) {
+	url, err := p.queueURL.get(ctx)
 	if err != nil {
-		p.logger.Error("can't get queue name", "error", err)
+		p.logger.Error("can't get queue url", "error", err, "queue", p.opts.QueueName)
 
-		os.Exit(1)
+		return nil, err
 	}
 
-	return urlResult.QueueUrl
+	return url, nil
 }
 
 // Run will produce message returned by sqsProducerAdaptFn to the targete SQS queue. It always returns nil, being capable of only return error if any happen
-func (c *SQSProducer[I, O]) Run(_ context.Context, i I, meta map[string]interface{}, name string) (O, error) {
+// When LargePayload is configured and the message exceeds the threshold, the body is uploaded to S3 and a pointer is sent instead
+func (c *SQSProducer[I, O]) Run(ctx context.Context, i I, meta map[string]interface{}, name string) (O, error) {
 	input := SQSProducerInput(i)
 
-	_, err := c.client.SendMessage(&sqs.SendMessageInput{
-		DelaySeconds:      c.opts.DelaySeconds,
-		MessageAttributes: input.MsgAtt,
-		MessageBody:       &input.Body,
-		QueueUrl:          c.queueURL,
+	queueURL, err := c.GetQueueURL(ctx)
+	if err != nil {
+		return O(task.Nullable{}), err
+	}
+
+	body := input.Body
+	attrs := input.MsgAtt
+
+	if lp := c.opts.LargePayload; lp != nil && lp.shouldOffload(body, attrs) {
+		body, attrs, err = lp.offload(ctx, body, attrs, meta, name)
+		if err != nil {
+			c.logger.Error("error offloading payload to s3", "error", err)
+			return O(task.Nullable{}), err
+		}
+
+		c.logger.Debug("sqs payload offloaded to s3", "pointer", body)
+	}
+
+	_, err = c.client.SendMessage(ctx, &sqs.SendMessageInput{
+		DelaySeconds:      aws.ToInt32(c.opts.DelaySeconds),
+		MessageAttributes: attrs,
+		MessageBody:       &body,
+		QueueUrl:          queueURL,
 	})
 
 	return O(task.Nullable{}), err
@@ -8,14 +8,13 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/aws/aws-sdk-go/aws"
-	awsSqs "github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/otaviohenrique/vecna/pkg/task/sqs"
 )
 
 type MockSQSProducer struct {
-	sqsiface.SQSAPI
 	QueueURL         string
 	CalledWith       []awsSqs.SendMessageInput
 	ExpectedResponse string
@@ -24,14 +23,14 @@ type MockSQSProducer struct {
 	WantErr          bool
 }
 
-func (s *MockSQSProducer) GetQueueUrl(input *awsSqs.GetQueueUrlInput) (*awsSqs.GetQueueUrlOutput, error) {
+func (s *MockSQSProducer) GetQueueUrl(_ context.Context, input *awsSqs.GetQueueUrlInput, _ ...func(*awsSqs.Options)) (*awsSqs.GetQueueUrlOutput, error) {
 	output := new(awsSqs.GetQueueUrlOutput)
 	output.QueueUrl = aws.String(*input.QueueName)
 
 	return output, nil
 }
 
-func (s *MockSQSProducer) SendMessage(input *awsSqs.SendMessageInput) (*awsSqs.SendMessageOutput, error) {
+func (s *MockSQSProducer) SendMessage(_ context.Context, input *awsSqs.SendMessageInput, _ ...func(*awsSqs.Options)) (*awsSqs.SendMessageOutput, error) {
 	if s.WantErr == true {
 		return nil, errors.New("test-err")
 	}
@@ -45,7 +44,7 @@ func (s *MockSQSProducer) SendMessage(input *awsSqs.SendMessageInput) (*awsSqs.S
 
 func TestSQSProducer_Run(t *testing.T) {
 	type fields struct {
-		client sqsiface.SQSAPI
+		client sqs.SendMessageAPI
 		logger *slog.Logger
 		opts   *sqs.SQSProducerOpts
 	}
@@ -94,7 +93,7 @@ func TestSQSProducer_Run(t *testing.T) {
 			in0: context.TODO(),
 			i: sqs.SQSProducerInput{
 				Body: "test-message",
-				MsgAtt: map[string]*awsSqs.MessageAttributeValue{
+				MsgAtt: map[string]types.MessageAttributeValue{
 					"Meaning": {
 						DataType:    aws.String("String"),
 						StringValue: aws.String("42"),
@@ -107,13 +106,16 @@ func TestSQSProducer_Run(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := sqs.NewSQSProducer(
+			c, err := sqs.NewSQSProducer(
 				tt.fields.client,
 				tt.fields.logger,
 				tt.fields.opts,
 			)
+			if err != nil {
+				t.Fatalf("NewSQSProducer() error = %v", err)
+			}
 
-			_, err := c.Run(tt.args.in0, tt.args.i, tt.args.meta, tt.args.name)
+			_, err = c.Run(tt.args.in0, tt.args.i, tt.args.meta, tt.args.name)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("SQSProducer.Run() error = %v, wantErr %v", err, tt.wantErr)
 				return
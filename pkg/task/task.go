@@ -2,6 +2,23 @@ package task
 
 import (
 	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultPollTimeout is how long source tasks consuming a broker wait for messages before returning ErrNoData
+	DefaultPollTimeout = time.Second
+)
+
+var (
+	// ErrNoData should be returned by source tasks (executed by producer workers) when there is nothing to produce,
+	// or by any task when the message should be dropped as an expected outcome (ex. a not modified object).
+	// Workers will skip the message without reporting it as a task error.
+	ErrNoData = errors.New("task has no data to produce")
+	// ErrStreamOutputType is returned by stream tasks (ex. S3StreamDownloader) built with an output type which can't
+	// hold the io.ReadCloser they return
+	ErrStreamOutputType = errors.New("stream output type must be io.ReadCloser")
 )
 
 // Task performs a simple action. Usually only one action.
@@ -2,7 +2,9 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 
 	"github.com/otaviohenrique/vecna/pkg/metrics"
 	"github.com/otaviohenrique/vecna/pkg/task"
@@ -24,6 +26,8 @@ type BiDirectionalWorker[I any, O any] struct {
 	metric    metrics.Metric
 	closeCh   chan struct{}
 	started   bool
+	// running goroutines, waited by Stop
+	wg sync.WaitGroup
 }
 
 func NewBiDirectionalWorker[I any, O any](name string, task task.Task[I, O], numWorker int, logger *slog.Logger, metric metrics.Metric) *BiDirectionalWorker[I, O] {
@@ -66,8 +70,12 @@ func (w *BiDirectionalWorker[I, O]) AddInputCh(i chan *WorkerData[I]) {
 func (w *BiDirectionalWorker[I, O]) Start(ctx context.Context) {
 	w.logger.Info("starting bidirectional worker", "worker_name", w.name)
 
+	w.wg.Add(w.numWorker)
+
 	for i := 0; i < w.numWorker; i++ {
 		go func() {
+			defer w.wg.Done()
+
 			for {
 				select {
 				case msgIn := <-w.Input:
@@ -79,11 +87,17 @@ func (w *BiDirectionalWorker[I, O]) Start(ctx context.Context) {
 					go w.metric.TaskRun(w.name)
 					resp, err := w.task.Run(ctx, msgIn.Data, msgIn.Metadata, w.name)
 
-					if err != nil {
+					if errors.Is(err, task.ErrNoData) {
+						w.logger.Debug("nothing to produce", "worker_name", w.name)
+					} else if err != nil {
 						w.logger.Error("task error", "worker", w.name, "error", err)
 						go w.metric.TaskError(w.name)
 					} else {
-						w.Output <- &WorkerData[O]{Data: resp, Metadata: msgIn.Metadata}
+						if !send(ctx, w.Output, &WorkerData[O]{Data: resp, Metadata: msgIn.Metadata}, w.closeCh) {
+							w.logger.Warn("message dropped, worker stopped with output full", "worker_name", w.name)
+							return
+						}
+
 						go func() {
 							w.metric.TaskSuccess(w.name)
 							w.metric.ProducedMessage(w.name)
@@ -103,4 +117,6 @@ func (w *BiDirectionalWorker[I, O]) Stop(ctx context.Context) {
 	w.logger.Info("Stopping Worker", "worker_name", w.name)
 
 	close(w.closeCh)
+
+	waitRunning(ctx, &w.wg, w.logger, w.name)
 }
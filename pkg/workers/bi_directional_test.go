@@ -2,10 +2,12 @@ package workers_test
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/otaviohenrique/vecna/pkg/metrics"
 	"github.com/otaviohenrique/vecna/pkg/task"
@@ -18,6 +20,51 @@ func (t *MockTaskBidirectional[T, K]) Run(_ context.Context, input T, meta map[s
 	return K(input), nil
 }
 
+// MockTaskSkip skips "skip" inputs with task.ErrNoData and fails "fail" inputs
+type MockTaskSkip[T string, K string] struct{}
+
+func (t *MockTaskSkip[T, K]) Run(_ context.Context, input T, meta map[string]interface{}, _ string) (K, error) {
+	switch input {
+	case "skip":
+		return "", task.ErrNoData
+	case "fail":
+		return "", errors.New("task failed")
+	default:
+		return K(input), nil
+	}
+}
+
+func TestBiDirectionalWorker_StartSkipsNoData(t *testing.T) {
+	metric := metrics.NewMockMetrics()
+	input := make(chan *workers.WorkerData[string])
+	output := make(chan *workers.WorkerData[string])
+
+	w := workers.NewBiDirectionalWorker[string, string]("skipper", &MockTaskSkip[string, string]{}, 1, slog.New(slog.NewTextHandler(os.Stdout, nil)), metric)
+	w.AddInputCh(input)
+	w.AddOutputCh(output)
+	w.Start(context.TODO())
+
+	go func() {
+		for _, msg := range []string{"skip", "fail", "Input1"} {
+			input <- &workers.WorkerData[string]{Data: msg, Metadata: map[string]interface{}{}}
+		}
+	}()
+
+	if msg := <-output; msg.Data != "Input1" {
+		t.Errorf("BiDirectional worker output = %s, want Input1", msg.Data)
+	}
+
+	// TaskError is reported asynchronously
+	time.Sleep(50 * time.Millisecond)
+
+	metric.Lock.RLock()
+	defer metric.Lock.RUnlock()
+
+	if errors := metric.TaskErrorCalled["skipper"]; errors != 1 {
+		t.Errorf("BiDirectional worker TaskError called %d times, want 1 (task.ErrNoData is not an error)", errors)
+	}
+}
+
 func TestBiDirectionalWorker_Start(t *testing.T) {
 	type fields struct {
 		name      string
@@ -83,3 +130,27 @@ func TestBiDirectionalWorker_Start(t *testing.T) {
 		})
 	}
 }
+
+func TestBiDirectionalWorker_StopWithFullOutput(t *testing.T) {
+	input := make(chan *workers.WorkerData[string])
+
+	w := workers.NewBiDirectionalWorker("Test BiDirectional Stop", &MockTaskBidirectional[string, string]{}, 1, slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics())
+	w.Input = input
+	w.Output = make(chan *workers.WorkerData[string])
+	w.Start(context.TODO())
+
+	// nobody reads the output, the message blocks the worker
+	input <- &workers.WorkerData[string]{Data: "Input1", Metadata: map[string]interface{}{}}
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop(context.TODO())
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("BiDirectionalWorker.Stop() hanged with a full output")
+	}
+}
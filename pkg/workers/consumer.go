@@ -2,7 +2,9 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 
 	"github.com/otaviohenrique/vecna/pkg/metrics"
 	"github.com/otaviohenrique/vecna/pkg/task"
@@ -22,6 +24,8 @@ type ConsumerWorker[I any, O any] struct {
 	metric    metrics.Metric
 	closeCh   chan struct{}
 	started   bool
+	// running goroutines, waited by Stop
+	wg sync.WaitGroup
 }
 
 func NewConsumerWorker[I any, O any](name string, task task.Task[I, O], numWorker int, logger *slog.Logger, metric metrics.Metric) *ConsumerWorker[I, O] {
@@ -64,8 +68,12 @@ func (w *ConsumerWorker[I, O]) AddInputCh(i chan *WorkerData[I]) {
 func (w *ConsumerWorker[I, O]) Start(ctx context.Context) {
 	w.logger.Info("starting consumer worker", "worker_name", w.name)
 
+	w.wg.Add(w.numWorker)
+
 	for i := 0; i < w.numWorker; i++ {
 		go func() {
+			defer w.wg.Done()
+
 			for {
 				select {
 				case msgIn := <-w.Input:
@@ -77,7 +85,9 @@ func (w *ConsumerWorker[I, O]) Start(ctx context.Context) {
 					go w.metric.TaskRun(w.name)
 					_, err := w.task.Run(ctx, msgIn.Data, msgIn.Metadata, w.name)
 
-					if err != nil {
+					if errors.Is(err, task.ErrNoData) {
+						w.logger.Debug("nothing to consume", "worker_name", w.name)
+					} else if err != nil {
 						go w.metric.TaskError(w.name)
 						w.logger.Error("task error", "worker", w.name, "error", err)
 					}
@@ -97,4 +107,6 @@ func (w *ConsumerWorker[I, O]) Stop(ctx context.Context) {
 	w.logger.Info("Stopping Worker", "worker_name", w.name)
 
 	close(w.closeCh)
+
+	waitRunning(ctx, &w.wg, w.logger, w.name)
 }
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -75,3 +76,35 @@ func TestConsumerWorker_Start(t *testing.T) {
 		})
 	}
 }
+
+// MockTaskSlow takes a while to run, marking finished when done
+type MockTaskSlow[T string, K string] struct {
+	started  chan struct{}
+	finished atomic.Bool
+}
+
+func (t *MockTaskSlow[T, K]) Run(_ context.Context, input T, meta map[string]interface{}, _ string) (K, error) {
+	close(t.started)
+	time.Sleep(50 * time.Millisecond)
+	t.finished.Store(true)
+
+	return "", nil
+}
+
+func TestConsumerWorker_StopWaitsRunningTasks(t *testing.T) {
+	input := make(chan *workers.WorkerData[string])
+	slow := &MockTaskSlow[string, string]{started: make(chan struct{})}
+
+	w := workers.NewConsumerWorker[string, string]("Test Consumer Stop", slow, 1, slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics())
+	w.AddInputCh(input)
+	w.Start(context.TODO())
+
+	input <- &workers.WorkerData[string]{Data: "Input1", Metadata: map[string]interface{}{}}
+	<-slow.started
+
+	w.Stop(context.TODO())
+
+	if !slow.finished.Load() {
+		t.Errorf("ConsumerWorker.Stop() returned before the running task finished")
+	}
+}
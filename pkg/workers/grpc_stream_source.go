@@ -0,0 +1,218 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/task"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	DefaultGRPCStreamInitialBackoff = 100 * time.Millisecond
+	DefaultGRPCStreamMaxBackoff     = 30 * time.Second
+)
+
+// GRPCStream is the receiving side of a server-streaming call, satisfied by generated clients (grpc.ServerStreamingClient)
+type GRPCStream[O any] interface {
+	Recv() (O, error)
+	Header() (metadata.MD, error)
+}
+
+// GRPCStreamOpener opens the stream, usually wrapping a generated client method:
+//
+//	func(ctx context.Context) (workers.GRPCStream[*pb.Event], error) { return client.Subscribe(ctx, req) }
+type GRPCStreamOpener[O any] func(ctx context.Context) (GRPCStream[O], error)
+
+// GRPCStreamInfo is appended on metadata under worker name for every message
+type GRPCStreamInfo struct {
+	// Header is the response header of the stream which delivered the message
+	Header metadata.MD
+	// Reconnects made before opening this stream
+	Reconnects int
+}
+
+type GRPCStreamSourceOpts struct {
+	// Metadata sent when opening the stream
+	Metadata metadata.MD
+	// InitialBackoff before reconnecting, doubled on every failed reconnection. Defaults to DefaultGRPCStreamInitialBackoff
+	InitialBackoff time.Duration
+	// MaxBackoff caps backoff. Defaults to DefaultGRPCStreamMaxBackoff
+	MaxBackoff time.Duration
+}
+
+// GRPCStreamSourceWorker is a worker which consumes a server-streaming gRPC call, producing one message per received message.
+// When the stream ends or fails it's opened again with exponential backoff (reset after a received message),
+// until Stop is called or ctx is done.
+type GRPCStreamSourceWorker[I task.Nullable, O any] struct {
+	// worker name to be reported on metrics and logging
+	name string
+	// output is a channel which this worker will put received messages
+	Output  chan *WorkerData[O]
+	open    GRPCStreamOpener[O]
+	logger  *slog.Logger
+	metric  metrics.Metric
+	opts    *GRPCStreamSourceOpts
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started bool
+}
+
+// NewGRPCStreamSourceWorker creates a GRPCStreamSourceWorker. opts is optional (nil)
+func NewGRPCStreamSourceWorker[I task.Nullable, O any](name string, open GRPCStreamOpener[O], logger *slog.Logger, metric metrics.Metric, opts *GRPCStreamSourceOpts) *GRPCStreamSourceWorker[I, O] {
+	w := new(GRPCStreamSourceWorker[I, O])
+
+	w.name = name
+	w.open = open
+	w.logger = logger
+	w.metric = metric
+	w.opts = opts
+	w.done = make(chan struct{})
+
+	if w.opts == nil {
+		w.opts = &GRPCStreamSourceOpts{}
+	}
+
+	if w.opts.InitialBackoff == 0 {
+		w.opts.InitialBackoff = DefaultGRPCStreamInitialBackoff
+	}
+
+	if w.opts.MaxBackoff == 0 {
+		w.opts.MaxBackoff = DefaultGRPCStreamMaxBackoff
+	}
+
+	return w
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) Name() string {
+	return w.name
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) Started() bool {
+	return w.started
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) InputCh() chan *WorkerData[I] {
+	return nil
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) OutputCh() chan *WorkerData[O] {
+	return w.Output
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) AddOutputCh(o chan *WorkerData[O]) {
+	w.Output = o
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) AddInputCh(i chan *WorkerData[I]) {
+	w.logger.Error("gRPC stream source worker don't have input channel to add.")
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) Start(ctx context.Context) {
+	w.logger.Info("starting grpc stream source worker", "worker_name", w.name)
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	go func() {
+		defer close(w.done)
+
+		w.run(ctx)
+	}()
+
+	w.started = true
+}
+
+// Stop cancels the stream and waits the worker to return
+func (w *GRPCStreamSourceWorker[I, O]) Stop(ctx context.Context) {
+	w.logger.Info("Stopping gRPC Stream Source Worker", "worker_name", w.name)
+
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+	}
+}
+
+func (w *GRPCStreamSourceWorker[I, O]) run(ctx context.Context) {
+	backoff := w.opts.InitialBackoff
+
+	for reconnects := 0; ; reconnects++ {
+		received, err := w.consume(ctx, reconnects)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if received {
+			backoff = w.opts.InitialBackoff
+		}
+
+		go w.metric.TaskError(w.name)
+		w.logger.Error("grpc stream interrupted, reconnecting", "worker_name", w.name, "error", err, "backoff", backoff)
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, w.opts.MaxBackoff)
+	}
+}
+
+// consume opens the stream and produces its messages until it fails, returning if any message was received
+func (w *GRPCStreamSourceWorker[I, O]) consume(ctx context.Context, reconnects int) (bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if len(w.opts.Metadata) > 0 {
+		streamCtx = metadata.NewOutgoingContext(streamCtx, w.opts.Metadata)
+	}
+
+	stream, err := w.open(streamCtx)
+	if err != nil {
+		return false, err
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return false, err
+	}
+
+	received := false
+
+	for {
+		go w.metric.TaskRun(w.name)
+
+		msg, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+
+		received = true
+
+		data := &WorkerData[O]{
+			Data:     msg,
+			Metadata: map[string]interface{}{w.name: &GRPCStreamInfo{Header: header, Reconnects: reconnects}},
+		}
+
+		select {
+		case w.Output <- data:
+			go func() {
+				w.metric.ProducedMessage(w.name)
+				w.metric.EnqueuedMessages(len(w.Output), w.name+"output")
+			}()
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
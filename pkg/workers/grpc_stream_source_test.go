@@ -0,0 +1,195 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/workers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeStream delivers msgs and then fails with err
+type fakeStream struct {
+	header metadata.MD
+	msgs   []string
+	err    error
+}
+
+func (s *fakeStream) Header() (metadata.MD, error) {
+	return s.header, nil
+}
+
+func (s *fakeStream) Recv() (string, error) {
+	if len(s.msgs) == 0 {
+		return "", s.err
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+
+	return msg, nil
+}
+
+func TestGRPCStreamSourceWorker_Reconnect(t *testing.T) {
+	streams := []*fakeStream{
+		{header: metadata.Pairs("connection", "1"), msgs: []string{"a", "b"}, err: errors.New("connection reset")},
+		nil,
+		{header: metadata.Pairs("connection", "3"), msgs: []string{"c"}, err: io.EOF},
+	}
+
+	var mu sync.Mutex
+	var outgoing []metadata.MD
+	opens := 0
+
+	open := func(ctx context.Context) (workers.GRPCStream[string], error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+
+		mu.Lock()
+		outgoing = append(outgoing, md)
+		opens++
+		n := opens
+		mu.Unlock()
+
+		if n > len(streams) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		if streams[n-1] == nil {
+			return nil, errors.New("unavailable")
+		}
+
+		return streams[n-1], nil
+	}
+
+	output := make(chan *workers.WorkerData[string], 10)
+
+	w := workers.NewGRPCStreamSourceWorker(
+		"grpc-source",
+		open,
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		metrics.NewMockMetrics(),
+		&workers.GRPCStreamSourceOpts{Metadata: metadata.Pairs("x-consumer", "vecna"), InitialBackoff: time.Millisecond},
+	)
+	w.AddOutputCh(output)
+	w.Start(context.TODO())
+
+	want := []struct {
+		data       string
+		connection string
+		reconnects int
+	}{
+		{"a", "1", 0},
+		{"b", "1", 0},
+		{"c", "3", 2},
+	}
+
+	for _, tt := range want {
+		select {
+		case msg := <-output:
+			info, ok := msg.Metadata["grpc-source"].(*workers.GRPCStreamInfo)
+			if !ok {
+				t.Fatalf("GRPCStreamSourceWorker metadata = %v, want *GRPCStreamInfo", msg.Metadata)
+			}
+
+			if msg.Data != tt.data || info.Header.Get("connection")[0] != tt.connection || info.Reconnects != tt.reconnects {
+				t.Errorf("GRPCStreamSourceWorker produced %s (header %v, reconnects %d), want %s (connection %s, reconnects %d)",
+					msg.Data, info.Header, info.Reconnects, tt.data, tt.connection, tt.reconnects)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("GRPCStreamSourceWorker didn't produce %s", tt.data)
+		}
+	}
+
+	// after the last stream ends the worker reconnects once more and waits
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		mu.Lock()
+		waiting := opens == 4
+		mu.Unlock()
+
+		if waiting {
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	w.Stop(ctx)
+
+	if ctx.Err() != nil {
+		t.Errorf("GRPCStreamSourceWorker.Stop() didn't return")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if opens != 4 {
+		t.Errorf("GRPCStreamSourceWorker opened %d streams, want 4", opens)
+	}
+
+	for _, md := range outgoing {
+		if got := md.Get("x-consumer"); len(got) != 1 || got[0] != "vecna" {
+			t.Errorf("GRPCStreamSourceWorker outgoing metadata = %v, want x-consumer vecna", md)
+		}
+	}
+
+}
+
+func TestGRPCStreamSourceWorker_Server(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	open := func(ctx context.Context) (workers.GRPCStream[*healthpb.HealthCheckResponse], error) {
+		return client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+	}
+
+	output := make(chan *workers.WorkerData[*healthpb.HealthCheckResponse], 10)
+
+	w := workers.NewGRPCStreamSourceWorker("health", open, slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics(), nil)
+	w.AddOutputCh(output)
+	w.Start(context.TODO())
+	defer w.Stop(context.TODO())
+
+	for _, want := range []healthpb.HealthCheckResponse_ServingStatus{healthpb.HealthCheckResponse_SERVING, healthpb.HealthCheckResponse_NOT_SERVING} {
+		select {
+		case msg := <-output:
+			if msg.Data.GetStatus() != want {
+				t.Errorf("GRPCStreamSourceWorker produced %v, want %v", msg.Data.GetStatus(), want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("GRPCStreamSourceWorker didn't produce %v", want)
+		}
+
+		healthServer.SetServingStatus("users", healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
@@ -0,0 +1,273 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/task"
+)
+
+const (
+	// HTTPReplyMetaKey is the metadata key holding the reply channel of a synchronous request, use Reply to answer it
+	HTTPReplyMetaKey = "http_reply"
+
+	// RejectedBackpressure is the PayloadRejected reason when the output channel is full
+	RejectedBackpressure = "backpressure"
+	// RejectedBodyTooLarge is the PayloadRejected reason when the request body is bigger than HTTPSourceOpts.MaxBodySize
+	RejectedBodyTooLarge = "body_too_large"
+
+	DefaultHTTPSourceMaxBodySize  = 1 << 20
+	DefaultHTTPSourceReplyTimeout = 30 * time.Second
+)
+
+// HTTPRequest is the data produced by HTTPSourceWorker for every received request
+type HTTPRequest struct {
+	Method     string
+	Path       string
+	Query      url.Values
+	Header     http.Header
+	Body       []byte
+	RemoteAddr string
+}
+
+// HTTPReply is the synchronous response given to Reply by a downstream stage
+type HTTPReply struct {
+	// StatusCode defaults to 200
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type HTTPSourceOpts struct {
+	// Addr to listen on Start() (ex. ":8080"). When empty no server is started, serve Handler() yourself
+	Addr string
+	// Methods accepted, others receive 405. Defaults to POST
+	Methods []string
+	// MaxBodySize in bytes, bigger requests receive 413. Defaults to DefaultHTTPSourceMaxBodySize
+	MaxBodySize int64
+	// SyncReply holds every request until a downstream stage calls Reply (or ReplyTimeout, answered with 504).
+	// Otherwise requests are answered with 202 as soon as they are enqueued
+	SyncReply bool
+	// ReplyTimeout defaults to DefaultHTTPSourceReplyTimeout
+	ReplyTimeout time.Duration
+}
+
+// HTTPSourceWorker is a worker which receives events pushed over HTTP, producing one *HTTPRequest per request.
+// It never blocks on a full output channel: requests are answered with 429, so use a buffered channel.
+type HTTPSourceWorker[I task.Nullable, O *HTTPRequest] struct {
+	// worker name to be reported on metrics and logging
+	name string
+	// output is a channel which this worker will put received requests
+	Output  chan *WorkerData[O]
+	logger  *slog.Logger
+	metric  metrics.Metric
+	opts    *HTTPSourceOpts
+	server  *http.Server
+	started bool
+}
+
+// NewHTTPSourceWorker creates a HTTPSourceWorker. opts is optional (nil)
+func NewHTTPSourceWorker[I task.Nullable, O *HTTPRequest](name string, logger *slog.Logger, metric metrics.Metric, opts *HTTPSourceOpts) *HTTPSourceWorker[I, O] {
+	w := new(HTTPSourceWorker[I, O])
+
+	w.name = name
+	w.logger = logger
+	w.metric = metric
+	w.opts = opts
+
+	if w.opts == nil {
+		w.opts = &HTTPSourceOpts{}
+	}
+
+	if len(w.opts.Methods) == 0 {
+		w.opts.Methods = []string{http.MethodPost}
+	}
+
+	if w.opts.MaxBodySize == 0 {
+		w.opts.MaxBodySize = DefaultHTTPSourceMaxBodySize
+	}
+
+	if w.opts.ReplyTimeout == 0 {
+		w.opts.ReplyTimeout = DefaultHTTPSourceReplyTimeout
+	}
+
+	return w
+}
+
+func (w *HTTPSourceWorker[I, O]) Name() string {
+	return w.name
+}
+
+func (w *HTTPSourceWorker[I, O]) Started() bool {
+	return w.started
+}
+
+func (w *HTTPSourceWorker[I, O]) InputCh() chan *WorkerData[I] {
+	return nil
+}
+
+func (w *HTTPSourceWorker[I, O]) OutputCh() chan *WorkerData[O] {
+	return w.Output
+}
+
+func (w *HTTPSourceWorker[I, O]) AddOutputCh(o chan *WorkerData[O]) {
+	w.Output = o
+}
+
+func (w *HTTPSourceWorker[I, O]) AddInputCh(i chan *WorkerData[I]) {
+	w.logger.Error("HTTP source worker don't have input channel to add.")
+}
+
+// Handler returns the http.Handler receiving events, to be mounted on your own server or httptest
+func (w *HTTPSourceWorker[I, O]) Handler() http.Handler {
+	return http.HandlerFunc(w.serveHTTP)
+}
+
+func (w *HTTPSourceWorker[I, O]) Start(ctx context.Context) {
+	w.logger.Info("starting http source worker", "worker_name", w.name, "addr", w.opts.Addr)
+
+	if w.opts.Addr != "" {
+		w.server = &http.Server{
+			Addr:        w.opts.Addr,
+			Handler:     w.Handler(),
+			BaseContext: func(_ net.Listener) context.Context { return ctx },
+		}
+
+		go func() {
+			if err := w.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				w.logger.Error("http source server error", "worker_name", w.name, "error", err)
+			}
+		}()
+	}
+
+	w.started = true
+}
+
+// Stop gracefully shuts the server down, waiting pending requests until ctx is done
+func (w *HTTPSourceWorker[I, O]) Stop(ctx context.Context) {
+	w.logger.Info("Stopping HTTP Source Worker", "worker_name", w.name)
+
+	if w.server != nil {
+		if err := w.server.Shutdown(ctx); err != nil {
+			w.logger.Error("error shutting http source server down", "worker_name", w.name, "error", err)
+		}
+	}
+}
+
+func (w *HTTPSourceWorker[I, O]) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !slices.Contains(w.opts.Methods, r.Method) {
+		rw.Header().Set("Allow", strings.Join(w.opts.Methods, ", "))
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, w.opts.MaxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.payloadRejected(RejectedBodyTooLarge)
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	metadata := map[string]interface{}{}
+
+	var reply chan *HTTPReply
+	if w.opts.SyncReply {
+		reply = make(chan *HTTPReply, 1)
+		metadata[HTTPReplyMetaKey] = reply
+	}
+
+	data := &HTTPRequest{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.Query(),
+		Header:     r.Header,
+		Body:       body,
+		RemoteAddr: r.RemoteAddr,
+	}
+
+	select {
+	case w.Output <- &WorkerData[O]{Data: data, Metadata: metadata}:
+		go func() {
+			w.metric.ProducedMessage(w.name)
+			w.metric.EnqueuedMessages(len(w.Output), w.name+"output")
+		}()
+	default:
+		w.logger.Warn("output channel full, rejecting request", "worker_name", w.name)
+		w.payloadRejected(RejectedBackpressure)
+
+		rw.Header().Set("Retry-After", "1")
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	if !w.opts.SyncReply {
+		rw.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	timer := time.NewTimer(w.opts.ReplyTimeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-reply:
+		writeReply(rw, resp)
+	case <-timer.C:
+		w.logger.Warn("timeout waiting reply", "worker_name", w.name, "path", r.URL.Path)
+		http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+	case <-r.Context().Done():
+	}
+}
+
+func writeReply(rw http.ResponseWriter, reply *HTTPReply) {
+	for k, values := range reply.Header {
+		for _, v := range values {
+			rw.Header().Add(k, v)
+		}
+	}
+
+	statusCode := reply.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	rw.WriteHeader(statusCode)
+	rw.Write(reply.Body)
+}
+
+// Reply answers the synchronous request which originated meta (see HTTPSourceOpts.SyncReply).
+// Returns false when the message didn't come from a synchronous request or a reply was already given.
+func Reply(meta map[string]interface{}, reply *HTTPReply) bool {
+	ch, ok := meta[HTTPReplyMetaKey].(chan *HTTPReply)
+	if !ok {
+		return false
+	}
+
+	select {
+	case ch <- reply:
+		return true
+	default:
+		return false
+	}
+}
+
+// payloadRejected reports a rejected request when the metric implements metrics.PayloadRejecter
+func (w *HTTPSourceWorker[I, O]) payloadRejected(reason string) {
+	if rejecter, ok := w.metric.(metrics.PayloadRejecter); ok {
+		go rejecter.PayloadRejected(w.name, reason)
+	}
+}
@@ -0,0 +1,156 @@
+package workers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/otaviohenrique/vecna/pkg/metrics"
+	"github.com/otaviohenrique/vecna/pkg/workers"
+)
+
+func TestHTTPSourceWorker_Handler(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       *workers.HTTPSourceOpts
+		method     string
+		body       string
+		outputSize int
+		fill       int
+		wantStatus int
+		wantData   bool
+		wantReject string
+	}{
+		{"It enqueues requests", nil, http.MethodPost, "event", 1, 0, http.StatusAccepted, true, ""},
+		{"It rejects with 429 when output is full", nil, http.MethodPost, "event", 1, 1, http.StatusTooManyRequests, false, workers.RejectedBackpressure},
+		{"It rejects not allowed methods", nil, http.MethodGet, "", 1, 0, http.StatusMethodNotAllowed, false, ""},
+		{"It accepts configured methods", &workers.HTTPSourceOpts{Methods: []string{http.MethodPut}}, http.MethodPut, "event", 1, 0, http.StatusAccepted, true, ""},
+		{"It rejects big bodies", &workers.HTTPSourceOpts{MaxBodySize: 4}, http.MethodPost, "too-big", 1, 0, http.StatusRequestEntityTooLarge, false, workers.RejectedBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := metrics.NewMockMetrics()
+			w := workers.NewHTTPSourceWorker("http-source", slog.New(slog.NewTextHandler(os.Stdout, nil)), metric, tt.opts)
+
+			output := make(chan *workers.WorkerData[*workers.HTTPRequest], tt.outputSize)
+			for i := 0; i < tt.fill; i++ {
+				output <- &workers.WorkerData[*workers.HTTPRequest]{}
+			}
+			w.AddOutputCh(output)
+
+			server := httptest.NewServer(w.Handler())
+			defer server.Close()
+
+			req, _ := http.NewRequest(tt.method, server.URL+"/events?source=test", strings.NewReader(tt.body))
+			req.Header.Set("X-Event", "created")
+
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantData {
+				msg := <-output
+				if msg.Data.Path != "/events" || msg.Data.Query.Get("source") != "test" || msg.Data.Header.Get("X-Event") != "created" ||
+					string(msg.Data.Body) != tt.body || msg.Data.Method != tt.method {
+					t.Errorf("produced = %+v", msg.Data)
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+
+			metric.Lock.RLock()
+			defer metric.Lock.RUnlock()
+
+			if rejected := metric.PayloadRejectedCalled["http-source"] == 1; rejected != (tt.wantReject != "") {
+				t.Errorf("PayloadRejected called = %v, want reason %q", metric.PayloadRejectedCalled["http-source"], tt.wantReject)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceWorker_SyncReply(t *testing.T) {
+	tests := []struct {
+		name       string
+		reply      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"It answers with downstream reply", true, http.StatusCreated, "EVENT"},
+		{"It answers 504 without reply", false, http.StatusGatewayTimeout, http.StatusText(http.StatusGatewayTimeout) + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := workers.NewHTTPSourceWorker("http-source", slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics(),
+				&workers.HTTPSourceOpts{SyncReply: true, ReplyTimeout: 50 * time.Millisecond})
+
+			output := make(chan *workers.WorkerData[*workers.HTTPRequest], 1)
+			w.AddOutputCh(output)
+
+			// downstream stage
+			go func() {
+				msg := <-output
+				if tt.reply {
+					workers.Reply(msg.Metadata, &workers.HTTPReply{
+						StatusCode: http.StatusCreated,
+						Header:     http.Header{"X-Processed": {"true"}},
+						Body:       bytes.ToUpper(msg.Data.Body),
+					})
+				}
+			}()
+
+			server := httptest.NewServer(w.Handler())
+			defer server.Close()
+
+			resp, err := server.Client().Post(server.URL, "text/plain", strings.NewReader("event"))
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("response = %v %s, want %v %s", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+
+			if tt.reply && resp.Header.Get("X-Processed") != "true" {
+				t.Errorf("response header = %v", resp.Header)
+			}
+		})
+	}
+}
+
+func TestReply_WithoutSyncRequest(t *testing.T) {
+	if workers.Reply(map[string]interface{}{}, &workers.HTTPReply{}) {
+		t.Errorf("Reply() = true, want false")
+	}
+}
+
+func TestHTTPSourceWorker_StartStop(t *testing.T) {
+	w := workers.NewHTTPSourceWorker("http-source", slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics(),
+		&workers.HTTPSourceOpts{Addr: "127.0.0.1:0"})
+
+	w.Start(context.TODO())
+
+	if !w.Started() {
+		t.Errorf("Started() = false, want true")
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	w.Stop(ctx)
+}
@@ -2,7 +2,9 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/otaviohenrique/vecna/pkg/metrics"
@@ -25,6 +27,8 @@ type ProducerWorker[I any, O any] struct {
 	trigger time.Duration
 	closeCh chan struct{}
 	started bool
+	// running goroutines, waited by Stop
+	wg sync.WaitGroup
 }
 
 func NewProducerWorker[I, O any](name string, task task.Task[I, O], numWorker int, logger *slog.Logger, metric metrics.Metric, trigger time.Duration) *ProducerWorker[I, O] {
@@ -70,8 +74,12 @@ func (w *ProducerWorker[I, O]) Start(ctx context.Context) {
 
 	ticker := time.NewTicker(w.trigger)
 
+	w.wg.Add(w.numWorker)
+
 	for i := 0; i < w.numWorker; i++ {
 		go func() {
+			defer w.wg.Done()
+
 			for {
 				select {
 				case <-w.closeCh:
@@ -85,11 +93,17 @@ func (w *ProducerWorker[I, O]) Start(ctx context.Context) {
 					metadata := map[string]interface{}{}
 					resp, err := w.task.Run(ctx, emptyMessage, metadata, w.name)
 
-					if err != nil {
+					if errors.Is(err, task.ErrNoData) {
+						w.logger.Debug("nothing to produce", "worker_name", w.name)
+					} else if err != nil {
 						go w.metric.TaskError(w.name)
 						w.logger.Error("task error", "worker", w.name, "error", err)
 					} else {
-						w.Output <- &WorkerData[O]{Data: resp, Metadata: metadata}
+						if !send(ctx, w.Output, &WorkerData[O]{Data: resp, Metadata: metadata}, w.closeCh) {
+							w.logger.Warn("message dropped, worker stopped with output full", "worker_name", w.name)
+							return
+						}
+
 						go func() {
 							w.metric.ProducedMessage(w.name)
 							w.metric.TaskRun(w.name)
@@ -107,4 +121,6 @@ func (w *ProducerWorker[I, O]) Stop(ctx context.Context) {
 	w.logger.Info("Stopping Producer Worker", "worker_name", w.name)
 
 	close(w.closeCh)
+
+	waitRunning(ctx, &w.wg, w.logger, w.name)
 }
@@ -84,3 +84,62 @@ func TestProducerWorker_Start(t *testing.T) {
 		})
 	}
 }
+
+type MockTaskNoData[T byte, K string] struct{}
+
+func (t *MockTaskNoData[T, K]) Run(_ context.Context, input T, meta map[string]interface{}, _ string) (K, error) {
+	return "", task.ErrNoData
+}
+
+func TestProducerWorker_StartNoData(t *testing.T) {
+	metric := metrics.NewMockMetrics()
+	output := make(chan *workers.WorkerData[string], 10)
+
+	w := workers.NewProducerWorker(
+		"Test Producer No Data",
+		task.Task[byte, string](&MockTaskNoData[byte, string]{}),
+		1,
+		slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		metric,
+		1*time.Millisecond,
+	)
+	w.AddOutputCh(output)
+	w.Start(context.TODO())
+
+	time.Sleep(20 * time.Millisecond)
+	w.Stop(context.TODO())
+
+	if len(output) != 0 {
+		t.Errorf("Producer worker produced %d messages, want 0", len(output))
+	}
+
+	metric.Lock.RLock()
+	defer metric.Lock.RUnlock()
+
+	if metric.TaskErrorCalled["Test Producer No Data"] != 0 {
+		t.Errorf("Producer worker reported task error for ErrNoData")
+	}
+}
+
+func TestProducerWorker_StopWithFullOutput(t *testing.T) {
+	producer := &MockTaskProducer[byte, string]{}
+
+	w := workers.NewProducerWorker("Test Producer Stop", producer, 1, slog.New(slog.NewTextHandler(os.Stdout, nil)), metrics.NewMockMetrics(), time.Millisecond)
+	w.Output = make(chan *workers.WorkerData[string])
+	w.Start(context.TODO())
+
+	// nobody reads the output, the produced message blocks the worker
+	time.Sleep(10 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop(context.TODO())
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("ProducerWorker.Stop() hanged with a full output")
+	}
+}
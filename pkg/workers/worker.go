@@ -1,6 +1,10 @@
 package workers
 
-import "context"
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
 
 // Worker is a simple generic interface which will execute task async, every worker must have a Start and Stop method
 type Worker[I any, O any] interface {
@@ -21,3 +25,39 @@ type WorkerData[K any] struct {
 	Data     K
 	Metadata map[string]interface{}
 }
+
+// waitRunning waits the goroutines of a worker pool to return, or ctx to be done. Running tasks finish their message,
+// so stopping workers in pipeline order (after their input channel is drained) doesn't lose messages in flight
+func waitRunning(ctx context.Context, wg *sync.WaitGroup, logger *slog.Logger, name string) {
+	stopped := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Warn("stopped without waiting running tasks", "worker_name", name, "error", ctx.Err())
+	}
+}
+
+// send puts data on output, giving up when the worker is stopped (closeCh) or ctx is done while output is full, so
+// Stop never hangs on a stalled downstream. A free output is always taken first, not dropping messages while stopping
+func send[T any](ctx context.Context, output chan *WorkerData[T], data *WorkerData[T], closeCh chan struct{}) bool {
+	select {
+	case output <- data:
+		return true
+	default:
+	}
+
+	select {
+	case output <- data:
+		return true
+	case <-closeCh:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}